@@ -5,10 +5,14 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"syscall"
+	"text/tabwriter"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/andrew-bierman/jean-tui/config"
@@ -16,6 +20,7 @@ import (
 	"github.com/andrew-bierman/jean-tui/internal/branding"
 	"github.com/andrew-bierman/jean-tui/internal/update"
 	"github.com/andrew-bierman/jean-tui/internal/version"
+	"github.com/andrew-bierman/jean-tui/session"
 	"github.com/andrew-bierman/jean-tui/tui"
 )
 
@@ -58,7 +63,7 @@ func main() {
 	shouldCheckInit := true
 	if len(os.Args) > 1 {
 		switch os.Args[1] {
-		case "init", "version", "help":
+		case "init", "version", "help", "watch", "restore", "sessions", "report":
 			shouldCheckInit = false
 		}
 	}
@@ -79,6 +84,18 @@ func main() {
 		case "update":
 			handleUpdate()
 			return
+		case "watch":
+			handleWatch()
+			return
+		case "restore":
+			handleRestore()
+			return
+		case "sessions":
+			handleSessions()
+			return
+		case "report":
+			handleReport()
+			return
 		case "version":
 			fmt.Printf("%s version %s\n", branding.CLIName, version.CliVersion)
 			os.Exit(0)
@@ -131,7 +148,8 @@ func main() {
 	if m, ok := finalModel.(tui.Model); ok {
 		switchInfo := m.GetSwitchInfo()
 		if switchInfo.Path != "" {
-			// Format: path|branch|auto-claude|target-window|script-command|session-name|is-claude-initialized
+			// Format: path|branch|auto-claude|target-window|script-command|session-name|is-claude-initialized|backend|attach-command
+			// (attach-command comes last since it may contain '|'; backend and attach-command need wrapper version 2)
 			autoCl := "false"
 			if switchInfo.AutoClaude {
 				autoCl = "true"
@@ -144,7 +162,18 @@ func main() {
 			if switchInfo.IsClaudeInitialized {
 				isInitialized = "true"
 			}
+			backend := switchInfo.Backend
+			if backend == "" {
+				backend = "tmux"
+			}
 			switchData := fmt.Sprintf("%s|%s|%s|%s|%s|%s|%s", switchInfo.Path, switchInfo.Branch, autoCl, targetWindow, switchInfo.ScriptCommand, switchInfo.SessionName, isInitialized)
+			if wrapperVersion() >= install.WrapperVersion {
+				switchData += fmt.Sprintf("|%s|%s", backend, switchInfo.AttachCommand)
+			} else {
+				// A wrapper loaded before the last update reads the first seven fields only
+				// (and attaches by itself), so leave the new ones out until the shell reloads it
+				fmt.Fprintf(os.Stderr, "Your shell is still using an outdated %s wrapper. Open a new shell (or source your shell rc file) to update it.\n", branding.CLIName)
+			}
 
 			// Debug: log what we're writing
 			debugLog(fmt.Sprintf("DEBUG main: switchInfo={Path:%q Branch:%q AutoClaude:%v TargetWindow:%q SessionName:%q}", switchInfo.Path, switchInfo.Branch, switchInfo.AutoClaude, switchInfo.TargetWindow, switchInfo.SessionName))
@@ -167,6 +196,20 @@ func main() {
 	}
 }
 
+// wrapperVersion returns the switch file format version of the shell wrapper that started jean:
+// 1 for wrappers from before the format was versioned, or the current version when jean prints
+// the switch info itself (without a wrapper)
+func wrapperVersion() int {
+	if os.Getenv(branding.GetEnvVar("SWITCH_FILE")) == "" {
+		return install.WrapperVersion
+	}
+	version, err := strconv.Atoi(os.Getenv(branding.GetEnvVar("WRAPPER_VERSION")))
+	if err != nil {
+		return 1
+	}
+	return version
+}
+
 // ensureShellIntegration checks if shell integration is installed and active.
 // Automatically installs or updates wrapper if needed using checksum comparison.
 // Returns nil if wrapper is already active, otherwise performs init/update and re-exec.
@@ -364,6 +407,14 @@ USAGE:
 COMMANDS:
     init            Install or manage %s shell integration
     update          Update %s to the latest version
+    watch           Watch agent sessions and alert when an agent finishes or needs input
+                    (started automatically in the background by the TUI)
+    restore         Recreate the sessions that were running before a tmux restart or reboot
+                    (-list shows them without restoring)
+    sessions prune  Kill sessions whose worktree was deleted and rename sessions of renamed branches
+                    (-dry-run shows what would change)
+    report          Summarise tracked active time per branch and day
+                    (-since 7d|24h|2006-01-02, -repo <path>, -format table|csv|json)
     help            Show this help message
     version         Print version and exit
 
@@ -477,3 +528,218 @@ func handleUpdate() {
 		os.Exit(1)
 	}
 }
+
+// handleWatch runs the background agent watcher (see session.Watcher)
+func handleWatch() {
+	watchFlags := flag.NewFlagSet("watch", flag.ExitOnError)
+	ttyFlag := watchFlags.String("tty", "", "Additional terminal to send bell/desktop alerts to")
+	intervalFlag := watchFlags.Duration("interval", 2*time.Second, "How often to check agent sessions")
+	watchFlags.Parse(os.Args[2:])
+
+	// The PID file is created exclusively so two watchers starting at once can't both run
+	pidPath := branding.GetWatcherPIDPath()
+	pidFlags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	pidFile, err := os.OpenFile(pidPath, pidFlags, 0644)
+	if os.IsExist(err) && !session.IsWatcherRunning() {
+		// Left behind by a watcher that didn't exit cleanly
+		os.Remove(pidPath)
+		pidFile, err = os.OpenFile(pidPath, pidFlags, 0644)
+	}
+	if os.IsExist(err) {
+		fmt.Fprintf(os.Stderr, "%s watch is already running\n", branding.CLIName)
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: could not write PID file: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(pidFile, "%d", os.Getpid())
+	pidFile.Close()
+	defer os.Remove(pidPath)
+
+	// Signals stop the watcher loop, which writes the tracked time before returning
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	stop := make(chan struct{})
+	go func() {
+		<-signals
+		close(stop)
+	}()
+
+	configManager, err := config.NewManager()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not load config: %v\n", err)
+	}
+
+	if err := session.NewWatcher(configManager, *ttyFlag).Run(*intervalFlag, stop); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Remove(pidPath)
+		os.Exit(1)
+	}
+}
+
+// handleRestore recreates the sessions recorded before a tmux server restart or reboot
+func handleRestore() {
+	restoreFlags := flag.NewFlagSet("restore", flag.ExitOnError)
+	listFlag := restoreFlags.Bool("list", false, "List restorable sessions without restoring them")
+	forgetFlag := restoreFlags.Bool("forget", false, "Stop offering the recorded sessions for restoring")
+	restoreFlags.Parse(os.Args[2:])
+
+	manager := session.NewManager()
+	if !manager.IsTmuxAvailable() {
+		fmt.Fprintln(os.Stderr, "Error: tmux is not installed")
+		os.Exit(1)
+	}
+
+	restorable := manager.RestorableSessions()
+	if len(restorable) == 0 {
+		fmt.Println("No sessions to restore")
+		return
+	}
+
+	if *forgetFlag {
+		if err := manager.ForgetRestorableSessions(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Forgot %d session(s)\n", len(restorable))
+		return
+	}
+
+	if *listFlag {
+		for _, sess := range restorable {
+			agent := ""
+			if sess.AgentRunning {
+				agent = " (agent running)"
+			}
+			fmt.Printf("%s\t%s%s\n", sess.Name, sess.Path, agent)
+		}
+		return
+	}
+
+	configManager, err := config.NewManager()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not load config: %v\n", err)
+	}
+
+	restored, err := manager.RestoreSessions(configManager)
+	for _, name := range restored {
+		fmt.Printf("Restored %s\n", name)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func handleSessions() {
+	if len(os.Args) < 3 || os.Args[2] != "prune" {
+		fmt.Fprintf(os.Stderr, "Usage: %s sessions prune [-dry-run]\n", branding.CLIName)
+		os.Exit(1)
+	}
+	pruneFlags := flag.NewFlagSet("sessions prune", flag.ExitOnError)
+	dryRunFlag := pruneFlags.Bool("dry-run", false, "Show what would be pruned without changing anything")
+	pruneFlags.Parse(os.Args[3:])
+
+	manager := session.NewManager()
+	if !manager.IsTmuxAvailable() {
+		fmt.Fprintln(os.Stderr, "Error: tmux is not installed")
+		os.Exit(1)
+	}
+
+	killed, renamed, err := manager.PruneOrphans("", *dryRunFlag)
+	killVerb, renameVerb := "Killed", "Renamed"
+	if *dryRunFlag {
+		killVerb, renameVerb = "Would kill", "Would rename"
+	}
+	for _, name := range killed {
+		fmt.Printf("%s %s\n", killVerb, name)
+	}
+	for _, change := range renamed {
+		fmt.Printf("%s %s\n", renameVerb, change)
+	}
+	if len(killed) == 0 && len(renamed) == 0 && err == nil {
+		fmt.Println("No orphaned sessions")
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// handleReport prints the time tracked per branch and day (see session.TimeTracker)
+func handleReport() {
+	reportFlags := flag.NewFlagSet("report", flag.ExitOnError)
+	sinceFlag := reportFlags.String("since", "7d", "Start of the report: a number of days (7d), a duration (24h) or a date (2006-01-02)")
+	repoFlag := reportFlags.String("repo", "", "Only report this repository (default: all repositories)")
+	formatFlag := reportFlags.String("format", "table", "Output format: table, csv or json")
+	reportFlags.Parse(os.Args[2:])
+
+	since, err := parseSince(*sinceFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	repoPath := ""
+	if *repoFlag != "" {
+		if repoPath, err = filepath.Abs(*repoFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	rows, err := session.TimeReport(since, repoPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	switch *formatFlag {
+	case "csv":
+		err = session.WriteTimeReportCSV(os.Stdout, rows)
+	case "json":
+		err = session.WriteTimeReportJSON(os.Stdout, rows)
+	case "table":
+		if len(rows) == 0 {
+			fmt.Println("No time tracked in this period")
+			if configManager, cfgErr := config.NewManager(); cfgErr == nil && !configManager.GetTimeTracking() {
+				fmt.Printf("Time tracking is off: enable it with \"time_tracking\": true in config.json or T → t in %s\n", branding.CLIName)
+			}
+			return
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "DAY\tREPO\tBRANCH\tACTIVE\tATTACHED\tAGENT")
+		for _, row := range rows {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", row.Day, filepath.Base(row.Repo), row.Branch,
+				session.FormatTrackedTime(row.Active), session.FormatTrackedTime(row.Attached), session.FormatTrackedTime(row.Agent))
+		}
+		w.Flush()
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown format %q (use table, csv or json)\n", *formatFlag)
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// parseSince parses the -since flag of the report command: "7d", a Go duration or a date
+func parseSince(value string) (time.Time, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n > 0 {
+			// The last n days including today
+			now := time.Now()
+			today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+			return today.AddDate(0, 0, 1-n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	if date, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return date, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid -since %q (use 7d, 24h or 2006-01-02)", value)
+}
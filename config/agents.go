@@ -0,0 +1,355 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/andrew-bierman/jean-tui/internal/branding"
+)
+
+// AgentDefinition describes how to launch a coding agent CLI.
+// Argument lists may contain the placeholders {path} (worktree path), {prompt}, {session} (conversation ID)
+// and {mode} (permission mode, in PermissionModeArgs).
+type AgentDefinition struct {
+	Name           string   `json:"name"`                      // Identifier shown in the UI (also used as tmux window name for extra agents)
+	Command        string   `json:"command"`                   // Executable to run
+	StartArgs      []string `json:"start_args,omitempty"`      // Always passed
+	ResumeArgs     []string `json:"resume_args,omitempty"`     // Passed to continue the previous conversation (empty = agent can't resume)
+	ResumeIDArgs   []string `json:"resume_id_args,omitempty"`  // Resume a specific conversation, e.g. ["--resume", "{session}"]
+	AddDirArgs     []string `json:"add_dir_args,omitempty"`    // Grant the agent access to the worktree, e.g. ["--add-dir", "{path}"]
+	PermissionArgs []string `json:"permission_args,omitempty"` // Permission/sandbox mode, e.g. ["--permission-mode", "plan"]
+	PromptArgs     []string `json:"prompt_args,omitempty"`     // How to pass an initial prompt (empty = agent doesn't take prompts)
+
+	// Permission modes that can be picked per repository or worktree, and the arguments replacing
+	// PermissionArgs when one is, e.g. ["--permission-mode", "{mode}"]
+	PermissionModes    []string `json:"permission_modes,omitempty"`
+	PermissionModeArgs []string `json:"permission_mode_args,omitempty"`
+
+	// Where the agent stores its conversations: "claude" or "codex" file layout ("" = not tracked)
+	ConversationFormat string `json:"conversation_format,omitempty"`
+
+	// State detection: regular expressions matched against the bottom of the agent's pane
+	BusyPatterns    []string `json:"busy_patterns,omitempty"`    // The agent is working
+	WaitingPatterns []string `json:"waiting_patterns,omitempty"` // The agent waits for input or a permission decision (checked first)
+}
+
+// AgentArgs customizes how the main agent of a repository or worktree is launched
+type AgentArgs struct {
+	PermissionMode string   `json:"permission_mode,omitempty"` // One of the agent's PermissionModes ("" = its default PermissionArgs)
+	ExtraArgs      []string `json:"extra_args,omitempty"`      // Passed on every start, e.g. ["--model", "opus"]
+}
+
+// IsZero reports whether the arguments leave the agent's defaults unchanged
+func (a AgentArgs) IsZero() bool {
+	return a.PermissionMode == "" && len(a.ExtraArgs) == 0
+}
+
+// clone returns a copy that doesn't share ExtraArgs
+func (a AgentArgs) clone() AgentArgs {
+	a.ExtraArgs = slices.Clone(a.ExtraArgs)
+	return a
+}
+
+// WithArgs returns the definition with the permission mode and extra arguments applied.
+// A permission mode is ignored by agents without PermissionModeArgs.
+func (a AgentDefinition) WithArgs(args AgentArgs) AgentDefinition {
+	if args.PermissionMode != "" && len(a.PermissionModeArgs) > 0 {
+		permission := make([]string, 0, len(a.PermissionModeArgs))
+		for _, arg := range a.PermissionModeArgs {
+			permission = append(permission, strings.ReplaceAll(arg, "{mode}", args.PermissionMode))
+		}
+		a.PermissionArgs = permission
+	}
+	if len(args.ExtraArgs) > 0 {
+		a.StartArgs = append(append([]string(nil), a.StartArgs...), args.ExtraArgs...)
+	}
+	return a
+}
+
+// BuiltinAgents returns the agent definitions that ship with jean
+func BuiltinAgents() []AgentDefinition {
+	return []AgentDefinition{
+		{
+			Name:               "claude",
+			Command:            "claude",
+			ResumeArgs:         []string{"--continue"},
+			ResumeIDArgs:       []string{"--resume", "{session}"},
+			AddDirArgs:         []string{"--add-dir", "{path}"},
+			PermissionArgs:     []string{"--permission-mode", "plan"},
+			PromptArgs:         []string{"{prompt}"},
+			PermissionModes:    []string{"default", "acceptEdits", "plan", "bypassPermissions"},
+			PermissionModeArgs: []string{"--permission-mode", "{mode}"},
+			ConversationFormat: "claude",
+			BusyPatterns:       []string{`esc to interrupt`},
+			WaitingPatterns: []string{
+				`Do you want to`,
+				`❯ \d+\. Yes`,
+				`Would you like to proceed`,
+			},
+		},
+		{
+			Name:            "aider",
+			Command:         "aider",
+			ResumeArgs:      []string{"--restore-chat-history"},
+			BusyPatterns:    []string{`Waiting for .*`, `Tokens: .* sent`},
+			WaitingPatterns: []string{`\(Y\)es/\(N\)o`},
+		},
+		{
+			Name:               "codex",
+			Command:            "codex",
+			ResumeArgs:         []string{"resume", "--last"},
+			ResumeIDArgs:       []string{"resume", "{session}"},
+			AddDirArgs:         []string{"--cd", "{path}"},
+			PermissionArgs:     []string{"--sandbox", "workspace-write"},
+			PromptArgs:         []string{"{prompt}"},
+			PermissionModes:    []string{"read-only", "workspace-write", "danger-full-access"},
+			PermissionModeArgs: []string{"--sandbox", "{mode}"},
+			ConversationFormat: "codex",
+			BusyPatterns:       []string{`esc to interrupt`},
+			WaitingPatterns:    []string{`Allow command\?`, `Would you like to run`},
+		},
+	}
+}
+
+// DefaultAgentName returns the agent used when neither the repository nor the worktree selects one.
+// This is the build-time branding.AgentCommand; "" means blank terminal mode.
+func DefaultAgentName() string {
+	return branding.AgentCommand
+}
+
+// GetAgents returns all available agents: built-ins followed by custom agents.
+// A custom agent with the same name as a built-in replaces it.
+func (m *Manager) GetAgents() []AgentDefinition {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.agents()
+}
+
+// agents is GetAgents for callers already holding the lock
+func (m *Manager) agents() []AgentDefinition {
+	agents := BuiltinAgents()
+	for _, custom := range m.config.CustomAgents {
+		replaced := false
+		for i := range agents {
+			if agents[i].Name == custom.Name {
+				agents[i] = custom
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			agents = append(agents, custom)
+		}
+	}
+	return agents
+}
+
+// GetAgent looks up an agent by name. Unknown names that match the build-time agent command
+// resolve to a bare definition running that command, matching the pre-registry behavior.
+func (m *Manager) GetAgent(name string) (AgentDefinition, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, agent := range m.agents() {
+		if agent.Name == name {
+			return agent, true
+		}
+	}
+	if name != "" && name == branding.AgentCommand {
+		return AgentDefinition{Name: name, Command: name}, true
+	}
+	return AgentDefinition{}, false
+}
+
+// AddCustomAgent adds or replaces a custom agent definition
+func (m *Manager) AddCustomAgent(agent AgentDefinition) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if agent.Name == "" || agent.Command == "" {
+		return fmt.Errorf("agent name and command are required")
+	}
+	for i := range m.config.CustomAgents {
+		if m.config.CustomAgents[i].Name == agent.Name {
+			m.config.CustomAgents[i] = agent
+			return m.save()
+		}
+	}
+	m.config.CustomAgents = append(m.config.CustomAgents, agent)
+	return m.save()
+}
+
+// GetRepoAgent returns the default agent for a repository
+func (m *Manager) GetRepoAgent(repoPath string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.repoAgent(repoPath)
+}
+
+// repoAgent is GetRepoAgent for callers already holding the lock
+func (m *Manager) repoAgent(repoPath string) string {
+	if repo, ok := m.config.Repositories[repoPath]; ok && repo.Agent != "" {
+		return repo.Agent
+	}
+	return DefaultAgentName()
+}
+
+// SetRepoAgent sets the default agent for a repository ("" = build-time default)
+func (m *Manager) SetRepoAgent(repoPath, agent string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.config.Repositories == nil {
+		m.config.Repositories = make(map[string]*RepoConfig)
+	}
+
+	if _, ok := m.config.Repositories[repoPath]; !ok {
+		m.config.Repositories[repoPath] = &RepoConfig{}
+	}
+
+	m.config.Repositories[repoPath].Agent = agent
+	return m.save()
+}
+
+// GetWorktreeAgents returns the agents to run in a worktree's session.
+// The first agent runs in the main agent window, the rest in their own windows.
+// Falls back to the repository default.
+func (m *Manager) GetWorktreeAgents(repoPath, branch string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if repo, ok := m.config.Repositories[repoPath]; ok {
+		if agents := repo.WorktreeAgents[branch]; len(agents) > 0 {
+			return slices.Clone(agents)
+		}
+	}
+	if agent := m.repoAgent(repoPath); agent != "" {
+		return []string{agent}
+	}
+	return []string{}
+}
+
+// SetWorktreeAgents sets the agents for a worktree (empty = use the repository default)
+func (m *Manager) SetWorktreeAgents(repoPath, branch string, agents []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.config.Repositories == nil {
+		m.config.Repositories = make(map[string]*RepoConfig)
+	}
+
+	if _, ok := m.config.Repositories[repoPath]; !ok {
+		m.config.Repositories[repoPath] = &RepoConfig{}
+	}
+
+	repo := m.config.Repositories[repoPath]
+	if len(agents) == 0 {
+		delete(repo.WorktreeAgents, branch)
+		return m.save()
+	}
+
+	if repo.WorktreeAgents == nil {
+		repo.WorktreeAgents = make(map[string][]string)
+	}
+	repo.WorktreeAgents[branch] = agents
+	return m.save()
+}
+
+// GetRepoAgentArgs returns the launch arguments of a repository's main agent
+func (m *Manager) GetRepoAgentArgs(repoPath string) AgentArgs {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.repoAgentArgs(repoPath)
+}
+
+// repoAgentArgs is GetRepoAgentArgs for callers already holding the lock
+func (m *Manager) repoAgentArgs(repoPath string) AgentArgs {
+	if repo, ok := m.config.Repositories[repoPath]; ok && repo.AgentArgs != nil {
+		return repo.AgentArgs.clone()
+	}
+	return AgentArgs{}
+}
+
+// SetRepoAgentArgs sets the launch arguments of a repository's main agent (zero = agent defaults)
+func (m *Manager) SetRepoAgentArgs(repoPath string, args AgentArgs) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.config.Repositories == nil {
+		m.config.Repositories = make(map[string]*RepoConfig)
+	}
+
+	if _, ok := m.config.Repositories[repoPath]; !ok {
+		m.config.Repositories[repoPath] = &RepoConfig{}
+	}
+
+	if args.IsZero() {
+		m.config.Repositories[repoPath].AgentArgs = nil
+	} else {
+		m.config.Repositories[repoPath].AgentArgs = &args
+	}
+	return m.save()
+}
+
+// GetWorktreeAgentArgs returns the launch arguments set for a worktree itself
+func (m *Manager) GetWorktreeAgentArgs(repoPath, branch string) AgentArgs {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.worktreeAgentArgs(repoPath, branch)
+}
+
+// worktreeAgentArgs is GetWorktreeAgentArgs for callers already holding the lock
+func (m *Manager) worktreeAgentArgs(repoPath, branch string) AgentArgs {
+	if repo, ok := m.config.Repositories[repoPath]; ok {
+		return repo.WorktreeAgentArgs[branch].clone()
+	}
+	return AgentArgs{}
+}
+
+// SetWorktreeAgentArgs sets the launch arguments of a worktree's main agent (zero = use the repository's)
+func (m *Manager) SetWorktreeAgentArgs(repoPath, branch string, args AgentArgs) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.config.Repositories == nil {
+		m.config.Repositories = make(map[string]*RepoConfig)
+	}
+
+	if _, ok := m.config.Repositories[repoPath]; !ok {
+		m.config.Repositories[repoPath] = &RepoConfig{}
+	}
+
+	repo := m.config.Repositories[repoPath]
+	if args.IsZero() {
+		delete(repo.WorktreeAgentArgs, branch)
+		return m.save()
+	}
+
+	if repo.WorktreeAgentArgs == nil {
+		repo.WorktreeAgentArgs = make(map[string]AgentArgs)
+	}
+	repo.WorktreeAgentArgs[branch] = args
+	return m.save()
+}
+
+// GetAgentArgs returns the launch arguments of a worktree's main agent: the worktree's
+// permission mode and extra arguments, each falling back to the repository's
+func (m *Manager) GetAgentArgs(repoPath, branch string) AgentArgs {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	args := m.repoAgentArgs(repoPath)
+	worktree := m.worktreeAgentArgs(repoPath, branch)
+	if worktree.PermissionMode != "" {
+		args.PermissionMode = worktree.PermissionMode
+	}
+	if len(worktree.ExtraArgs) > 0 {
+		args.ExtraArgs = worktree.ExtraArgs
+	}
+	return args
+}
@@ -3,8 +3,11 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"sync"
 
 	"github.com/andrew-bierman/jean-tui/internal/branding"
 	"github.com/andrew-bierman/jean-tui/openrouter"
@@ -30,6 +33,16 @@ type Config struct {
 	AIPrompts           *AIPrompts             `json:"ai_prompts,omitempty"` // Customizable AI prompts
 	WrapperChecksums    map[string]string      `json:"wrapper_checksums,omitempty"` // Shell -> SHA256 checksum of installed wrapper
 	Onboarded           bool                   `json:"onboarded"` // Whether the user has completed the onboarding flow
+	CustomAgents        []AgentDefinition      `json:"custom_agents,omitempty"` // User-defined agents (see BuiltinAgents)
+	Notifications       *NotificationConfig    `json:"notifications,omitempty"` // Alerts when an agent finishes or needs input
+	ScrollbackAutosave  int                    `json:"scrollback_autosave_minutes,omitempty"` // Periodically archive running sessions, 0 = only on kill/delete
+	PortRangeStart      int                    `json:"port_range_start,omitempty"` // First port handed out to worktrees, 0 = default (4000)
+	PortBlockSize       int                    `json:"port_block_size,omitempty"` // Ports per worktree, 0 = default (10)
+	Multiplexer         string                 `json:"multiplexer,omitempty"` // Session backend: "tmux" (default), "zellij" or "none"
+	AgentPrompts        map[string]string      `json:"agent_prompts,omitempty"` // Prompt library for agent sessions (name -> text)
+	AgentPreview        bool                   `json:"agent_preview,omitempty"` // Show the agent's output in the details panel
+	AgentPreviewLines   int                    `json:"agent_preview_lines,omitempty"` // Lines of agent output to show, 0 = default (15)
+	TimeTracking        bool                   `json:"time_tracking,omitempty"` // Record attached and agent-busy time per worktree
 }
 
 // PRInfo represents information about a pull request
@@ -53,12 +66,26 @@ type RepoConfig struct {
 	PRDefaultState     string            `json:"pr_default_state,omitempty"`    // "draft" or "ready", "" = use default (ready)
 	PRs                map[string][]PRInfo `json:"prs,omitempty"`                 // branch -> list of PRs
 	InitializedClaudes map[string]bool   `json:"initialized_claudes,omitempty"` // branch -> whether Claude has been started
+	InitialPrompts     map[string]string `json:"initial_prompts,omitempty"`     // branch -> prompt the agent session was started with
+	Agent              string            `json:"agent,omitempty"`               // Default agent for new sessions, "" = build-time default
+	WorktreeAgents     map[string][]string `json:"worktree_agents,omitempty"`   // branch -> agents to run side by side (first is the main agent)
+	Ports              map[string]PortBlock `json:"ports,omitempty"`            // branch -> its allocated port block
+	Conversations      map[string]AgentConversation `json:"conversations,omitempty"` // branch -> conversation the main agent resumes
+	AgentArgs          *AgentArgs        `json:"agent_args,omitempty"`          // Launch arguments of the main agent
+	WorktreeAgentArgs  map[string]AgentArgs `json:"worktree_agent_args,omitempty"` // branch -> launch arguments overriding AgentArgs
+}
+
+// AgentConversation identifies the conversation a worktree's main agent resumes
+type AgentConversation struct {
+	Agent string `json:"agent"` // Agent the conversation belongs to
+	ID    string `json:"id"`    // The agent's session/conversation ID
 }
 
 // Manager handles configuration loading and saving
 type Manager struct {
 	configPath string
 	config     *Config
+	mu         sync.Mutex // Guards config: the TUI reads and writes it from background commands too
 }
 
 // NewManager creates a new configuration manager
@@ -115,6 +142,9 @@ func (m *Manager) save() error {
 
 // GetBaseBranch returns the base branch for a repository
 func (m *Manager) GetBaseBranch(repoPath string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if repo, ok := m.config.Repositories[repoPath]; ok {
 		return repo.BaseBranch
 	}
@@ -123,6 +153,9 @@ func (m *Manager) GetBaseBranch(repoPath string) string {
 
 // SetBaseBranch sets the base branch for a repository
 func (m *Manager) SetBaseBranch(repoPath, branch string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if m.config.Repositories == nil {
 		m.config.Repositories = make(map[string]*RepoConfig)
 	}
@@ -137,14 +170,53 @@ func (m *Manager) SetBaseBranch(repoPath, branch string) error {
 
 // GetRepoConfig returns the configuration for a specific repository
 func (m *Manager) GetRepoConfig(repoPath string) *RepoConfig {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if repo, ok := m.config.Repositories[repoPath]; ok {
-		return repo
+		return repo.clone()
 	}
 	return &RepoConfig{}
 }
 
+// clone returns a deep copy of the repository config, so callers can't change the manager's state
+// without holding the lock
+func (r *RepoConfig) clone() *RepoConfig {
+	c := *r
+	if r.PRs != nil {
+		c.PRs = make(map[string][]PRInfo, len(r.PRs))
+		for branch, prs := range r.PRs {
+			c.PRs[branch] = slices.Clone(prs)
+		}
+	}
+	if r.WorktreeAgents != nil {
+		c.WorktreeAgents = make(map[string][]string, len(r.WorktreeAgents))
+		for branch, agents := range r.WorktreeAgents {
+			c.WorktreeAgents[branch] = slices.Clone(agents)
+		}
+	}
+	if r.WorktreeAgentArgs != nil {
+		c.WorktreeAgentArgs = make(map[string]AgentArgs, len(r.WorktreeAgentArgs))
+		for branch, args := range r.WorktreeAgentArgs {
+			c.WorktreeAgentArgs[branch] = args.clone()
+		}
+	}
+	if r.AgentArgs != nil {
+		args := r.AgentArgs.clone()
+		c.AgentArgs = &args
+	}
+	c.InitializedClaudes = maps.Clone(r.InitializedClaudes)
+	c.InitialPrompts = maps.Clone(r.InitialPrompts)
+	c.Ports = maps.Clone(r.Ports)
+	c.Conversations = maps.Clone(r.Conversations)
+	return &c
+}
+
 // GetLastSelectedBranch returns the last selected branch for a repository
 func (m *Manager) GetLastSelectedBranch(repoPath string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if repo, ok := m.config.Repositories[repoPath]; ok {
 		return repo.LastSelectedBranch
 	}
@@ -153,6 +225,9 @@ func (m *Manager) GetLastSelectedBranch(repoPath string) string {
 
 // SetLastSelectedBranch sets the last selected branch for a repository
 func (m *Manager) SetLastSelectedBranch(repoPath, branch string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if m.config.Repositories == nil {
 		m.config.Repositories = make(map[string]*RepoConfig)
 	}
@@ -167,6 +242,9 @@ func (m *Manager) SetLastSelectedBranch(repoPath, branch string) error {
 
 // GetEditor returns the preferred editor for a repository
 func (m *Manager) GetEditor(repoPath string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if repo, ok := m.config.Repositories[repoPath]; ok {
 		if repo.Editor != "" {
 			return repo.Editor
@@ -177,6 +255,9 @@ func (m *Manager) GetEditor(repoPath string) string {
 
 // SetEditor sets the preferred editor for a repository
 func (m *Manager) SetEditor(repoPath, editor string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if m.config.Repositories == nil {
 		m.config.Repositories = make(map[string]*RepoConfig)
 	}
@@ -192,6 +273,9 @@ func (m *Manager) SetEditor(repoPath, editor string) error {
 // GetAutoFetchInterval returns the auto-fetch interval for a repository
 // Returns the configured interval in seconds, or 10 if not set
 func (m *Manager) GetAutoFetchInterval(repoPath string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if repo, ok := m.config.Repositories[repoPath]; ok {
 		if repo.AutoFetchInterval > 0 {
 			return repo.AutoFetchInterval
@@ -202,6 +286,9 @@ func (m *Manager) GetAutoFetchInterval(repoPath string) int {
 
 // SetAutoFetchInterval sets the auto-fetch interval for a repository
 func (m *Manager) SetAutoFetchInterval(repoPath string, interval int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if m.config.Repositories == nil {
 		m.config.Repositories = make(map[string]*RepoConfig)
 	}
@@ -216,11 +303,17 @@ func (m *Manager) SetAutoFetchInterval(repoPath string, interval int) error {
 
 // GetLastUpdateCheckTime returns the last update check time
 func (m *Manager) GetLastUpdateCheckTime() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	return m.config.LastUpdateCheckTime
 }
 
 // SetLastUpdateCheckTime sets the last update check time
 func (m *Manager) SetLastUpdateCheckTime(timestamp string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	m.config.LastUpdateCheckTime = timestamp
 	return m.save()
 }
@@ -229,6 +322,9 @@ func (m *Manager) SetLastUpdateCheckTime(timestamp string) error {
 // Returns per-repo theme if set, otherwise returns global default theme
 // Returns "coolify" if no theme is configured
 func (m *Manager) GetTheme(repoPath string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	// Check if repo has a per-repo theme override
 	if repo, ok := m.config.Repositories[repoPath]; ok {
 		if repo.Theme != "" {
@@ -248,6 +344,9 @@ func (m *Manager) GetTheme(repoPath string) string {
 // SetTheme sets the theme for a specific repository
 // If theme is empty string, it will use the global default
 func (m *Manager) SetTheme(repoPath, theme string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if m.config.Repositories == nil {
 		m.config.Repositories = make(map[string]*RepoConfig)
 	}
@@ -262,6 +361,9 @@ func (m *Manager) SetTheme(repoPath, theme string) error {
 
 // SetGlobalTheme sets the global default theme for all repositories
 func (m *Manager) SetGlobalTheme(theme string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	m.config.DefaultTheme = theme
 	return m.save()
 }
@@ -269,6 +371,9 @@ func (m *Manager) SetGlobalTheme(theme string) error {
 // GetGlobalTheme returns the global default theme
 // Returns "coolify" if not set
 func (m *Manager) GetGlobalTheme() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if m.config.DefaultTheme != "" {
 		return m.config.DefaultTheme
 	}
@@ -277,11 +382,17 @@ func (m *Manager) GetGlobalTheme() string {
 
 // GetOpenRouterAPIKey returns the OpenRouter API key
 func (m *Manager) GetOpenRouterAPIKey() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	return m.config.OpenRouterAPIKey
 }
 
 // SetOpenRouterAPIKey sets the OpenRouter API key
 func (m *Manager) SetOpenRouterAPIKey(apiKey string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	m.config.OpenRouterAPIKey = apiKey
 	return m.save()
 }
@@ -289,6 +400,9 @@ func (m *Manager) SetOpenRouterAPIKey(apiKey string) error {
 // GetOpenRouterModel returns the OpenRouter model
 // Returns "openai/gpt-4o-mini" if not set
 func (m *Manager) GetOpenRouterModel() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if m.config.OpenRouterModel != "" {
 		return m.config.OpenRouterModel
 	}
@@ -297,45 +411,74 @@ func (m *Manager) GetOpenRouterModel() string {
 
 // SetOpenRouterModel sets the OpenRouter model
 func (m *Manager) SetOpenRouterModel(model string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	m.config.OpenRouterModel = model
 	return m.save()
 }
 
 // GetAICommitEnabled returns whether AI commit message generation is enabled
 func (m *Manager) GetAICommitEnabled() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	return m.config.AICommitEnabled
 }
 
 // SetAICommitEnabled sets whether AI commit message generation is enabled
 func (m *Manager) SetAICommitEnabled(enabled bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	m.config.AICommitEnabled = enabled
 	return m.save()
 }
 
 // GetAIBranchNameEnabled returns whether AI branch name generation is enabled
 func (m *Manager) GetAIBranchNameEnabled() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	return m.config.AIBranchNameEnabled
 }
 
 // SetAIBranchNameEnabled sets whether AI branch name generation is enabled
 func (m *Manager) SetAIBranchNameEnabled(enabled bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	m.config.AIBranchNameEnabled = enabled
 	return m.save()
 }
 
 // GetDebugLoggingEnabled returns whether debug logging is enabled
 func (m *Manager) GetDebugLoggingEnabled() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	return m.config.DebugLoggingEnabled
 }
 
 // SetDebugLoggingEnabled sets whether debug logging is enabled
 func (m *Manager) SetDebugLoggingEnabled(enabled bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	m.config.DebugLoggingEnabled = enabled
 	return m.save()
 }
 
 // GetPRs returns all pull requests for a given branch
 func (m *Manager) GetPRs(repoPath, branch string) []PRInfo {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.prs(repoPath, branch)
+}
+
+// prs is GetPRs for callers already holding the lock
+func (m *Manager) prs(repoPath, branch string) []PRInfo {
 	if repo, ok := m.config.Repositories[repoPath]; ok {
 		if repo.PRs != nil {
 			if prs, ok := repo.PRs[branch]; ok {
@@ -348,7 +491,10 @@ func (m *Manager) GetPRs(repoPath, branch string) []PRInfo {
 
 // GetLatestPR returns the most recent pull request for a given branch
 func (m *Manager) GetLatestPR(repoPath, branch string) *PRInfo {
-	prs := m.GetPRs(repoPath, branch)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	prs := m.prs(repoPath, branch)
 	if len(prs) == 0 {
 		return nil
 	}
@@ -357,6 +503,9 @@ func (m *Manager) GetLatestPR(repoPath, branch string) *PRInfo {
 
 // AddPR adds a pull request for a given branch
 func (m *Manager) AddPR(repoPath, branch, url string, prNumber int, title string, author string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if m.config.Repositories == nil {
 		m.config.Repositories = make(map[string]*RepoConfig)
 	}
@@ -392,6 +541,9 @@ func (m *Manager) AddPR(repoPath, branch, url string, prNumber int, title string
 
 // UpdatePRStatus updates the status of a pull request
 func (m *Manager) UpdatePRStatus(repoPath, branch, url, status string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if repo, ok := m.config.Repositories[repoPath]; ok {
 		if repo.PRs != nil {
 			if prs, ok := repo.PRs[branch]; ok {
@@ -409,6 +561,9 @@ func (m *Manager) UpdatePRStatus(repoPath, branch, url, status string) error {
 
 // RemovePR removes a pull request
 func (m *Manager) RemovePR(repoPath, branch, url string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if repo, ok := m.config.Repositories[repoPath]; ok {
 		if repo.PRs != nil {
 			if prs, ok := repo.PRs[branch]; ok {
@@ -428,12 +583,18 @@ func (m *Manager) RemovePR(repoPath, branch, url string) error {
 
 // HasPRs checks if there are any pull requests for a given branch
 func (m *Manager) HasPRs(repoPath, branch string) bool {
-	prs := m.GetPRs(repoPath, branch)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	prs := m.prs(repoPath, branch)
 	return len(prs) > 0
 }
 
 // IsClaudeInitialized checks if a Claude session has been initialized for a branch
 func (m *Manager) IsClaudeInitialized(repoPath, branch string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if repo, ok := m.config.Repositories[repoPath]; ok {
 		if repo.InitializedClaudes != nil {
 			return repo.InitializedClaudes[branch]
@@ -444,6 +605,9 @@ func (m *Manager) IsClaudeInitialized(repoPath, branch string) bool {
 
 // SetClaudeInitialized marks a branch as having an initialized Claude session
 func (m *Manager) SetClaudeInitialized(repoPath, branch string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if m.config.Repositories == nil {
 		m.config.Repositories = make(map[string]*RepoConfig)
 	}
@@ -461,12 +625,110 @@ func (m *Manager) SetClaudeInitialized(repoPath, branch string) error {
 	return m.save()
 }
 
+// GetInitialPrompt returns the prompt the agent for a branch was started with ("" if none)
+func (m *Manager) GetInitialPrompt(repoPath, branch string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if repo, ok := m.config.Repositories[repoPath]; ok {
+		if repo.InitialPrompts != nil {
+			return repo.InitialPrompts[branch]
+		}
+	}
+	return ""
+}
+
+// SetInitialPrompt records the prompt the agent for a branch was started with
+func (m *Manager) SetInitialPrompt(repoPath, branch, prompt string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.config.Repositories == nil {
+		m.config.Repositories = make(map[string]*RepoConfig)
+	}
+
+	if _, ok := m.config.Repositories[repoPath]; !ok {
+		m.config.Repositories[repoPath] = &RepoConfig{}
+	}
+
+	repo := m.config.Repositories[repoPath]
+	if repo.InitialPrompts == nil {
+		repo.InitialPrompts = make(map[string]string)
+	}
+
+	if prompt == "" {
+		delete(repo.InitialPrompts, branch)
+	} else {
+		repo.InitialPrompts[branch] = prompt
+	}
+	return m.save()
+}
+
+// ClearClaudeInitialized makes the next agent start for a branch a fresh conversation
+func (m *Manager) ClearClaudeInitialized(repoPath, branch string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	repo, ok := m.config.Repositories[repoPath]
+	if !ok || repo.InitializedClaudes == nil {
+		return nil
+	}
+	delete(repo.InitializedClaudes, branch)
+	return m.save()
+}
+
+// GetConversation returns the conversation recorded for a branch's main agent
+func (m *Manager) GetConversation(repoPath, branch string) (AgentConversation, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if repo, ok := m.config.Repositories[repoPath]; ok {
+		if conv, ok := repo.Conversations[branch]; ok && conv.ID != "" {
+			return conv, true
+		}
+	}
+	return AgentConversation{}, false
+}
+
+// SetConversation records the conversation a branch's main agent resumes (an empty ID clears it)
+func (m *Manager) SetConversation(repoPath, branch string, conv AgentConversation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.config.Repositories == nil {
+		m.config.Repositories = make(map[string]*RepoConfig)
+	}
+
+	if _, ok := m.config.Repositories[repoPath]; !ok {
+		m.config.Repositories[repoPath] = &RepoConfig{}
+	}
+
+	repo := m.config.Repositories[repoPath]
+	if repo.Conversations == nil {
+		repo.Conversations = make(map[string]AgentConversation)
+	}
+
+	if conv.ID == "" {
+		delete(repo.Conversations, branch)
+	} else {
+		repo.Conversations[branch] = conv
+	}
+	return m.save()
+}
+
 // CleanupBranch removes all branch-specific data from config when a worktree is deleted
 // This includes:
 // - All pull requests for the branch
 // - Claude initialization flag
+// - Initial agent prompt
+// - Recorded agent conversation
+// - Selected agents and their launch arguments
+// - Allocated ports
 // - Last selected branch reference (if it matches the deleted branch)
 func (m *Manager) CleanupBranch(repoPath, branch string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	repo, ok := m.config.Repositories[repoPath]
 	if !ok {
 		return nil // Nothing to clean up
@@ -482,6 +744,29 @@ func (m *Manager) CleanupBranch(repoPath, branch string) error {
 		delete(repo.InitializedClaudes, branch)
 	}
 
+	// Remove the initial agent prompt for this branch
+	if repo.InitialPrompts != nil {
+		delete(repo.InitialPrompts, branch)
+	}
+
+	// Remove the recorded agent conversation for this branch
+	if repo.Conversations != nil {
+		delete(repo.Conversations, branch)
+	}
+
+	// Remove the agent selection for this branch
+	if repo.WorktreeAgents != nil {
+		delete(repo.WorktreeAgents, branch)
+	}
+	if repo.WorktreeAgentArgs != nil {
+		delete(repo.WorktreeAgentArgs, branch)
+	}
+
+	// Free the ports allocated to this branch
+	if repo.Ports != nil {
+		delete(repo.Ports, branch)
+	}
+
 	// Clear last selected branch if it matches the deleted branch
 	if repo.LastSelectedBranch == branch {
 		repo.LastSelectedBranch = ""
@@ -490,9 +775,94 @@ func (m *Manager) CleanupBranch(repoPath, branch string) error {
 	return m.save()
 }
 
+// GetScrollbackAutosave returns how often running sessions are archived in minutes (0 = disabled)
+func (m *Manager) GetScrollbackAutosave() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.config.ScrollbackAutosave
+}
+
+// SetScrollbackAutosave sets how often running sessions are archived in minutes (0 = disabled)
+func (m *Manager) SetScrollbackAutosave(minutes int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.config.ScrollbackAutosave = minutes
+	return m.save()
+}
+
+// GetTimeTracking returns whether attached and agent-busy time is recorded per worktree
+func (m *Manager) GetTimeTracking() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.config.TimeTracking
+}
+
+// SetTimeTracking enables or disables recording attached and agent-busy time per worktree
+func (m *Manager) SetTimeTracking(enabled bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.config.TimeTracking = enabled
+	return m.save()
+}
+
+// GetMultiplexer returns the session backend ("tmux" by default)
+func (m *Manager) GetMultiplexer() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.config.Multiplexer == "" {
+		return "tmux"
+	}
+	return m.config.Multiplexer
+}
+
+// SetMultiplexer sets the session backend ("tmux", "zellij" or "none")
+func (m *Manager) SetMultiplexer(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.config.Multiplexer = name
+	return m.save()
+}
+
+// GetAgentPreview returns whether the agent's output is shown in the details panel
+func (m *Manager) GetAgentPreview() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.config.AgentPreview
+}
+
+// SetAgentPreview sets whether the agent's output is shown in the details panel
+func (m *Manager) SetAgentPreview(enabled bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.config.AgentPreview = enabled
+	return m.save()
+}
+
+// GetAgentPreviewLines returns how many lines of agent output the preview shows (default 15)
+func (m *Manager) GetAgentPreviewLines() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.config.AgentPreviewLines <= 0 {
+		return 15
+	}
+	return m.config.AgentPreviewLines
+}
+
 // GetCommitPrompt returns the custom commit message prompt
 // Returns the custom prompt if set, otherwise returns the default prompt
 func (m *Manager) GetCommitPrompt() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if m.config.AIPrompts != nil && m.config.AIPrompts.CommitMessage != "" {
 		return m.config.AIPrompts.CommitMessage
 	}
@@ -501,6 +871,9 @@ func (m *Manager) GetCommitPrompt() string {
 
 // SetCommitPrompt sets the custom commit message prompt
 func (m *Manager) SetCommitPrompt(prompt string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if m.config.AIPrompts == nil {
 		m.config.AIPrompts = &AIPrompts{}
 	}
@@ -511,6 +884,9 @@ func (m *Manager) SetCommitPrompt(prompt string) error {
 // GetBranchNamePrompt returns the custom branch name prompt
 // Returns the custom prompt if set, otherwise returns the default prompt
 func (m *Manager) GetBranchNamePrompt() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if m.config.AIPrompts != nil && m.config.AIPrompts.BranchName != "" {
 		return m.config.AIPrompts.BranchName
 	}
@@ -519,6 +895,9 @@ func (m *Manager) GetBranchNamePrompt() string {
 
 // SetBranchNamePrompt sets the custom branch name prompt
 func (m *Manager) SetBranchNamePrompt(prompt string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if m.config.AIPrompts == nil {
 		m.config.AIPrompts = &AIPrompts{}
 	}
@@ -529,6 +908,9 @@ func (m *Manager) SetBranchNamePrompt(prompt string) error {
 // GetPRPrompt returns the custom PR content prompt
 // Returns the custom prompt if set, otherwise returns the default prompt
 func (m *Manager) GetPRPrompt() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if m.config.AIPrompts != nil && m.config.AIPrompts.PRContent != "" {
 		return m.config.AIPrompts.PRContent
 	}
@@ -537,6 +919,9 @@ func (m *Manager) GetPRPrompt() string {
 
 // SetPRPrompt sets the custom PR content prompt
 func (m *Manager) SetPRPrompt(prompt string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if m.config.AIPrompts == nil {
 		m.config.AIPrompts = &AIPrompts{}
 	}
@@ -546,6 +931,9 @@ func (m *Manager) SetPRPrompt(prompt string) error {
 
 // ResetAIPromptsToDefaults resets all AI prompts to their default values
 func (m *Manager) ResetAIPromptsToDefaults() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	m.config.AIPrompts = &AIPrompts{} // Empty AIPrompts means use defaults
 	return m.save()
 }
@@ -553,6 +941,9 @@ func (m *Manager) ResetAIPromptsToDefaults() error {
 // GetWrapperChecksum returns the stored checksum for a shell wrapper
 // Returns empty string if no checksum is stored
 func (m *Manager) GetWrapperChecksum(shell string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if m.config.WrapperChecksums == nil {
 		return ""
 	}
@@ -561,6 +952,9 @@ func (m *Manager) GetWrapperChecksum(shell string) string {
 
 // SetWrapperChecksum stores the checksum for a shell wrapper
 func (m *Manager) SetWrapperChecksum(shell, checksum string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if m.config.WrapperChecksums == nil {
 		m.config.WrapperChecksums = make(map[string]string)
 	}
@@ -570,11 +964,17 @@ func (m *Manager) SetWrapperChecksum(shell, checksum string) error {
 
 // IsOnboarded returns whether the user has completed the onboarding flow
 func (m *Manager) IsOnboarded() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	return m.config.Onboarded
 }
 
 // SetOnboarded marks the onboarding flow as completed
 func (m *Manager) SetOnboarded() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	m.config.Onboarded = true
 	return m.save()
 }
@@ -582,6 +982,9 @@ func (m *Manager) SetOnboarded() error {
 // GetPRDefaultState returns the default PR state for a repository
 // Returns "draft" or "ready", defaults to "ready" if not set
 func (m *Manager) GetPRDefaultState(repoPath string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if repo, ok := m.config.Repositories[repoPath]; ok {
 		if repo.PRDefaultState == "draft" || repo.PRDefaultState == "ready" {
 			return repo.PRDefaultState
@@ -592,6 +995,9 @@ func (m *Manager) GetPRDefaultState(repoPath string) string {
 
 // SetPRDefaultState sets the default PR state for a repository
 func (m *Manager) SetPRDefaultState(repoPath, state string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if m.config.Repositories == nil {
 		m.config.Repositories = make(map[string]*RepoConfig)
 	}
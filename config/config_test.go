@@ -0,0 +1,58 @@
+package config
+
+import "testing"
+
+// newTestManager returns a manager whose config lives in a temporary home directory
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	m, err := NewManager()
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+	return m
+}
+
+// TestGetRepoConfig_ReturnsCopy tests that changing the returned config leaves the manager's alone
+func TestGetRepoConfig_ReturnsCopy(t *testing.T) {
+	m := newTestManager(t)
+	const repo = "/repo"
+	m.SetBaseBranch(repo, "main")
+	m.SetWorktreeAgents(repo, "feature", []string{"claude", "codex"})
+	m.SetRepoAgentArgs(repo, AgentArgs{ExtraArgs: []string{"--model", "opus"}})
+
+	got := m.GetRepoConfig(repo)
+	got.BaseBranch = "changed"
+	got.WorktreeAgents["feature"][0] = "changed"
+	got.WorktreeAgents["other"] = []string{"changed"}
+	got.AgentArgs.ExtraArgs[1] = "changed"
+
+	again := m.GetRepoConfig(repo)
+	if again.BaseBranch != "main" {
+		t.Errorf("Expected base branch main, got %s", again.BaseBranch)
+	}
+	if agents := m.GetWorktreeAgents(repo, "feature"); agents[0] != "claude" {
+		t.Errorf("Expected claude first, got %v", agents)
+	}
+	if _, ok := again.WorktreeAgents["other"]; ok {
+		t.Error("Expected no agents for other")
+	}
+	if args := m.GetRepoAgentArgs(repo); args.ExtraArgs[1] != "opus" {
+		t.Errorf("Expected --model opus, got %v", args.ExtraArgs)
+	}
+}
+
+// TestGetAgentPrompts_ReturnsCopy tests that changing the returned prompts leaves the library alone
+func TestGetAgentPrompts_ReturnsCopy(t *testing.T) {
+	m := newTestManager(t)
+	m.SaveAgentPrompt("review", "Review the diff")
+
+	prompts := m.GetAgentPrompts()
+	prompts["review"] = "changed"
+	prompts["other"] = "added"
+
+	again := m.GetAgentPrompts()
+	if len(again) != 1 || again["review"] != "Review the diff" {
+		t.Errorf("Expected the library unchanged, got %v", again)
+	}
+}
@@ -0,0 +1,48 @@
+package config
+
+// NotificationConfig controls how jean alerts when an agent finishes or needs input
+type NotificationConfig struct {
+	Bell        bool   `json:"bell"`              // Ring the terminal bell
+	TmuxMessage bool   `json:"tmux_message"`      // Show a tmux display-message in attached clients
+	Desktop     bool   `json:"desktop"`           // Desktop notification via OSC 9 / OSC 777 escape sequences
+	Command     string `json:"command,omitempty"` // Shell command to run (gets JEAN_NOTIFY_TITLE, JEAN_NOTIFY_MESSAGE, JEAN_BRANCH, JEAN_SESSION)
+}
+
+// Enabled reports whether any alert is configured
+func (n NotificationConfig) Enabled() bool {
+	return n.Bell || n.TmuxMessage || n.Desktop || n.Command != ""
+}
+
+// GetNotificationConfig returns the notification settings (bell and tmux message by default)
+func (m *Manager) GetNotificationConfig() NotificationConfig {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.config.Notifications == nil {
+		return NotificationConfig{Bell: true, TmuxMessage: true}
+	}
+	return *m.config.Notifications
+}
+
+// SetNotificationConfig saves the notification settings
+func (m *Manager) SetNotificationConfig(n NotificationConfig) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.config.Notifications = &n
+	return m.save()
+}
+
+// Reload re-reads the configuration from disk (used by long-running processes like the watcher).
+// The current configuration is kept if the file can't be read, e.g. while it is being written.
+func (m *Manager) Reload() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current := m.config
+	if err := m.load(); err != nil {
+		m.config = current
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/andrew-bierman/jean-tui/internal/branding"
+)
+
+const (
+	defaultPortRangeStart = 4000
+	defaultPortBlockSize  = 10
+	maxPort               = 65535
+)
+
+// PortBlock is a range of consecutive ports allocated to a worktree.
+// The size is kept with the block so changing the block size only affects new allocations.
+type PortBlock struct {
+	Base int `json:"base"`
+	Size int `json:"size"`
+}
+
+// UnmarshalJSON also reads blocks saved as just their first port by older versions
+// (Size 0, meaning the configured block size)
+func (b *PortBlock) UnmarshalJSON(data []byte) error {
+	var base int
+	if json.Unmarshal(data, &base) == nil {
+		*b = PortBlock{Base: base}
+		return nil
+	}
+	type plain PortBlock
+	return json.Unmarshal(data, (*plain)(b))
+}
+
+// GetPortRangeStart returns the first port handed out to worktrees
+func (m *Manager) GetPortRangeStart() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.portRangeStart()
+}
+
+// portRangeStart is GetPortRangeStart for callers already holding the lock
+func (m *Manager) portRangeStart() int {
+	if m.config.PortRangeStart <= 0 {
+		return defaultPortRangeStart
+	}
+	return m.config.PortRangeStart
+}
+
+// GetPortBlockSize returns how many consecutive ports each newly allocated block gets
+func (m *Manager) GetPortBlockSize() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.portBlockSize()
+}
+
+// portBlockSize is GetPortBlockSize for callers already holding the lock
+func (m *Manager) portBlockSize() int {
+	if m.config.PortBlockSize <= 0 {
+		return defaultPortBlockSize
+	}
+	return m.config.PortBlockSize
+}
+
+// GetPorts returns the ports allocated to a branch (nil if none are allocated yet)
+func (m *Manager) GetPorts(repoPath, branch string) []int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.ports(repoPath, branch)
+}
+
+// ports is GetPorts for callers already holding the lock
+func (m *Manager) ports(repoPath, branch string) []int {
+	repo, ok := m.config.Repositories[repoPath]
+	if !ok || repo.Ports == nil {
+		return nil
+	}
+	block, ok := repo.Ports[branch]
+	if !ok {
+		return nil
+	}
+
+	ports := make([]int, m.blockSize(block))
+	for i := range ports {
+		ports[i] = block.Base + i
+	}
+	return ports
+}
+
+// blockSize returns the size of an allocated block (the configured size for blocks saved without one)
+func (m *Manager) blockSize(block PortBlock) int {
+	if block.Size > 0 {
+		return block.Size
+	}
+	return m.portBlockSize()
+}
+
+// AllocatePorts returns the ports of a branch, allocating a free block first if needed.
+// Blocks are unique across all repositories and skip ports something is already listening on.
+func (m *Manager) AllocatePorts(repoPath, branch string) ([]int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if ports := m.ports(repoPath, branch); ports != nil {
+		return ports, nil
+	}
+
+	size := m.portBlockSize()
+	var taken []PortBlock
+	for _, repo := range m.config.Repositories {
+		for _, block := range repo.Ports {
+			taken = append(taken, PortBlock{Base: block.Base, Size: m.blockSize(block)})
+		}
+	}
+
+	for base := m.portRangeStart(); base+size-1 <= maxPort; base += size {
+		if blockOverlaps(base, size, taken) || !blockAvailable(base, size) {
+			continue
+		}
+
+		if m.config.Repositories == nil {
+			m.config.Repositories = make(map[string]*RepoConfig)
+		}
+		if m.config.Repositories[repoPath] == nil {
+			m.config.Repositories[repoPath] = &RepoConfig{}
+		}
+		if m.config.Repositories[repoPath].Ports == nil {
+			m.config.Repositories[repoPath].Ports = make(map[string]PortBlock)
+		}
+		m.config.Repositories[repoPath].Ports[branch] = PortBlock{Base: base, Size: size}
+		if err := m.save(); err != nil {
+			return nil, err
+		}
+		return m.ports(repoPath, branch), nil
+	}
+
+	return nil, fmt.Errorf("no free port block of %d ports left", size)
+}
+
+// blockOverlaps reports whether [base, base+size) overlaps any allocated block
+func blockOverlaps(base, size int, taken []PortBlock) bool {
+	for _, other := range taken {
+		if base < other.Base+other.Size && other.Base < base+size {
+			return true
+		}
+	}
+	return false
+}
+
+// blockAvailable reports whether nothing is listening on any port of the block
+func blockAvailable(base, size int) bool {
+	for port := base; port < base+size; port++ {
+		listener, err := net.Listen("tcp", "127.0.0.1:"+strconv.Itoa(port))
+		if err != nil {
+			return false
+		}
+		listener.Close()
+	}
+	return true
+}
+
+// PortEnv returns the environment for a port block: JEAN_PORT is the first port,
+// JEAN_PORT_<n> the n-th port after it (JEAN_PORT_1 = JEAN_PORT + 1, ...)
+func PortEnv(ports []int) []string {
+	if len(ports) == 0 {
+		return nil
+	}
+	env := []string{fmt.Sprintf("%s=%d", branding.GetEnvVar("PORT"), ports[0])}
+	for i := 1; i < len(ports); i++ {
+		env = append(env, fmt.Sprintf("%s_%d=%d", branding.GetEnvVar("PORT"), i, ports[i]))
+	}
+	return env
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"maps"
+	"sort"
+	"strings"
+)
+
+// Prompt is a reusable prompt that can be sent to agent sessions
+type Prompt struct {
+	Name   string
+	Text   string
+	Source string // "jean.json" or "config"
+}
+
+// GetAgentPrompts returns the prompt library from the user config (name -> text)
+func (m *Manager) GetAgentPrompts() map[string]string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.config.AgentPrompts == nil {
+		return map[string]string{}
+	}
+	return maps.Clone(m.config.AgentPrompts)
+}
+
+// SaveAgentPrompt adds or replaces a prompt in the user config's prompt library
+func (m *Manager) SaveAgentPrompt(name, text string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.config.AgentPrompts == nil {
+		m.config.AgentPrompts = make(map[string]string)
+	}
+	m.config.AgentPrompts[name] = text
+	return m.save()
+}
+
+// DeleteAgentPrompt removes a prompt from the user config's prompt library
+func (m *Manager) DeleteAgentPrompt(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.config.AgentPrompts, name)
+	return m.save()
+}
+
+// MergePrompts combines the jean.json prompts with the user's prompt library, sorted by name.
+// jean.json prompts win when both define the same name.
+func MergePrompts(repoPrompts, userPrompts map[string]string) []Prompt {
+	var prompts []Prompt
+	for name, text := range repoPrompts {
+		prompts = append(prompts, Prompt{Name: name, Text: text, Source: "jean.json"})
+	}
+	for name, text := range userPrompts {
+		if _, exists := repoPrompts[name]; !exists {
+			prompts = append(prompts, Prompt{Name: name, Text: text, Source: "config"})
+		}
+	}
+	sort.Slice(prompts, func(i, j int) bool {
+		return prompts[i].Name < prompts[j].Name
+	})
+	return prompts
+}
+
+// ExpandPrompt replaces {name} variables (e.g. {branch}, {base}) with their values.
+// Unknown variables are left as they are.
+func ExpandPrompt(text string, vars map[string]string) string {
+	pairs := make([]string, 0, len(vars)*2)
+	for name, value := range vars {
+		pairs = append(pairs, "{"+name+"}", value)
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
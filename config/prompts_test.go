@@ -0,0 +1,75 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestMergePrompts tests that jean.json prompts override the user's library and the result is sorted
+func TestMergePrompts(t *testing.T) {
+	tests := []struct {
+		name string
+		repo map[string]string
+		user map[string]string
+		want []Prompt
+	}{
+		{"empty", nil, nil, nil},
+		{
+			name: "user only",
+			user: map[string]string{"review": "Review {branch}", "docs": "Write docs"},
+			want: []Prompt{
+				{Name: "docs", Text: "Write docs", Source: "config"},
+				{Name: "review", Text: "Review {branch}", Source: "config"},
+			},
+		},
+		{
+			name: "repo wins",
+			repo: map[string]string{"review": "Review against {base}"},
+			user: map[string]string{"review": "Review {branch}", "tests": "Add tests"},
+			want: []Prompt{
+				{Name: "review", Text: "Review against {base}", Source: "jean.json"},
+				{Name: "tests", Text: "Add tests", Source: "config"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MergePrompts(tt.repo, tt.user); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestExpandPrompt tests the substitution of prompt variables
+func TestExpandPrompt(t *testing.T) {
+	vars := map[string]string{"branch": "feature/login", "base": "main"}
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"no variables", "Fix the tests", "Fix the tests"},
+		{"known variables", "Rebase {branch} onto {base}", "Rebase feature/login onto main"},
+		{"repeated variable", "{branch} and {branch}", "feature/login and feature/login"},
+		{"unknown variable", "Mention {ticket} on {branch}", "Mention {ticket} on feature/login"},
+		{"unbraced name", "branch base", "branch base"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExpandPrompt(tt.text, vars); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestExpandPrompt_ValuesNotExpanded tests that variable values containing braces are inserted as they are
+func TestExpandPrompt_ValuesNotExpanded(t *testing.T) {
+	got := ExpandPrompt("{branch}", map[string]string{"branch": "{base}", "base": "main"})
+	if got != "{base}" {
+		t.Errorf("Expected {base}, got %q", got)
+	}
+}
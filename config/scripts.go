@@ -4,11 +4,55 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // ScriptConfig represents the jean.json configuration file
 type ScriptConfig struct {
 	Scripts map[string]string `json:"scripts"`
+	Layout  *LayoutConfig     `json:"layout,omitempty"`  // Optional tmux session layout
+	Prompts map[string]string `json:"prompts,omitempty"` // Prompts that can be sent to agent sessions (name -> text)
+	Task    *TaskConfig       `json:"task,omitempty"`    // How a task typed when creating a worktree is handed to the agent
+}
+
+// TaskConfig describes the task brief written into worktrees created from a task description.
+// Templates may use {task}, {title} (first line of the task), {branch}, {base}, {repo} and {path};
+// the prompt may also use {file}.
+type TaskConfig struct {
+	File     string `json:"file,omitempty"`     // Task file, relative to the worktree (default: .jean/TASK.md)
+	Template string `json:"template,omitempty"` // Content of the task file (default: the title as heading, then the task)
+	Prompt   string `json:"prompt,omitempty"`   // Initial prompt of the agent (default: the task and where the brief is)
+	Track    bool   `json:"track,omitempty"`    // Let git see the task file (default: excluded via .git/info/exclude)
+}
+
+// Defaults of the jean.json task section
+const (
+	DefaultTaskFile     = ".jean/TASK.md"
+	DefaultTaskTemplate = "# {title}\n\n{task}\n"
+	DefaultTaskPrompt   = "{task}\n\nThe task brief is saved in {file}."
+)
+
+// LayoutConfig describes the tmux windows created for each worktree session.
+// The default "terminal" (window 1) and agent (window 2) windows always exist;
+// listing them here customizes them (e.g. adds panes), other windows are added after them.
+type LayoutConfig struct {
+	Windows []LayoutWindow `json:"windows"`
+}
+
+// LayoutWindow describes a tmux window and its pane splits
+type LayoutWindow struct {
+	Name    string       `json:"name"`
+	Dir     string       `json:"dir,omitempty"`     // Working directory, relative to the worktree (default: worktree root)
+	Command string       `json:"command,omitempty"` // Startup command (default: shell)
+	Panes   []LayoutPane `json:"panes,omitempty"`   // Additional panes split from the window
+}
+
+// LayoutPane describes an additional pane split off a window
+type LayoutPane struct {
+	Split   string `json:"split,omitempty"`   // "horizontal" (side by side) or "vertical" (stacked, default)
+	Size    int    `json:"size,omitempty"`    // Size of the new pane in percent (default: tmux decides)
+	Dir     string `json:"dir,omitempty"`     // Working directory, relative to the worktree
+	Command string `json:"command,omitempty"` // Startup command (default: shell)
 }
 
 // LoadScripts loads the jean.json file from a repository path
@@ -67,3 +111,67 @@ func (s *ScriptConfig) HasScripts() bool {
 	}
 	return len(s.Scripts) > 0
 }
+
+// GetLayout returns the configured tmux layout, or nil if none is configured
+func (s *ScriptConfig) GetLayout() *LayoutConfig {
+	if s == nil || s.Layout == nil || len(s.Layout.Windows) == 0 {
+		return nil
+	}
+	return s.Layout
+}
+
+// GetWindowNames returns the names of the windows in the layout, in order
+func (l *LayoutConfig) GetWindowNames() []string {
+	if l == nil {
+		return []string{}
+	}
+	names := make([]string, 0, len(l.Windows))
+	for _, w := range l.Windows {
+		if w.Name != "" {
+			names = append(names, w.Name)
+		}
+	}
+	return names
+}
+
+// GetTask returns the task section with defaults filled in (callers may pass a nil config)
+func (s *ScriptConfig) GetTask() TaskConfig {
+	task := TaskConfig{}
+	if s != nil && s.Task != nil {
+		task = *s.Task
+	}
+	if task.File == "" {
+		task.File = DefaultTaskFile
+	}
+	if task.Template == "" {
+		task.Template = DefaultTaskTemplate
+	}
+	if task.Prompt == "" {
+		task.Prompt = DefaultTaskPrompt
+	}
+	return task
+}
+
+// TaskTitle returns the first non-empty line of a task description, without markdown heading marks
+func TaskTitle(task string) string {
+	for _, line := range strings.Split(task, "\n") {
+		if line = strings.TrimSpace(strings.TrimLeft(line, "# ")); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// Render expands the task file content and the agent prompt for a task.
+// vars holds the worktree variables ({branch}, {base}, {repo}, {path}).
+func (t TaskConfig) Render(task string, vars map[string]string) (content, prompt string) {
+	all := map[string]string{
+		"task":  strings.TrimSpace(task),
+		"title": TaskTitle(task),
+		"file":  t.File,
+	}
+	for name, value := range vars {
+		all[name] = value
+	}
+	return ExpandPrompt(t.Template, all), ExpandPrompt(t.Prompt, all)
+}
@@ -0,0 +1,132 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+)
+
+// PairConflict describes how two branches overlap
+type PairConflict struct {
+	A           string
+	B           string
+	Conflicts   []string // Files that would conflict when merging A and B
+	Overlapping []string // Files changed on both branches since their merge base
+}
+
+// ConflictMatrix holds predicted conflicts between worktree branches and the base branch
+type ConflictMatrix struct {
+	BaseBranch    string
+	BaseConflicts map[string][]string // branch -> files that would conflict with the base branch
+	Pairs         []PairConflict      // Every pair of worktree branches that overlaps
+}
+
+// HasConflicts reports whether a branch is predicted to conflict with the base branch or any other worktree
+func (c *ConflictMatrix) HasConflicts(branch string) bool {
+	if c == nil {
+		return false
+	}
+	if len(c.BaseConflicts[branch]) > 0 {
+		return true
+	}
+	for _, pair := range c.Pairs {
+		if (pair.A == branch || pair.B == branch) && len(pair.Conflicts) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// PredictConflicts runs a merge of two refs in memory (`git merge-tree --write-tree`) and returns
+// the files that would conflict. Nothing in any worktree is touched. Requires git 2.38+.
+// Only committed work is considered.
+func (m *Manager) PredictConflicts(ref1, ref2 string) ([]string, error) {
+	cmd := exec.Command("git", "-C", m.repoPath, "merge-tree", "--write-tree", "--name-only", "--no-messages", ref1, ref2)
+	output, err := cmd.Output()
+	if err == nil {
+		return nil, nil // Clean merge
+	}
+
+	// Exit status 1 means the merge has conflicts; the first line is the tree, then conflicted files
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+		lines := splitLines(string(output))
+		if len(lines) > 0 {
+			lines = lines[1:]
+		}
+		return lines, nil
+	}
+
+	return nil, fmt.Errorf("failed to predict conflicts between %s and %s: %w", ref1, ref2, err)
+}
+
+// ChangedFilesSinceMergeBase returns files changed on ref since it diverged from other
+func (m *Manager) ChangedFilesSinceMergeBase(other, ref string) ([]string, error) {
+	cmd := exec.Command("git", "-C", m.repoPath, "diff", "--name-only", fmt.Sprintf("%s...%s", other, ref))
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list changed files: %w", err)
+	}
+	return splitLines(string(output)), nil
+}
+
+// ComputeConflictMatrix predicts conflicts for each branch against the base branch
+// and between every pair of branches
+func (m *Manager) ComputeConflictMatrix(branches []string, baseBranch string) (*ConflictMatrix, error) {
+	matrix := &ConflictMatrix{
+		BaseBranch:    baseBranch,
+		BaseConflicts: make(map[string][]string),
+	}
+
+	// Files each branch changed relative to base, used to find overlaps between pairs
+	changed := make(map[string]map[string]bool)
+	for _, branch := range branches {
+		if baseBranch != "" && branch != baseBranch {
+			conflicts, err := m.PredictConflicts(baseBranch, branch)
+			if err != nil {
+				return nil, err
+			}
+			if len(conflicts) > 0 {
+				matrix.BaseConflicts[branch] = conflicts
+			}
+		}
+
+		changed[branch] = make(map[string]bool)
+		if baseBranch != "" {
+			files, err := m.ChangedFilesSinceMergeBase(baseBranch, branch)
+			if err != nil {
+				return nil, err
+			}
+			for _, f := range files {
+				changed[branch][f] = true
+			}
+		}
+	}
+
+	for i := 0; i < len(branches); i++ {
+		for j := i + 1; j < len(branches); j++ {
+			a, b := branches[i], branches[j]
+			if a == baseBranch || b == baseBranch {
+				continue
+			}
+
+			var overlapping []string
+			for f := range changed[a] {
+				if changed[b][f] {
+					overlapping = append(overlapping, f)
+				}
+			}
+			if len(overlapping) == 0 {
+				continue // Disjoint changes cannot conflict
+			}
+
+			conflicts, err := m.PredictConflicts(a, b)
+			if err != nil {
+				return nil, err
+			}
+			sort.Strings(overlapping)
+			matrix.Pairs = append(matrix.Pairs, PairConflict{A: a, B: b, Conflicts: conflicts, Overlapping: overlapping})
+		}
+	}
+
+	return matrix, nil
+}
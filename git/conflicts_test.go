@@ -0,0 +1,77 @@
+package git
+
+import (
+	"strings"
+	"testing"
+)
+
+// commitIn writes a file in a worktree and commits it
+func (r *testRepo) commitIn(dir, file, content, message string) {
+	r.t.Helper()
+	r.write(dir, file, content)
+	r.gitIn(dir, "add", file)
+	r.gitIn(dir, "commit", "-q", "-m", message)
+}
+
+// TestComputeConflictMatrix tests conflict prediction against the base branch and between worktrees
+func TestComputeConflictMatrix(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.commit("shared.txt", "shared\n", "Add shared")
+	a := repo.worktree("a")
+	b := repo.worktree("b")
+	c := repo.worktree("c")
+	d := repo.worktree("d")
+	repo.commitIn(a, "shared.txt", "from a\n", "Change shared in a")
+	repo.commitIn(b, "shared.txt", "from b\n", "Change shared in b")
+	repo.commitIn(c, "shared.txt", "from a\n", "Make the same change in c")
+	repo.commitIn(d, "other.txt", "other\n", "Add other in d")
+	repo.commit("README.md", "main\n", "Change readme on main")
+	repo.commitIn(d, "README.md", "d\n", "Change readme in d")
+	m := NewManager(repo.path)
+
+	matrix, err := m.ComputeConflictMatrix([]string{"main", "a", "b", "c", "d"}, "main")
+	if err != nil {
+		t.Fatalf("ComputeConflictMatrix failed: %v", err)
+	}
+
+	if len(matrix.BaseConflicts) != 1 || strings.Join(matrix.BaseConflicts["d"], ",") != "README.md" {
+		t.Errorf("Expected only d to conflict with main in README.md, got %v", matrix.BaseConflicts)
+	}
+
+	// Only pairs that changed the same files are checked; identical changes merge cleanly
+	pairs := make(map[string]PairConflict)
+	for _, pair := range matrix.Pairs {
+		pairs[pair.A+"-"+pair.B] = pair
+	}
+	tests := []struct {
+		pair      string
+		present   bool
+		conflicts string
+	}{
+		{"a-b", true, "shared.txt"},
+		{"b-c", true, "shared.txt"},
+		{"a-c", true, ""},
+		{"a-d", false, ""},
+		{"c-d", false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pair, func(t *testing.T) {
+			pair, ok := pairs[tt.pair]
+			if ok != tt.present {
+				t.Fatalf("Expected pair present %v, got %v", tt.present, ok)
+			}
+			if ok && strings.Join(pair.Conflicts, ",") != tt.conflicts {
+				t.Errorf("Expected conflicts %q, got %v", tt.conflicts, pair.Conflicts)
+			}
+			if ok && strings.Join(pair.Overlapping, ",") != "shared.txt" {
+				t.Errorf("Expected shared.txt to overlap, got %v", pair.Overlapping)
+			}
+		})
+	}
+
+	for branch, want := range map[string]bool{"a": true, "b": true, "c": true, "d": true, "main": false} {
+		if got := matrix.HasConflicts(branch); got != want {
+			t.Errorf("Expected HasConflicts(%s) %v, got %v", branch, want, got)
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package git
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// AmConflictError is returned when `git am` stops because a patch did not apply
+type AmConflictError struct {
+	Patch     string   // Subject of the patch that failed
+	Conflicts []string // Files with conflicts (may be empty if the patch was rejected outright)
+}
+
+func (e *AmConflictError) Error() string {
+	if len(e.Conflicts) > 0 {
+		return fmt.Sprintf("patch '%s' has conflicts in: %s", e.Patch, strings.Join(e.Conflicts, ", "))
+	}
+	return fmt.Sprintf("patch '%s' does not apply", e.Patch)
+}
+
+// ExportPatches writes the worktree's commits relative to the base branch as a format-patch series
+// into outDir. Returns the list of patch files created.
+func (m *Manager) ExportPatches(worktreePath, baseBranch, outDir string) ([]string, error) {
+	if baseBranch == "" {
+		return nil, fmt.Errorf("base branch not specified")
+	}
+
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	cmd := exec.Command("git", "-C", worktreePath, "format-patch", fmt.Sprintf("%s..HEAD", baseBranch), "-o", outDir)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("failed to export patches: %s", string(output))
+	}
+
+	files := splitLines(string(output))
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no commits ahead of %s to export", baseBranch)
+	}
+	return files, nil
+}
+
+// ExportBundle writes the worktree's commits relative to the base branch into a single bundle file
+func (m *Manager) ExportBundle(worktreePath, branch, baseBranch, outFile string) error {
+	if baseBranch == "" {
+		return fmt.Errorf("base branch not specified")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outFile), 0755); err != nil {
+		return fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	cmd := exec.Command("git", "-C", worktreePath, "bundle", "create", outFile, fmt.Sprintf("%s..%s", baseBranch, branch))
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if strings.Contains(string(output), "empty bundle") {
+			return fmt.Errorf("no commits ahead of %s to export", baseBranch)
+		}
+		return fmt.Errorf("failed to create bundle: %s", string(output))
+	}
+	return nil
+}
+
+// ImportPatches applies a patch series (a .patch/.mbox file or a directory of them) or a bundle file
+// to a worktree using `git am --3way`. Returns an *AmConflictError if am stops on a conflict;
+// the am session is then left in progress for AmContinue/AmSkip/AmAbort.
+func (m *Manager) ImportPatches(worktreePath, source string) error {
+	info, err := os.Stat(source)
+	if err != nil {
+		return fmt.Errorf("cannot read %s: %w", source, err)
+	}
+
+	if m.IsAmInProgress(worktreePath) {
+		return fmt.Errorf("a patch import is already in progress in this worktree")
+	}
+
+	var cmd *exec.Cmd
+	switch {
+	case info.IsDir():
+		patches, err := filepath.Glob(filepath.Join(source, "*.patch"))
+		if err != nil || len(patches) == 0 {
+			return fmt.Errorf("no .patch files found in %s", source)
+		}
+		sort.Strings(patches) // format-patch numbers files (0001-, 0002-, ...)
+		cmd = exec.Command("git", append([]string{"-C", worktreePath, "am", "--3way"}, patches...)...)
+
+	case strings.HasSuffix(source, ".bundle"):
+		mbox, err := m.bundleToMbox(worktreePath, source)
+		if err != nil {
+			return err
+		}
+		cmd = exec.Command("git", "-C", worktreePath, "am", "--3way")
+		cmd.Stdin = strings.NewReader(mbox)
+
+	default:
+		cmd = exec.Command("git", "-C", worktreePath, "am", "--3way", source)
+	}
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if m.IsAmInProgress(worktreePath) {
+			return m.CurrentAmConflict(worktreePath)
+		}
+		return fmt.Errorf("failed to import patches: %s", string(output))
+	}
+	return nil
+}
+
+// bundlePrerequisites reads the commits a bundle was created against from its header ("-<oid> <subject>" lines)
+func bundlePrerequisites(bundlePath string) ([]string, error) {
+	file, err := os.Open(bundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+	defer file.Close()
+
+	var prerequisites []string
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadString('\n')
+		line = strings.TrimRight(line, "\n")
+		if line == "" {
+			break // The header ends with a blank line before the pack data
+		}
+		if strings.HasPrefix(line, "-") {
+			if fields := strings.Fields(line[1:]); len(fields) > 0 {
+				prerequisites = append(prerequisites, fields[0])
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	return prerequisites, nil
+}
+
+// bundleToMbox fetches the bundle's head and renders its commits that aren't in the worktree yet as an mbox.
+// The bundle's prerequisites bound the range, so commits of the base the worktree lacks aren't included.
+func (m *Manager) bundleToMbox(worktreePath, bundlePath string) (string, error) {
+	verifyCmd := exec.Command("git", "-C", worktreePath, "bundle", "verify", bundlePath)
+	if output, err := verifyCmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("invalid bundle (missing prerequisite commits?): %s", string(output))
+	}
+
+	headsCmd := exec.Command("git", "-C", worktreePath, "bundle", "list-heads", bundlePath)
+	headsOutput, err := headsCmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read bundle: %w", err)
+	}
+	heads := splitLines(string(headsOutput))
+	if len(heads) == 0 {
+		return "", fmt.Errorf("bundle has no branches")
+	}
+	fields := strings.Fields(heads[0])
+	if len(fields) < 2 {
+		return "", fmt.Errorf("failed to read bundle head")
+	}
+	headRef := fields[1]
+
+	fetchCmd := exec.Command("git", "-C", worktreePath, "fetch", bundlePath, headRef)
+	if output, err := fetchCmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to fetch from bundle: %s", string(output))
+	}
+
+	prerequisites, err := bundlePrerequisites(bundlePath)
+	if err != nil {
+		return "", err
+	}
+	args := []string{"-C", worktreePath, "format-patch", "--stdout", "^HEAD"}
+	for _, prerequisite := range prerequisites {
+		args = append(args, "^"+prerequisite)
+	}
+	patchCmd := exec.Command("git", append(args, "FETCH_HEAD")...)
+	mbox, err := patchCmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read commits from bundle: %w", err)
+	}
+	if len(strings.TrimSpace(string(mbox))) == 0 {
+		return "", fmt.Errorf("bundle contains no new commits for this worktree")
+	}
+	return string(mbox), nil
+}
+
+// IsAmInProgress reports whether a `git am` session is stopped in the worktree
+func (m *Manager) IsAmInProgress(worktreePath string) bool {
+	cmd := exec.Command("git", "-C", worktreePath, "rev-parse", "--git-path", "rebase-apply/applying")
+	output, err := cmd.Output()
+	if err != nil {
+		return false
+	}
+	path := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(worktreePath, path)
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// CurrentAmConflict describes the patch a stopped `git am` session is waiting on
+func (m *Manager) CurrentAmConflict(worktreePath string) *AmConflictError {
+	conflictErr := &AmConflictError{}
+	conflictErr.Conflicts, _ = m.GetConflictedFiles(worktreePath)
+
+	cmd := exec.Command("git", "-C", worktreePath, "am", "--show-current-patch=raw")
+	if output, err := cmd.Output(); err == nil {
+		for _, line := range strings.Split(string(output), "\n") {
+			if strings.HasPrefix(line, "Subject: ") {
+				conflictErr.Patch = strings.TrimPrefix(line, "Subject: ")
+				break
+			}
+		}
+	}
+	return conflictErr
+}
+
+// AmContinue stages the resolved conflict files and continues the am session
+func (m *Manager) AmContinue(worktreePath string) error {
+	conflicts, _ := m.GetConflictedFiles(worktreePath)
+	for _, file := range conflicts {
+		// Refuse to continue while conflict markers remain
+		content, err := os.ReadFile(filepath.Join(worktreePath, file))
+		if err == nil && strings.Contains(string(content), "<<<<<<< ") {
+			return fmt.Errorf("%s still contains conflict markers", file)
+		}
+	}
+	if len(conflicts) > 0 {
+		addCmd := exec.Command("git", append([]string{"-C", worktreePath, "add", "--"}, conflicts...)...)
+		if output, err := addCmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to stage resolved files: %s", string(output))
+		}
+	}
+
+	return m.runAm(worktreePath, "--continue")
+}
+
+// AmSkip skips the current patch and continues with the rest of the series
+func (m *Manager) AmSkip(worktreePath string) error {
+	return m.runAm(worktreePath, "--skip")
+}
+
+// AmAbort aborts the am session and restores the branch to its state before the import
+func (m *Manager) AmAbort(worktreePath string) error {
+	cmd := exec.Command("git", "-C", worktreePath, "am", "--abort")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to abort patch import: %s", string(output))
+	}
+	return nil
+}
+
+// runAm runs an am subcommand, reporting a new conflict if am stops again
+func (m *Manager) runAm(worktreePath, flag string) error {
+	cmd := exec.Command("git", "-C", worktreePath, "am", flag)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if m.IsAmInProgress(worktreePath) {
+			return m.CurrentAmConflict(worktreePath)
+		}
+		return fmt.Errorf("failed to run git am %s: %s", flag, string(output))
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package git
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// subjects returns the subjects of the commits in base..HEAD, oldest first
+func (r *testRepo) subjects(dir, base string) []string {
+	r.t.Helper()
+	output := r.gitIn(dir, "log", "--reverse", "--format=%s", base+"..HEAD")
+	if output == "" {
+		return nil
+	}
+	return strings.Split(output, "\n")
+}
+
+// TestExportImportPatches tests that an exported patch series imports into another worktree
+func TestExportImportPatches(t *testing.T) {
+	repo := newTestRepo(t)
+	feature := repo.worktree("feature")
+	repo.write(feature, "a.txt", "a\n")
+	repo.gitIn(feature, "add", "a.txt")
+	repo.gitIn(feature, "commit", "-q", "-m", "Add a")
+	repo.write(feature, "b.txt", "b\n")
+	repo.gitIn(feature, "add", "b.txt")
+	repo.gitIn(feature, "commit", "-q", "-m", "Add b")
+	m := NewManager(repo.path)
+
+	if _, err := m.ExportPatches(repo.path, "main", t.TempDir()); err == nil {
+		t.Error("Expected an error when nothing is ahead of the base")
+	}
+	outDir := filepath.Join(t.TempDir(), "patches")
+	files, err := m.ExportPatches(feature, "main", outDir)
+	if err != nil {
+		t.Fatalf("ExportPatches failed: %v", err)
+	}
+	if len(files) != 2 {
+		t.Errorf("Expected 2 patch files, got %v", files)
+	}
+
+	target := repo.worktree("target")
+	if err := m.ImportPatches(target, outDir); err != nil {
+		t.Fatalf("ImportPatches failed: %v", err)
+	}
+	if got := repo.subjects(target, "main"); strings.Join(got, ",") != "Add a,Add b" {
+		t.Errorf("Expected the two commits imported, got %v", got)
+	}
+	if repo.read(target, "b.txt") != "b\n" {
+		t.Error("Expected b.txt in the target")
+	}
+}
+
+// TestImportPatches_Bundle tests that a bundle only imports the branch's own commits,
+// even into a worktree that lacks commits of the base the bundle was made against
+func TestImportPatches_Bundle(t *testing.T) {
+	repo := newTestRepo(t)
+	older := repo.git("rev-parse", "HEAD")
+	repo.commit("base.txt", "base\n", "Advance main")
+	feature := repo.worktree("feature")
+	repo.write(feature, "a.txt", "a\n")
+	repo.gitIn(feature, "add", "a.txt")
+	repo.gitIn(feature, "commit", "-q", "-m", "Add a")
+	m := NewManager(repo.path)
+
+	bundle := filepath.Join(t.TempDir(), "feature.bundle")
+	if err := m.ExportBundle(feature, "feature", "main", bundle); err != nil {
+		t.Fatalf("ExportBundle failed: %v", err)
+	}
+	if err := m.ExportBundle(repo.path, "main", "main", filepath.Join(t.TempDir(), "empty.bundle")); err == nil {
+		t.Error("Expected an error for an empty bundle")
+	}
+
+	// The target branches off main before the commit the bundle was created against
+	target := filepath.Join(filepath.Dir(repo.path), "target")
+	repo.git("worktree", "add", "-q", "-b", "target", target, older)
+	if err := m.ImportPatches(target, bundle); err != nil {
+		t.Fatalf("ImportPatches failed: %v", err)
+	}
+	if got := repo.subjects(target, older); strings.Join(got, ",") != "Add a" {
+		t.Errorf("Expected only the bundle's own commit, got %v", got)
+	}
+}
+
+// TestImportPatches_Conflict tests that a conflicting patch leaves am stopped until it's aborted
+func TestImportPatches_Conflict(t *testing.T) {
+	repo := newTestRepo(t)
+	feature := repo.worktree("feature")
+	repo.write(feature, "README.md", "feature\n")
+	repo.gitIn(feature, "commit", "-q", "-am", "Change readme")
+	m := NewManager(repo.path)
+	outDir := filepath.Join(t.TempDir(), "patches")
+	if _, err := m.ExportPatches(feature, "main", outDir); err != nil {
+		t.Fatalf("ExportPatches failed: %v", err)
+	}
+
+	target := repo.worktree("target")
+	repo.write(target, "README.md", "target\n")
+	repo.gitIn(target, "commit", "-q", "-am", "Conflicting change")
+	head := repo.gitIn(target, "rev-parse", "HEAD")
+
+	err := m.ImportPatches(target, outDir)
+	var conflictErr *AmConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("Expected an AmConflictError, got %v", err)
+	}
+	if conflictErr.Patch != "[PATCH] Change readme" || strings.Join(conflictErr.Conflicts, ",") != "README.md" {
+		t.Errorf("Expected a conflict in README.md, got %+v", conflictErr)
+	}
+	if !m.IsAmInProgress(target) {
+		t.Fatal("Expected am to be in progress")
+	}
+	if err := m.ImportPatches(target, outDir); err == nil {
+		t.Error("Expected an error while an import is in progress")
+	}
+
+	if err := m.AmAbort(target); err != nil {
+		t.Fatalf("AmAbort failed: %v", err)
+	}
+	if m.IsAmInProgress(target) || repo.gitIn(target, "rev-parse", "HEAD") != head {
+		t.Error("Expected the abort to restore the branch")
+	}
+}
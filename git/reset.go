@@ -0,0 +1,314 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ResetMode selects how a worktree is reset
+type ResetMode int
+
+const (
+	ResetDiscardChanges ResetMode = iota // Discard staged and unstaged changes to tracked files
+	ResetHardToBase                      // Hard-reset the branch to the base branch
+	ResetCleanUntracked                  // Remove untracked files (optionally ignored files too)
+)
+
+// safetyRefPrefix is where snapshots taken before a reset are stored
+const safetyRefPrefix = "refs/jean/backups/"
+
+// MaxIgnoredSnapshotSize caps the ignored files copied into a safety snapshot: cleaning them
+// (e.g. node_modules) would otherwise copy whole dependency trees into the object database
+const MaxIgnoredSnapshotSize = 100 << 20
+
+// ResetPreview lists what a reset would affect (dry run)
+type ResetPreview struct {
+	Files           []string // Files that would be changed or removed
+	Commits         []string // Commits that would no longer be on the branch (oneline format)
+	IgnoredSize     int64    // Total size of the ignored files a clean removes
+	SnapshotIgnored bool     // Whether the ignored files fit in the safety snapshot (see MaxIgnoredSnapshotSize)
+}
+
+// PreviewReset returns the files and commits affected by a reset without changing anything
+func (m *Manager) PreviewReset(worktreePath string, mode ResetMode, baseBranch string, includeIgnored bool) (*ResetPreview, error) {
+	preview := &ResetPreview{}
+
+	switch mode {
+	case ResetDiscardChanges:
+		cmd := exec.Command("git", "-C", worktreePath, "status", "--porcelain", "--untracked-files=no")
+		output, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to check git status: %w", err)
+		}
+		preview.Files = parsePorcelainFiles(string(output))
+
+	case ResetHardToBase:
+		if baseBranch == "" {
+			return nil, fmt.Errorf("base branch not specified")
+		}
+		if err := exec.Command("git", "-C", worktreePath, "rev-parse", "--verify", baseBranch).Run(); err != nil {
+			return nil, fmt.Errorf("base branch '%s' does not exist", baseBranch)
+		}
+
+		cmd := exec.Command("git", "-C", worktreePath, "log", "--oneline", fmt.Sprintf("%s..HEAD", baseBranch))
+		output, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list commits: %w", err)
+		}
+		preview.Commits = splitLines(string(output))
+
+		// Tracked files that differ from base (committed or not)
+		cmd = exec.Command("git", "-C", worktreePath, "diff", "--name-only", baseBranch)
+		output, err = cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list changed files: %w", err)
+		}
+		preview.Files = splitLines(string(output))
+
+	case ResetCleanUntracked:
+		args := []string{"-C", worktreePath, "clean", "-n", "-d"}
+		if includeIgnored {
+			args = append(args, "-x")
+		}
+		output, err := exec.Command("git", args...).Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to preview clean: %w", err)
+		}
+		for _, line := range splitLines(string(output)) {
+			preview.Files = append(preview.Files, strings.TrimPrefix(line, "Would remove "))
+		}
+		if includeIgnored {
+			size, err := ignoredFilesSize(worktreePath)
+			if err != nil {
+				return nil, err
+			}
+			preview.IgnoredSize = size
+			preview.SnapshotIgnored = size <= MaxIgnoredSnapshotSize
+		}
+	}
+
+	return preview, nil
+}
+
+// ignoredFilesSize returns the total size of the ignored files in a worktree
+func ignoredFilesSize(worktreePath string) (int64, error) {
+	cmd := exec.Command("git", "-C", worktreePath, "ls-files", "-z", "--others", "--ignored", "--exclude-standard")
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list ignored files: %w", err)
+	}
+	var size int64
+	for _, file := range strings.Split(string(output), "\x00") {
+		if file == "" {
+			continue
+		}
+		if info, err := os.Lstat(filepath.Join(worktreePath, file)); err == nil {
+			size += info.Size()
+		}
+	}
+	return size, nil
+}
+
+// ResetWorktree performs the reset after taking a safety snapshot.
+// Returns the safety ref that UndoReset can restore from.
+func (m *Manager) ResetWorktree(worktreePath, branch string, mode ResetMode, baseBranch string, includeIgnored bool) (string, error) {
+	// Cleaning with -x deletes ignored files, so the snapshot holds them too unless they're too large
+	snapshotIgnored := false
+	if mode == ResetCleanUntracked && includeIgnored {
+		size, err := ignoredFilesSize(worktreePath)
+		if err != nil {
+			return "", err
+		}
+		snapshotIgnored = size <= MaxIgnoredSnapshotSize
+	}
+	safetyRef, err := m.CreateSafetyRef(worktreePath, branch, snapshotIgnored)
+	if err != nil {
+		return "", err
+	}
+
+	var args []string
+	switch mode {
+	case ResetDiscardChanges:
+		args = []string{"-C", worktreePath, "reset", "--hard", "HEAD"}
+	case ResetHardToBase:
+		if baseBranch == "" {
+			return safetyRef, fmt.Errorf("base branch not specified")
+		}
+		args = []string{"-C", worktreePath, "reset", "--hard", baseBranch}
+	case ResetCleanUntracked:
+		args = []string{"-C", worktreePath, "clean", "-f", "-d"}
+		if includeIgnored {
+			args = append(args, "-x")
+		}
+	default:
+		return safetyRef, fmt.Errorf("unknown reset mode")
+	}
+
+	output, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		return safetyRef, fmt.Errorf("failed to reset worktree: %s", string(output))
+	}
+
+	return safetyRef, nil
+}
+
+// CreateSafetyRef snapshots HEAD plus the full working tree (tracked and untracked, and ignored
+// files when includeIgnored is set) into a commit whose parent is HEAD, and stores it under
+// refs/jean/backups/<branch>/<unix nanos>
+func (m *Manager) CreateSafetyRef(worktreePath, branch string, includeIgnored bool) (string, error) {
+	head, err := m.GetHeadCommit(worktreePath)
+	if err != nil {
+		return "", err
+	}
+
+	// Use a temporary index so the real index is left untouched
+	tmpIndex, err := os.CreateTemp("", "jean-index-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary index: %w", err)
+	}
+	tmpIndexPath := tmpIndex.Name()
+	tmpIndex.Close()
+	os.Remove(tmpIndexPath) // git wants to create the index itself
+	defer os.Remove(tmpIndexPath)
+
+	env := append(os.Environ(), "GIT_INDEX_FILE="+tmpIndexPath)
+
+	readCmd := exec.Command("git", "-C", worktreePath, "read-tree", "HEAD")
+	readCmd.Env = env
+	if output, err := readCmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to snapshot worktree: %s", string(output))
+	}
+
+	addArgs := []string{"-C", worktreePath, "add", "-A"}
+	if includeIgnored {
+		addArgs = append(addArgs, "-f")
+	}
+	addCmd := exec.Command("git", addArgs...)
+	addCmd.Env = env
+	if output, err := addCmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to snapshot worktree: %s", string(output))
+	}
+
+	treeCmd := exec.Command("git", "-C", worktreePath, "write-tree")
+	treeCmd.Env = env
+	treeOutput, err := treeCmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to snapshot worktree: %w", err)
+	}
+	tree := strings.TrimSpace(string(treeOutput))
+
+	commitCmd := exec.Command("git", "-C", worktreePath, "commit-tree", tree, "-p", head, "-m", fmt.Sprintf("jean: safety snapshot of %s before reset", branch))
+	commitOutput, err := commitCmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("failed to snapshot worktree: %s", string(commitOutput))
+	}
+	snapshot := strings.TrimSpace(string(commitOutput))
+
+	ref := fmt.Sprintf("%s%s/%d", safetyRefPrefix, branch, time.Now().UnixNano())
+	if output, err := exec.Command("git", "-C", worktreePath, "update-ref", ref, snapshot).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to create safety ref: %s", string(output))
+	}
+
+	return ref, nil
+}
+
+// ListSafetyRefs returns the safety refs for a branch, newest first.
+// Snapshots of nested branches (e.g. feature/x for feature) share the prefix and are left out.
+func (m *Manager) ListSafetyRefs(branch string) ([]string, error) {
+	prefix := safetyRefPrefix + branch + "/"
+	cmd := exec.Command("git", "-C", m.repoPath, "for-each-ref", "--format=%(refname)", prefix)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list safety refs: %w", err)
+	}
+
+	var refs []string
+	for _, ref := range splitLines(string(output)) {
+		if isTimestamp(strings.TrimPrefix(ref, prefix)) {
+			refs = append(refs, ref)
+		}
+	}
+	// Ref names end in a unix nanosecond timestamp, so reverse lexical order is newest first
+	sort.Sort(sort.Reverse(sort.StringSlice(refs)))
+	return refs, nil
+}
+
+// UndoReset restores a worktree to the state captured by a safety ref:
+// the branch is moved back to the original HEAD and the working tree is restored
+// (changes come back unstaged). The safety ref is deleted afterwards.
+func (m *Manager) UndoReset(worktreePath, safetyRef string) error {
+	steps := [][]string{
+		{"-C", worktreePath, "reset", "--hard", safetyRef + "^"},
+		{"-C", worktreePath, "read-tree", "--reset", "-u", safetyRef},
+		{"-C", worktreePath, "reset", "-q"},
+	}
+	for _, args := range steps {
+		if output, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to undo reset: %s", string(output))
+		}
+	}
+
+	_ = exec.Command("git", "-C", worktreePath, "update-ref", "-d", safetyRef).Run()
+	return nil
+}
+
+// DeleteSafetyRefs removes all safety refs for a branch (used when its worktree is deleted)
+func (m *Manager) DeleteSafetyRefs(branch string) error {
+	refs, err := m.ListSafetyRefs(branch)
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		if output, err := exec.Command("git", "-C", m.repoPath, "update-ref", "-d", ref).CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to delete safety ref: %s", string(output))
+		}
+	}
+	return nil
+}
+
+// isTimestamp reports whether a ref name component is a unix nanosecond timestamp
+func isTimestamp(component string) bool {
+	if component == "" {
+		return false
+	}
+	for _, r := range component {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// SafetyRefTime extracts the snapshot time from a safety ref name
+func SafetyRefTime(ref string) time.Time {
+	var nanos int64
+	fmt.Sscanf(filepath.Base(ref), "%d", &nanos)
+	return time.Unix(0, nanos)
+}
+
+// parsePorcelainFiles extracts file paths from `git status --porcelain` output
+func parsePorcelainFiles(output string) []string {
+	var files []string
+	for _, line := range strings.Split(output, "\n") {
+		if len(line) > 3 {
+			files = append(files, line[3:])
+		}
+	}
+	return files
+}
+
+// splitLines splits output into non-empty trimmed lines
+func splitLines(output string) []string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
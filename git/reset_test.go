@@ -0,0 +1,207 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testRepo is a scratch repository with helpers to run git and write files in it
+type testRepo struct {
+	t    *testing.T
+	path string
+}
+
+// newTestRepo creates a repository on main with one commit of README.md
+func newTestRepo(t *testing.T) *testRepo {
+	t.Helper()
+	r := &testRepo{t: t, path: filepath.Join(t.TempDir(), "repo")}
+	if err := os.MkdirAll(r.path, 0755); err != nil {
+		t.Fatal(err)
+	}
+	r.git("init", "-q", "-b", "main")
+	r.git("config", "user.email", "test@example.com")
+	r.git("config", "user.name", "Test")
+	r.git("config", "commit.gpgsign", "false")
+	r.commit("README.md", "hello\n", "Initial commit")
+	return r
+}
+
+// git runs git in the repository and returns its trimmed output, failing the test on errors
+func (r *testRepo) git(args ...string) string {
+	return r.gitIn(r.path, args...)
+}
+
+// gitIn runs git in a worktree of the repository
+func (r *testRepo) gitIn(dir string, args ...string) string {
+	r.t.Helper()
+	output, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %s: %s", strings.Join(args, " "), output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// write writes a file relative to dir, creating its directories
+func (r *testRepo) write(dir, file, content string) {
+	r.t.Helper()
+	path := filepath.Join(dir, file)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		r.t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+// read returns a file's content relative to dir ("" if it doesn't exist)
+func (r *testRepo) read(dir, file string) string {
+	data, _ := os.ReadFile(filepath.Join(dir, file))
+	return string(data)
+}
+
+// commit writes a file in the main worktree and commits it
+func (r *testRepo) commit(file, content, message string) {
+	r.t.Helper()
+	r.write(r.path, file, content)
+	r.git("add", file)
+	r.git("commit", "-q", "-m", message)
+}
+
+// worktree adds a worktree with a new branch off main
+func (r *testRepo) worktree(branch string) string {
+	r.t.Helper()
+	path := filepath.Join(filepath.Dir(r.path), strings.ReplaceAll(branch, "/", "-"))
+	r.git("worktree", "add", "-q", "-b", branch, path, "main")
+	return path
+}
+
+// TestListSafetyRefs tests that only the branch's own snapshots are listed, newest first
+func TestListSafetyRefs(t *testing.T) {
+	repo := newTestRepo(t)
+	head := repo.git("rev-parse", "HEAD")
+	for _, ref := range []string{
+		"feature/1700000000000000001",
+		"feature/1700000000000000003",
+		"feature/x/1700000000000000009", // Snapshot of the nested branch feature/x
+		"feature-2/1700000000000000005",
+		"feature/not-a-time",
+	} {
+		repo.git("update-ref", safetyRefPrefix+ref, head)
+	}
+	m := NewManager(repo.path)
+
+	tests := []struct {
+		branch string
+		want   []string
+	}{
+		{"feature", []string{safetyRefPrefix + "feature/1700000000000000003", safetyRefPrefix + "feature/1700000000000000001"}},
+		{"feature/x", []string{safetyRefPrefix + "feature/x/1700000000000000009"}},
+		{"feature-2", []string{safetyRefPrefix + "feature-2/1700000000000000005"}},
+		{"missing", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.branch, func(t *testing.T) {
+			refs, err := m.ListSafetyRefs(tt.branch)
+			if err != nil {
+				t.Fatalf("ListSafetyRefs failed: %v", err)
+			}
+			if strings.Join(refs, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Expected %v, got %v", tt.want, refs)
+			}
+		})
+	}
+
+	// Deleting the branch's snapshots leaves the nested branch's alone
+	if err := m.DeleteSafetyRefs("feature"); err != nil {
+		t.Fatalf("DeleteSafetyRefs failed: %v", err)
+	}
+	if refs, _ := m.ListSafetyRefs("feature/x"); len(refs) != 1 {
+		t.Errorf("Expected the feature/x snapshot to be kept, got %v", refs)
+	}
+	if refs, _ := m.ListSafetyRefs("feature"); len(refs) != 0 {
+		t.Errorf("Expected no feature snapshots, got %v", refs)
+	}
+}
+
+// TestResetWorktree_Undo tests that undo restores what each reset mode removed
+func TestResetWorktree_Undo(t *testing.T) {
+	tests := []struct {
+		name           string
+		mode           ResetMode
+		includeIgnored bool
+	}{
+		{"discard changes", ResetDiscardChanges, false},
+		{"hard reset to base", ResetHardToBase, false},
+		{"clean untracked", ResetCleanUntracked, false},
+		{"clean ignored", ResetCleanUntracked, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newTestRepo(t)
+			repo.commit(".gitignore", ".env\n", "Ignore .env")
+			wt := repo.worktree("feature")
+			repo.write(wt, "work.txt", "committed\n")
+			repo.gitIn(wt, "add", "work.txt")
+			repo.gitIn(wt, "commit", "-q", "-m", "Work")
+			head := repo.gitIn(wt, "rev-parse", "HEAD")
+			repo.write(wt, "README.md", "changed\n")
+			repo.write(wt, "notes.txt", "untracked\n")
+			repo.write(wt, ".env", "SECRET=1\n")
+
+			m := NewManager(repo.path)
+			ref, err := m.ResetWorktree(wt, "feature", tt.mode, "main", tt.includeIgnored)
+			if err != nil {
+				t.Fatalf("ResetWorktree failed: %v", err)
+			}
+			if err := m.UndoReset(wt, ref); err != nil {
+				t.Fatalf("UndoReset failed: %v", err)
+			}
+
+			if got := repo.gitIn(wt, "rev-parse", "HEAD"); got != head {
+				t.Errorf("Expected HEAD %s, got %s", head, got)
+			}
+			for file, want := range map[string]string{"README.md": "changed\n", "notes.txt": "untracked\n", ".env": "SECRET=1\n"} {
+				if got := repo.read(wt, file); got != want {
+					t.Errorf("Expected %s to be %q, got %q", file, want, got)
+				}
+			}
+			if staged := repo.gitIn(wt, "diff", "--cached", "--name-only"); staged != "" {
+				t.Errorf("Expected nothing staged after undo, got %s", staged)
+			}
+		})
+	}
+}
+
+// TestPreviewReset_IgnoredSize tests that ignored files over the snapshot limit are reported
+func TestPreviewReset_IgnoredSize(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.commit(".gitignore", "deps/\n", "Ignore deps")
+	repo.write(repo.path, "deps/small.js", "x")
+	m := NewManager(repo.path)
+
+	preview, err := m.PreviewReset(repo.path, ResetCleanUntracked, "", true)
+	if err != nil {
+		t.Fatalf("PreviewReset failed: %v", err)
+	}
+	if preview.IgnoredSize != 1 || !preview.SnapshotIgnored {
+		t.Errorf("Expected 1 byte of ignored files in the snapshot, got %d (%v)", preview.IgnoredSize, preview.SnapshotIgnored)
+	}
+
+	large, err := os.Create(filepath.Join(repo.path, "deps", "large.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	large.Truncate(MaxIgnoredSnapshotSize) // Sparse, so nothing is written
+	large.Close()
+	preview, err = m.PreviewReset(repo.path, ResetCleanUntracked, "", true)
+	if err != nil {
+		t.Fatalf("PreviewReset failed: %v", err)
+	}
+	if preview.SnapshotIgnored {
+		t.Errorf("Expected %d bytes of ignored files to be left out of the snapshot", preview.IgnoredSize)
+	}
+}
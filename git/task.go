@@ -0,0 +1,79 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// WriteTaskFile writes a task brief into a worktree (file is relative to the worktree and may
+// not leave it). Unless track is set, the file is added to the repository's info/exclude so it
+// doesn't show up as an untracked change or get committed.
+func (m *Manager) WriteTaskFile(worktreePath, file, content string, track bool) error {
+	if !filepath.IsLocal(file) {
+		return fmt.Errorf("task file %s must be a relative path inside the worktree", file)
+	}
+	path := filepath.Join(worktreePath, file)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create task directory: %w", err)
+	}
+	// A symlinked directory could still point outside the worktree
+	root, err := filepath.EvalSymlinks(worktreePath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve worktree: %w", err)
+	}
+	dir, err := filepath.EvalSymlinks(filepath.Dir(path))
+	if err != nil {
+		return fmt.Errorf("failed to resolve task directory: %w", err)
+	}
+	if rel, err := filepath.Rel(root, dir); err != nil || !filepath.IsLocal(rel) {
+		return fmt.Errorf("task file %s must be a relative path inside the worktree", file)
+	}
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		return fmt.Errorf("task file %s is a symlink", file)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write task file: %w", err)
+	}
+	if track {
+		return nil
+	}
+	return excludeFromGit(worktreePath, "/"+filepath.ToSlash(file))
+}
+
+// excludeFromGit adds a pattern to info/exclude, which all worktrees of a repository share
+func excludeFromGit(worktreePath, pattern string) error {
+	output, err := exec.Command("git", "-C", worktreePath, "rev-parse", "--git-common-dir").Output()
+	if err != nil {
+		return fmt.Errorf("failed to find git directory: %w", err)
+	}
+	gitDir := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(worktreePath, gitDir)
+	}
+	excludePath := filepath.Join(gitDir, "info", "exclude")
+
+	data, err := os.ReadFile(excludePath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read info/exclude: %w", err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == pattern {
+			return nil
+		}
+	}
+	if len(data) > 0 && !strings.HasSuffix(string(data), "\n") {
+		data = append(data, '\n')
+	}
+	data = append(data, pattern+"\n"...)
+
+	if err := os.MkdirAll(filepath.Dir(excludePath), 0755); err != nil {
+		return fmt.Errorf("failed to update info/exclude: %w", err)
+	}
+	if err := os.WriteFile(excludePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to update info/exclude: %w", err)
+	}
+	return nil
+}
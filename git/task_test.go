@@ -0,0 +1,53 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestWriteTaskFile tests that task files are written inside the worktree and excluded from git
+func TestWriteTaskFile(t *testing.T) {
+	repo := newTestRepo(t)
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(repo.path, "linked")); err != nil {
+		t.Fatal(err)
+	}
+	m := NewManager(repo.path)
+
+	tests := []struct {
+		name    string
+		file    string
+		wantErr bool
+	}{
+		{"top level", "TASK.md", false},
+		{"nested", ".jean/task.md", false},
+		{"absolute", filepath.Join(outside, "task.md"), true},
+		{"parent", "../task.md", true},
+		{"escaping after a directory", "docs/../../task.md", true},
+		{"empty", "", true},
+		{"through a symlinked directory", "linked/task.md", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := m.WriteTaskFile(repo.path, tt.file, "Do the task\n", false)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := repo.read(repo.path, tt.file); got != "Do the task\n" {
+				t.Errorf("Expected the task in %s, got %q", tt.file, got)
+			}
+		})
+	}
+
+	if entries, _ := os.ReadDir(outside); len(entries) > 0 || repo.read(filepath.Dir(repo.path), "task.md") != "" {
+		t.Error("Expected nothing written outside the worktree")
+	}
+	if status := repo.git("status", "--porcelain"); strings.Contains(status, "TASK.md") || strings.Contains(status, ".jean") {
+		t.Errorf("Expected the task files to be excluded, got %s", status)
+	}
+}
@@ -0,0 +1,273 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// TransplantOptions controls which changes are moved between worktrees
+type TransplantOptions struct {
+	IncludeStaged    bool // Move staged changes too (otherwise they stay in the source worktree)
+	IncludeUntracked bool // Copy untracked (non-ignored) files as well
+	KeepSource       bool // Copy instead of move: leave the changes in the source worktree
+}
+
+// TransplantResult describes the outcome of moving changes between worktrees
+type TransplantResult struct {
+	Conflicts   []string // Files that did not apply cleanly in the target worktree
+	Collisions  []string // Untracked files that already exist in the target (nothing was moved)
+	SourceKept  bool     // True when the source changes were left in place (e.g. because of conflicts)
+	BackupStash string   // Stash commit in the source worktree holding the moved changes (for recovery)
+}
+
+// CreatePatch returns a binary patch of the uncommitted changes in a worktree.
+// With includeStaged the patch is taken against HEAD (staged + unstaged),
+// otherwise only unstaged changes (working tree vs index) are included.
+func (m *Manager) CreatePatch(worktreePath string, includeStaged bool) (string, error) {
+	args := []string{"-C", worktreePath, "diff", "--binary"}
+	if includeStaged {
+		args = append(args, "HEAD")
+	}
+
+	cmd := exec.Command("git", args...)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to create patch: %w", err)
+	}
+	return string(output), nil
+}
+
+// ApplyPatch applies a patch to a worktree's files, with a 3-way merge as fallback.
+// Changes stay unstaged, as they were in the source worktree, unless the 3-way merge conflicts.
+// Returns the list of conflicted files if the patch applied with conflicts,
+// or an error if it could not be applied at all (the worktree is left untouched).
+func (m *Manager) ApplyPatch(worktreePath, patch string) ([]string, error) {
+	if strings.TrimSpace(patch) == "" {
+		return nil, nil
+	}
+
+	cmd := exec.Command("git", "-C", worktreePath, "apply", "--binary", "--whitespace=nowarn")
+	cmd.Stdin = strings.NewReader(patch)
+	if err := cmd.Run(); err == nil {
+		return nil, nil
+	}
+
+	// The 3-way merge works through the index, so it stages what it applies: keep a copy of the
+	// index to put back afterwards, which also keeps what the target had staged itself
+	indexPath, savedIndex, err := readIndex(worktreePath)
+	if err != nil {
+		return nil, err
+	}
+	cmd = exec.Command("git", "-C", worktreePath, "apply", "--3way", "--binary", "--whitespace=nowarn")
+	cmd.Stdin = strings.NewReader(patch)
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		if err := restoreIndex(worktreePath, indexPath, savedIndex); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	// A 3-way apply with conflicts leaves conflict markers and unmerged index entries
+	conflicts, conflictErr := m.GetConflictedFiles(worktreePath)
+	if conflictErr == nil && len(conflicts) > 0 {
+		return conflicts, nil
+	}
+
+	return nil, fmt.Errorf("failed to apply patch: %s", strings.TrimSpace(string(output)))
+}
+
+// readIndex returns the path and content of a worktree's index (nil content if there is none yet)
+func readIndex(worktreePath string) (string, []byte, error) {
+	output, err := exec.Command("git", "-C", worktreePath, "rev-parse", "--git-path", "index").Output()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to locate index: %w", err)
+	}
+	indexPath := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(indexPath) {
+		indexPath = filepath.Join(worktreePath, indexPath)
+	}
+	data, err := os.ReadFile(indexPath)
+	if err != nil && !os.IsNotExist(err) {
+		return "", nil, fmt.Errorf("failed to read index: %w", err)
+	}
+	return indexPath, data, nil
+}
+
+// restoreIndex puts back an index saved by readIndex; without one, nothing was staged before
+func restoreIndex(worktreePath, indexPath string, data []byte) error {
+	if data == nil {
+		if output, err := exec.Command("git", "-C", worktreePath, "reset", "-q").CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to unstage applied changes: %s", strings.TrimSpace(string(output)))
+		}
+		return nil
+	}
+	tmpPath := indexPath + ".jean"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to restore index: %w", err)
+	}
+	if err := os.Rename(tmpPath, indexPath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to restore index: %w", err)
+	}
+	return nil
+}
+
+// GetConflictedFiles returns the files with unresolved conflicts in a worktree
+func (m *Manager) GetConflictedFiles(worktreePath string) ([]string, error) {
+	cmd := exec.Command("git", "-C", worktreePath, "diff", "--name-only", "--diff-filter=U")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conflicted files: %w", err)
+	}
+
+	var files []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// ListUntrackedFiles returns untracked, non-ignored files relative to the worktree root
+func (m *Manager) ListUntrackedFiles(worktreePath string) ([]string, error) {
+	cmd := exec.Command("git", "-C", worktreePath, "ls-files", "--others", "--exclude-standard", "-z")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list untracked files: %w", err)
+	}
+
+	var files []string
+	for _, f := range bytes.Split(output, []byte{0}) {
+		if len(f) > 0 {
+			files = append(files, string(f))
+		}
+	}
+	return files, nil
+}
+
+// TransplantChanges moves uncommitted changes from one worktree to another.
+// The changes are exported as a patch and applied unstaged in the target with a 3-way fallback.
+// Only when everything applies cleanly are the changes removed from the source, and even
+// then they are kept in a stash entry (see TransplantResult.BackupStash) so nothing is lost.
+// If conflicts occur, the source is left untouched and the conflicted files are reported.
+// Untracked files that would overwrite files in the target are checked first: then nothing is moved.
+func (m *Manager) TransplantChanges(sourcePath, targetPath string, opts TransplantOptions) (*TransplantResult, error) {
+	if sourcePath == targetPath {
+		return nil, fmt.Errorf("source and target worktree are the same")
+	}
+
+	patch, err := m.CreatePatch(sourcePath, opts.IncludeStaged)
+	if err != nil {
+		return nil, err
+	}
+
+	var untracked []string
+	if opts.IncludeUntracked {
+		untracked, err = m.ListUntrackedFiles(sourcePath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if strings.TrimSpace(patch) == "" && len(untracked) == 0 {
+		return nil, fmt.Errorf("no changes to move")
+	}
+
+	result := &TransplantResult{}
+
+	// Untracked files never overwrite anything in the target
+	for _, file := range untracked {
+		if _, statErr := os.Lstat(filepath.Join(targetPath, file)); statErr == nil {
+			result.Collisions = append(result.Collisions, file)
+		}
+	}
+	if len(result.Collisions) > 0 {
+		result.SourceKept = true
+		return result, nil
+	}
+
+	conflicts, err := m.ApplyPatch(targetPath, patch)
+	if err != nil {
+		return nil, err
+	}
+	result.Conflicts = append(result.Conflicts, conflicts...)
+
+	for _, file := range untracked {
+		if copyErr := copyFile(filepath.Join(sourcePath, file), filepath.Join(targetPath, file)); copyErr != nil {
+			return result, fmt.Errorf("failed to copy untracked file %s: %w", file, copyErr)
+		}
+	}
+
+	if len(result.Conflicts) > 0 {
+		result.SourceKept = true
+		return result, nil
+	}
+
+	if opts.KeepSource {
+		result.SourceKept = true
+		return result, nil
+	}
+
+	// Everything applied cleanly - remove the changes from the source via stash (keeps a backup)
+	stashArgs := []string{"-C", sourcePath, "stash", "push", "-m", fmt.Sprintf("jean: moved to %s", targetPath)}
+	if !opts.IncludeStaged {
+		stashArgs = append(stashArgs, "--keep-index")
+	}
+	if opts.IncludeUntracked {
+		stashArgs = append(stashArgs, "--include-untracked")
+	}
+	stashCmd := exec.Command("git", stashArgs...)
+	if output, err := stashCmd.CombinedOutput(); err != nil {
+		result.SourceKept = true
+		return result, fmt.Errorf("changes applied to target but failed to clean source: %s", string(output))
+	}
+
+	revCmd := exec.Command("git", "-C", sourcePath, "rev-parse", "--short", "stash@{0}")
+	if output, err := revCmd.Output(); err == nil {
+		result.BackupStash = strings.TrimSpace(string(output))
+	}
+
+	return result, nil
+}
+
+// copyFile copies a regular file or symlink, creating parent directories as needed
+func copyFile(src, dst string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(target, dst)
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, in)
+	return err
+}
@@ -0,0 +1,151 @@
+package git
+
+import (
+	"strings"
+	"testing"
+)
+
+// numbered returns lines "1".."n", with line i replaced by text when i > 0
+func numbered(n, i int, text string) string {
+	var b strings.Builder
+	for line := 1; line <= n; line++ {
+		if line == i {
+			b.WriteString(text)
+		} else {
+			b.WriteString(strings.Repeat("x", line))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// TestTransplantChanges tests moving uncommitted changes between worktrees
+func TestTransplantChanges(t *testing.T) {
+	tests := []struct {
+		name string
+		// setup prepares the target worktree after the fixture is created
+		setup          func(repo *testRepo, target string)
+		wantConflicts  []string
+		wantCollisions []string
+	}{
+		{
+			name:  "plain apply",
+			setup: func(repo *testRepo, target string) {},
+		},
+		{
+			name: "3-way fallback keeps the target's staged changes",
+			setup: func(repo *testRepo, target string) {
+				// Changes next to the moved hunk break the plain apply's context
+				repo.write(target, "code.txt", numbered(10, 5, "target"))
+				repo.gitIn(target, "commit", "-qam", "Target change")
+				repo.write(target, "staged.txt", "staged\n")
+				repo.gitIn(target, "add", "staged.txt")
+			},
+		},
+		{
+			name: "conflict",
+			setup: func(repo *testRepo, target string) {
+				repo.write(target, "code.txt", numbered(10, 8, "other"))
+				repo.gitIn(target, "commit", "-qam", "Conflicting change")
+			},
+			wantConflicts: []string{"code.txt"},
+		},
+		{
+			name: "untracked collision",
+			setup: func(repo *testRepo, target string) {
+				repo.write(target, "notes.txt", "target notes\n")
+			},
+			wantCollisions: []string{"notes.txt"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newTestRepo(t)
+			repo.commit("code.txt", numbered(10, 0, ""), "Add code")
+			source := repo.worktree("source")
+			target := repo.worktree("target")
+			tt.setup(repo, target)
+			repo.write(source, "code.txt", numbered(10, 8, "moved"))
+			repo.write(source, "notes.txt", "notes\n")
+			targetBefore := repo.read(target, "code.txt")
+
+			m := NewManager(repo.path)
+			result, err := m.TransplantChanges(source, target, TransplantOptions{IncludeUntracked: true})
+			if err != nil {
+				t.Fatalf("TransplantChanges failed: %v", err)
+			}
+			if strings.Join(result.Conflicts, ",") != strings.Join(tt.wantConflicts, ",") {
+				t.Errorf("Expected conflicts %v, got %v", tt.wantConflicts, result.Conflicts)
+			}
+			if strings.Join(result.Collisions, ",") != strings.Join(tt.wantCollisions, ",") {
+				t.Errorf("Expected collisions %v, got %v", tt.wantCollisions, result.Collisions)
+			}
+
+			switch {
+			case len(tt.wantCollisions) > 0:
+				// Nothing may have been applied anywhere
+				if got := repo.read(target, "code.txt"); got != targetBefore {
+					t.Errorf("Expected the target to be unchanged, got %q", got)
+				}
+				if !result.SourceKept || !strings.Contains(repo.read(source, "code.txt"), "moved") {
+					t.Error("Expected the source changes to be kept")
+				}
+			case len(tt.wantConflicts) > 0:
+				if !result.SourceKept || !strings.Contains(repo.read(source, "code.txt"), "moved") {
+					t.Error("Expected the source changes to be kept")
+				}
+			default:
+				if !strings.Contains(repo.read(target, "code.txt"), "moved") || repo.read(target, "notes.txt") != "notes\n" {
+					t.Error("Expected the changes in the target")
+				}
+				if staged := repo.gitIn(target, "diff", "--cached", "--name-only"); strings.Contains(staged, "code.txt") {
+					t.Errorf("Expected the moved changes unstaged, got staged %s", staged)
+				}
+				if status := repo.gitIn(source, "status", "--porcelain"); status != "" {
+					t.Errorf("Expected a clean source, got %s", status)
+				}
+				if result.BackupStash == "" {
+					t.Error("Expected a backup stash")
+				}
+			}
+			if strings.Contains(tt.name, "staged") {
+				if staged := repo.gitIn(target, "diff", "--cached", "--name-only"); staged != "staged.txt" {
+					t.Errorf("Expected staged.txt to stay staged, got %q", staged)
+				}
+			}
+		})
+	}
+}
+
+// TestApplyPatch_Renames tests that renamed and new files of a 3-way apply end up unstaged
+func TestApplyPatch_Renames(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.commit("code.txt", numbered(10, 0, ""), "Add code")
+	repo.commit("old name.txt", "content of the renamed file\n", "Add file")
+	source := repo.worktree("source")
+	target := repo.worktree("target")
+	repo.write(target, "code.txt", numbered(10, 5, "target"))
+	repo.gitIn(target, "commit", "-qam", "Target change")
+
+	repo.write(source, "code.txt", numbered(10, 8, "moved"))
+	repo.gitIn(source, "mv", "old name.txt", "new.txt")
+	repo.write(source, "added.txt", "added\n")
+	repo.gitIn(source, "add", "added.txt")
+
+	m := NewManager(repo.path)
+	patch, err := m.CreatePatch(source, true)
+	if err != nil {
+		t.Fatalf("CreatePatch failed: %v", err)
+	}
+	conflicts, err := m.ApplyPatch(target, patch)
+	if err != nil || len(conflicts) > 0 {
+		t.Fatalf("ApplyPatch failed: %v %v", err, conflicts)
+	}
+	if staged := repo.gitIn(target, "diff", "--cached", "--name-only"); staged != "" {
+		t.Errorf("Expected nothing staged, got %s", staged)
+	}
+	if repo.read(target, "new.txt") == "" || repo.read(target, "old name.txt") != "" || repo.read(target, "added.txt") != "added\n" {
+		t.Error("Expected the rename and the new file in the target")
+	}
+}
@@ -32,7 +32,8 @@ type Worktree struct {
 
 // Manager handles Git worktree operations
 type Manager struct {
-	repoPath string
+	repoPath  string
+	scriptEnv func(branch string) []string // Extra environment for the setup script (e.g. allocated ports)
 }
 
 // NewManager creates a new worktree manager
@@ -40,6 +41,11 @@ func NewManager(repoPath string) *Manager {
 	return &Manager{repoPath: repoPath}
 }
 
+// SetScriptEnv sets a function providing extra environment variables for a branch's setup script
+func (m *Manager) SetScriptEnv(fn func(branch string) []string) {
+	m.scriptEnv = fn
+}
+
 // List returns all worktrees in the repository with status relative to the base branch
 func (m *Manager) List(baseBranch string) ([]Worktree, error) {
 	cmd := exec.Command("git", "-C", m.repoPath, "worktree", "list", "--porcelain")
@@ -344,6 +350,12 @@ func (m *Manager) executeSetupScript(workspacePath string) error {
 		fmt.Sprintf("%s=%s", branding.GetEnvVar("WORKSPACE_PATH"), workspacePath),
 		fmt.Sprintf("%s=%s", branding.GetEnvVar("ROOT_PATH"), repoRoot),
 	)
+	if branch, err := m.GetCurrentBranchForWorktree(workspacePath); err == nil {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", branding.GetEnvVar("BRANCH"), branch))
+		if m.scriptEnv != nil {
+			cmd.Env = append(cmd.Env, m.scriptEnv(branch)...)
+		}
+	}
 
 	// Capture both stdout and stderr for error reporting
 	output, err := cmd.CombinedOutput()
@@ -1274,6 +1286,16 @@ func (m *Manager) GetStatus(worktreePath string) (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
+// GetHeadCommit returns the full commit hash of HEAD in a worktree
+func (m *Manager) GetHeadCommit(worktreePath string) (string, error) {
+	cmd := exec.Command("git", "-C", worktreePath, "rev-parse", "HEAD")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
 // GetCurrentBranchForWorktree returns the current branch name for a specific worktree
 func (m *Manager) GetCurrentBranchForWorktree(worktreePath string) (string, error) {
 	cmd := exec.Command("git", "-C", worktreePath, "branch", "--show-current")
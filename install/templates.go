@@ -7,13 +7,17 @@ import (
 	"github.com/andrew-bierman/jean-tui/internal/branding"
 )
 
+// WrapperVersion is the version of the switch file format the wrappers read. The wrappers pass it
+// in <PREFIX>_WRAPPER_VERSION so a wrapper still loaded from before an update can be recognized.
+// Version 2 added the backend and attach-command fields after the original seven.
+const WrapperVersion = 2
+
 // GetBashZshWrapper returns the wrapper function for bash and zsh shells
 // with the correct branding applied.
 func GetBashZshWrapper() string {
 	cliName := branding.CLIName
 	configDir := branding.ConfigDirName
 	envVarPrefix := branding.EnvVarPrefix
-	sessionPrefix := branding.SessionPrefix
 	startMarker := branding.GetShellWrapperMarkerStart()
 	endMarker := branding.GetShellWrapperMarkerEnd()
 
@@ -44,7 +48,7 @@ func GetBashZshWrapper() string {
         local temp_file=$(mktemp)
 
         # Set environment variable so %s knows to write to file
-        %s_SWITCH_FILE="$temp_file" command %s "$@"
+        %s_SWITCH_FILE="$temp_file" %s_WRAPPER_VERSION=%d command %s "$@"
         local exit_code=$?
 
         # Restore PATH if it got corrupted
@@ -57,7 +61,7 @@ func GetBashZshWrapper() string {
         if [ "$debug_enabled" = "true" ]; then
         echo "DEBUG wrapper: switch file exists and has content" >> "$debug_log"
         fi
-        # Read the switch info: path|branch|auto-claude|target-window|script-command|claude-session-name|is-claude-initialized
+        # Read the switch info: path|branch|auto-claude|target-window|script-command|claude-session-name|is-claude-initialized|backend|attach-command
         local switch_info=$(cat "$temp_file")
         if [ "$debug_enabled" = "true" ]; then
         echo "DEBUG wrapper: switch_info=$switch_info" >> "$debug_log"
@@ -68,103 +72,29 @@ func GetBashZshWrapper() string {
         fi
 
         # Parse the info (using worktree_path instead of path to avoid PATH conflict)
-        IFS='|' read -r worktree_path branch auto_claude target_window script_command claude_session_name is_claude_initialized <<< "$switch_info"
+        # (attach_command is last so it keeps any '|' it contains)
+        IFS='|' read -r worktree_path branch auto_claude target_window script_command claude_session_name is_claude_initialized backend attach_command <<< "$switch_info"
 
         # Check if we got valid data (has at least two pipes)
         if [[ "$switch_info" == *"|"*"|"* ]]; then
-            # Check if tmux is available
-            if ! command -v tmux >/dev/null 2>&1; then
-                # No tmux, just cd
-                cd "$worktree_path" || return
-                echo "Switched to worktree: $branch (no tmux)"
-                return
-            fi
-
-            # Use the pre-sanitized session name from %s (includes repo basename)
-            # Format: %s<repo>-<branch>
-            local session_name="$claude_session_name"
+            cd "$worktree_path" || return
 
-            # If session name is empty (older version or fallback), generate it from branch
-            if [ -z "$session_name" ]; then
-                session_name="%s${branch//[^a-zA-Z0-9\-_]/-}"
-                session_name="${session_name//--/-}"
-                session_name="${session_name#-}"
-                session_name="${session_name%%-}"
-            fi
-
-            # Check if already in a tmux session and if it's the same session we want
-            if [ -n "$TMUX" ]; then
-                # Get current tmux session name
-                local current_session=$(tmux display-message -p '#S')
-                if [ "$current_session" = "$session_name" ]; then
-                    # Already in the correct session, just cd
-                    cd "$worktree_path" || return
-                    echo "Switched to worktree: $branch"
-                    return
-                fi
-                # Different session - fall through to switch to it
+            # %s has prepared the session (agents, jean.json layout, ports) and hands over
+            # the command that enters it; without one (no multiplexer) just switch directories
+            if [ -z "$attach_command" ]; then
+                echo "Switched to worktree: $branch"
+                return
             fi
-
-            # Set window index based on target window
-            # Note: with base-index 1, windows are 1, 2, 3... instead of 0, 1, 2...
-            local window_index="1"
-            if [ "$target_window" = "claude" ]; then
-                window_index="2"
+            if [ "$debug_enabled" = "true" ]; then
+                echo "DEBUG wrapper: running attach command: $attach_command" >> "$debug_log"
             fi
+            sh -c "$attach_command"
 
-            # Check if session exists
-            if tmux has-session -t "=$session_name" 2>/dev/null; then
-                # Session exists - check if target window exists
-                if ! tmux list-windows -t "$session_name" -F "#{window_index}:#{window_name}" | grep -q "^${window_index}:"; then
-                    # Target window doesn't exist, create it
-                    if [ "$target_window" = "claude" ]; then
-                        # Create claude window with claude command
-                        if command -v claude >/dev/null 2>&1; then
-                            if [ "$is_claude_initialized" = "true" ]; then
-                                # Try with --continue first, fallback to fresh start if it fails
-                                tmux new-window -t "$session_name:2" -c "$worktree_path" -n "claude" "claude --add-dir \"$worktree_path\" --continue --permission-mode plan || claude --add-dir \"$worktree_path\" --permission-mode plan"
-                            else
-                                tmux new-window -t "$session_name:2" -c "$worktree_path" -n "claude" "claude --add-dir \"$worktree_path\" --permission-mode plan"
-                            fi
-                        else
-                            # Fallback to shell if claude not available
-                            tmux new-window -t "$session_name:2" -c "$worktree_path" -n "claude"
-                        fi
-                    else
-                        # Create terminal window
-                        tmux new-window -t "$session_name:1" -c "$worktree_path" -n "terminal"
-                    fi
-                fi
-                # Attach to target window
-                tmux attach-session -t "$session_name:${window_index}"
-                continue
-            else
-                # Create new session with both windows
-                if [ "$debug_enabled" = "true" ]; then
-                    echo "DEBUG wrapper: Creating new session: $session_name" >> "$debug_log"
-                fi
-                # Window 1: terminal (always created) - base-index 1 makes first window = 1
-                tmux new-session -d -s "$session_name" -c "$worktree_path" -n "terminal"
-
-                # Window 2: claude (if auto-claude is true)
-                if [ "$auto_claude" = "true" ]; then
-                    if command -v claude >/dev/null 2>&1; then
-                        if [ "$is_claude_initialized" = "true" ]; then
-                            # Try with --continue first, fallback to fresh start if it fails
-                            tmux new-window -t "$session_name:2" -c "$worktree_path" -n "claude" "claude --add-dir \"$worktree_path\" --continue --permission-mode plan || claude --add-dir \"$worktree_path\" --permission-mode plan"
-                        else
-                            tmux new-window -t "$session_name:2" -c "$worktree_path" -n "claude" "claude --add-dir \"$worktree_path\" --permission-mode plan"
-                        fi
-                    else
-                        # Fallback: create window with shell
-                        tmux new-window -t "$session_name:2" -c "$worktree_path" -n "claude"
-                    fi
-                fi
-
-                # Attach to target window
-                tmux attach-session -t "$session_name:${window_index}"
-                continue
+            # Inside tmux the client was switched to the session, so there is nothing to return to
+            if [ "$backend" = "tmux" ] && [ -n "$TMUX" ]; then
+                return
             fi
+            continue
         else
             return 1
         fi
@@ -180,7 +110,7 @@ func GetBashZshWrapper() string {
     done
 }
 %s
-`, startMarker, cliName, cliName, cliName, cliName, configDir, configDir, cliName, cliName, cliName, envVarPrefix, cliName, cliName, sessionPrefix, sessionPrefix, cliName, endMarker)
+`, startMarker, cliName, cliName, cliName, cliName, configDir, configDir, cliName, cliName, cliName, envVarPrefix, envVarPrefix, WrapperVersion, cliName, cliName, cliName, endMarker)
 }
 
 // GetFishWrapper returns the wrapper function for fish shell
@@ -189,7 +119,6 @@ func GetFishWrapper() string {
 	cliName := branding.CLIName
 	configDir := branding.ConfigDirName
 	envVarPrefix := branding.EnvVarPrefix
-	sessionPrefix := branding.SessionPrefix
 	startMarker := branding.GetShellWrapperMarkerStart()
 	endMarker := branding.GetShellWrapperMarkerEnd()
 
@@ -213,12 +142,13 @@ function %s
 
         # Set environment variable so %s knows to write to file
         set -x %s_SWITCH_FILE $temp_file
+        set -x %s_WRAPPER_VERSION %d
         command %s $argv
         set exit_code $status
 
         # Check if switch info was written
         if test -f "$temp_file" -a -s "$temp_file"
-            # Read the switch info: path|branch|auto-claude|target-window|script-command|claude-session-name|is-claude-initialized
+            # Read the switch info: path|branch|auto-claude|target-window|script-command|claude-session-name|is-claude-initialized|backend|attach-command
             set switch_info (cat $temp_file)
             rm $temp_file
 
@@ -242,93 +172,31 @@ function %s
                 if test (count $parts) -ge 7
                     set is_claude_initialized $parts[7]
                 end
-
-                # Check if tmux is available
-                if not command -v tmux &> /dev/null
-                    # No tmux, just cd
-                    cd $worktree_path
-                    echo "Switched to worktree: $branch (no tmux)"
-                    return
+                set backend "tmux"
+                if test (count $parts) -ge 8
+                    set backend $parts[8]
                 end
-
-                # Use the pre-sanitized session name from %s (includes repo basename)
-                # Format: %s<repo>-<branch>
-                set session_name "$claude_session_name"
-
-                # If session name is empty (older version or fallback), generate it from branch
-                if test -z "$session_name"
-                    set session_name "%s"(string replace -ra '[^a-zA-Z0-9\-_]' '-' $branch)
-                    set session_name (string replace -ra '--+' '-' $session_name)
-                    set session_name (string trim -c '-' $session_name)
+                # The attach command is last, rejoin it in case it contains '|'
+                set attach_command ""
+                if test (count $parts) -ge 9
+                    set attach_command (string join '|' $parts[9..-1])
                 end
 
-                # Check if already in a tmux session
-                if test -n "$TMUX"
-                    # Already in tmux, just cd
-                    cd $worktree_path
+                cd $worktree_path
+
+                # %s has prepared the session (agents, jean.json layout, ports) and hands over
+                # the command that enters it; without one (no multiplexer) just switch directories
+                if test -z "$attach_command"
                     echo "Switched to worktree: $branch"
-                    echo "Note: Already in tmux. Session: $session_name would be available outside tmux."
                     return
                 end
+                sh -c "$attach_command"
 
-                # Set window index based on target window
-                # Note: with base-index 1, windows are 1, 2, 3... instead of 0, 1, 2...
-                set window_index "1"
-                if test "$target_window" = "claude"
-                    set window_index "2"
-                end
-
-                # Check if session exists
-                if tmux has-session -t "=$session_name" 2>/dev/null
-                    # Session exists - check if target window exists
-                    set window_exists (tmux list-windows -t "$session_name" -F "#{window_index}:#{window_name}" | grep "^${window_index}:" | wc -l)
-                    if test $window_exists -eq 0
-                        # Target window doesn't exist, create it
-                        if test "$target_window" = "claude"
-                            # Create claude window
-                            if command -v claude &> /dev/null
-                                set claude_args "--add-dir \"$worktree_path\" --permission-mode plan"
-                                if test "$is_claude_initialized" = "true"
-                                    # Try with --continue first, fallback to fresh start if it fails
-                                    set claude_args "--add-dir \"$worktree_path\" --continue --permission-mode plan; or claude --add-dir \"$worktree_path\" --permission-mode plan"
-                                end
-                                tmux new-window -t "$session_name:2" -c "$worktree_path" -n "claude" "claude $claude_args"
-                            else
-                                # Fallback to shell
-                                tmux new-window -t "$session_name:2" -c "$worktree_path" -n "claude"
-                            end
-                        else
-                            # Create terminal window
-                            tmux new-window -t "$session_name:1" -c "$worktree_path" -n "terminal"
-                        end
-                    end
-                    # Attach to target window
-                    tmux attach-session -t "$session_name:${window_index}"
-                    continue
-                else
-                    # Create new session with both windows
-                    # Window 1: terminal (always created) - base-index 1 makes first window = 1
-                    tmux new-session -d -s "$session_name" -c "$worktree_path" -n "terminal"
-
-                    # Window 2: claude (if auto-claude is true)
-                    if test "$auto_claude" = "true"
-                        if command -v claude &> /dev/null
-                            set claude_args "--add-dir \"$worktree_path\" --permission-mode plan"
-                            if test "$is_claude_initialized" = "true"
-                                # Try with --continue first, fallback to fresh start if it fails
-                                set claude_args "--add-dir \"$worktree_path\" --continue --permission-mode plan; or claude --add-dir \"$worktree_path\" --permission-mode plan"
-                            end
-                            tmux new-window -t "$session_name:2" -c "$worktree_path" -n "claude" "claude $claude_args"
-                        else
-                            # Fallback: create window with shell
-                            tmux new-window -t "$session_name:2" -c "$worktree_path" -n "claude"
-                        end
-                    end
-
-                    # Attach to target window
-                    tmux attach-session -t "$session_name:${window_index}"
-                    continue
+                # Inside tmux the client was switched to the session, so there is nothing to return to
+                if test "$backend" = "tmux"; and test -n "$TMUX"
+                    return
                 end
+                continue
             end
         else
             # No switch file, just clean up
@@ -339,7 +207,7 @@ function %s
     end
 end
 %s
-`, startMarker, cliName, cliName, cliName, configDir, configDir, cliName, cliName, envVarPrefix, cliName, cliName, sessionPrefix, sessionPrefix, endMarker)
+`, startMarker, cliName, cliName, cliName, configDir, configDir, cliName, cliName, envVarPrefix, envVarPrefix, WrapperVersion, cliName, cliName, endMarker)
 }
 
 // Legacy constants for backwards compatibility (deprecated, use functions instead)
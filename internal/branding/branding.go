@@ -69,6 +69,12 @@ func GetGitDebugLogPath() string {
 	return fmt.Sprintf("/tmp/%s-git-debug.log", CLIName)
 }
 
+// GetWatcherPIDPath returns the PID file of the background agent watcher.
+// Example: returns "/tmp/jean-watch.pid" by default.
+func GetWatcherPIDPath() string {
+	return fmt.Sprintf("/tmp/%s-watch.pid", CLIName)
+}
+
 // GetTmuxConfigMarkerStart returns the start marker for tmux config.
 func GetTmuxConfigMarkerStart() string {
 	upper := strings.ToUpper(CLIName)
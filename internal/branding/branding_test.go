@@ -76,6 +76,14 @@ func TestGetGitDebugLogPath(t *testing.T) {
 	}
 }
 
+// TestGetWatcherPIDPath tests the GetWatcherPIDPath function
+func TestGetWatcherPIDPath(t *testing.T) {
+	path := GetWatcherPIDPath()
+	if path != "/tmp/jean-watch.pid" {
+		t.Errorf("Expected '/tmp/jean-watch.pid', got '%s'", path)
+	}
+}
+
 // TestGetTmuxConfigMarkerStart tests the GetTmuxConfigMarkerStart function
 func TestGetTmuxConfigMarkerStart(t *testing.T) {
 	marker := GetTmuxConfigMarkerStart()
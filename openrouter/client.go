@@ -119,6 +119,32 @@ func (c *Client) GenerateBranchName(diff, customPrompt string) (string, error) {
 		return "", err
 	}
 
+	return cleanBranchName(name)
+}
+
+// GenerateBranchNameFromTask generates a semantic branch name from a task description
+func (c *Client) GenerateBranchNameFromTask(task string) (string, error) {
+	if c.apiKey == "" {
+		return "", fmt.Errorf("OpenRouter API key not configured")
+	}
+
+	// Limit task to reasonable size
+	if len(task) > 3000 {
+		task = task[:3000]
+	}
+
+	prompt := strings.ReplaceAll(DefaultTaskBranchNamePrompt, "{task}", task)
+
+	name, err := c.callAPI(prompt)
+	if err != nil {
+		return "", err
+	}
+
+	return cleanBranchName(name)
+}
+
+// cleanBranchName turns an AI response into a valid kebab-case branch name
+func cleanBranchName(name string) (string, error) {
 	// Clean up response
 	name = strings.TrimSpace(name)
 	name = strings.ToLower(name)
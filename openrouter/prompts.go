@@ -45,6 +45,17 @@ Examples: fix-login-bug, feat-dark-theme, refactor-api-client
 Git diff:
 {diff}`
 
+	// DefaultTaskBranchNamePrompt generates a semantic branch name from a task description
+	// The {task} placeholder will be replaced with the task
+	DefaultTaskBranchNamePrompt = `Generate a short, semantic git branch name for this task.
+
+Return ONLY the branch name (lowercase, kebab-case, max 40 characters). No explanations or markdown.
+
+Examples: fix-login-bug, feat-dark-theme, refactor-api-client
+
+Task:
+{task}`
+
 	// DefaultPRPrompt generates a PR title and release notes style description from git diff
 	// The {diff} placeholder will be replaced with the actual git diff
 	DefaultPRPrompt = `Generate a pull request title and release notes style description for these changes.
@@ -0,0 +1,264 @@
+package session
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/andrew-bierman/jean-tui/config"
+	"github.com/andrew-bierman/jean-tui/internal/branding"
+)
+
+// safeShellArg matches arguments that don't need quoting
+var safeShellArg = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// shellArg quotes an argument only when needed, keeping common commands readable
+func shellArg(s string) string {
+	if safeShellArg.MatchString(s) {
+		return s
+	}
+	return shellQuote(s)
+}
+
+// FormatArgs joins arguments into a command line, quoting those that need it
+func FormatArgs(args []string) string {
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		quoted = append(quoted, shellArg(arg))
+	}
+	return strings.Join(quoted, " ")
+}
+
+// SplitArgs splits a command line into arguments, honouring single and double quotes and backslashes
+func SplitArgs(line string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inArg = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("unterminated quote or escape in %q", line)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// DefaultAgent returns the definition of the build-time default agent (branding.AgentCommand)
+func DefaultAgent() config.AgentDefinition {
+	for _, agent := range config.BuiltinAgents() {
+		if agent.Name == branding.AgentCommand {
+			return agent
+		}
+	}
+	// Custom build-time agent: run the command as-is
+	return config.AgentDefinition{Name: branding.AgentCommand, Command: branding.AgentCommand}
+}
+
+// expandAgentArgs appends args to parts, substituting {path}, {prompt} and {session}
+func expandAgentArgs(parts []string, args []string, path, prompt, sessionID string) []string {
+	for _, arg := range args {
+		arg = strings.ReplaceAll(arg, "{path}", path)
+		arg = strings.ReplaceAll(arg, "{prompt}", prompt)
+		arg = strings.ReplaceAll(arg, "{session}", sessionID)
+		parts = append(parts, shellArg(arg))
+	}
+	return parts
+}
+
+// BuildAgentCommand builds the shell command that starts an agent in a worktree.
+// With resume (and no prompt), the agent continues a previous conversation and falls back to a
+// fresh start if that fails: the conversation with resumeID when the agent can resume by ID,
+// otherwise the last one in the worktree. The prompt is only passed to agents that declare PromptArgs.
+func BuildAgentCommand(agent config.AgentDefinition, path string, resume bool, resumeID, prompt string) string {
+	if agent.Command == "" {
+		return ""
+	}
+
+	build := func(resumeArgs []string) string {
+		parts := []string{agent.Command}
+		parts = expandAgentArgs(parts, resumeArgs, path, "", resumeID)
+		parts = expandAgentArgs(parts, agent.StartArgs, path, "", "")
+		parts = expandAgentArgs(parts, agent.AddDirArgs, path, "", "")
+		parts = expandAgentArgs(parts, agent.PermissionArgs, path, "", "")
+		if len(resumeArgs) == 0 && prompt != "" {
+			parts = expandAgentArgs(parts, agent.PromptArgs, path, prompt, "")
+		}
+		return strings.Join(parts, " ")
+	}
+
+	if resume && prompt == "" {
+		if resumeID != "" && len(agent.ResumeIDArgs) > 0 {
+			return build(agent.ResumeIDArgs) + " || " + build(nil)
+		}
+		if len(agent.ResumeArgs) > 0 {
+			return build(agent.ResumeArgs) + " || " + build(nil)
+		}
+	}
+	return build(nil)
+}
+
+// IsAgentInstalled checks if an agent's executable is available in PATH
+func IsAgentInstalled(agent config.AgentDefinition) bool {
+	fields := strings.Fields(agent.Command)
+	if len(fields) == 0 {
+		return false
+	}
+	_, err := exec.LookPath(fields[0])
+	return err == nil
+}
+
+// agentWindowName returns the tmux window name for an extra agent, avoiding the default window names
+func agentWindowName(agent config.AgentDefinition) string {
+	if isDefaultWindow(agent.Name) {
+		return agent.Name + "-2"
+	}
+	return agent.Name
+}
+
+// StartAgents makes sure every agent runs in the session: the first in the agent window (2),
+// the others in windows of their own named after the agent. Windows that already exist are kept.
+// resumeID is the conversation the first agent resumes ("" = its last one); a prompt instead
+// starts the first agent on a fresh conversation with that initial prompt.
+func (m *Manager) StartAgents(sessionName, path string, agents []config.AgentDefinition, resume bool, resumeID, prompt string) error {
+	windows, err := listWindows(sessionName)
+	if err != nil {
+		return err
+	}
+
+	for i, agent := range agents {
+		name := agentWindowName(agent)
+		target := ""
+		if i == 0 {
+			name = branding.AgentWindowName
+			target = sessionName + ":2"
+			if isIndexTaken(windows, 2) {
+				continue
+			}
+		} else {
+			if _, exists := windows[name]; exists {
+				continue
+			}
+			index := firstLayoutWindowIndex
+			for isIndexTaken(windows, index) {
+				index++
+			}
+			target = fmt.Sprintf("%s:%d", sessionName, index)
+			windows[name] = index
+		}
+
+		args := []string{"new-window", "-d", "-t", target, "-c", path, "-n", name}
+		if IsAgentInstalled(agent) {
+			id, initial := "", ""
+			if i == 0 {
+				id, initial = resumeID, prompt
+			}
+			args = append(args, BuildAgentCommand(agent, path, resume, id, initial))
+		}
+		// Otherwise fall back to a shell so the window still exists
+		if output, err := exec.Command("tmux", args...).CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to start %s: %s", agent.Name, string(output))
+		}
+		if i == 0 {
+			windows[name] = 2
+		}
+	}
+
+	return nil
+}
+
+// SessionOptions controls how PrepareSession sets up a session
+type SessionOptions struct {
+	AutoStartAgent bool                     // Start the agents (otherwise only terminal + layout windows)
+	Resume         bool                     // Agents continue their previous conversation
+	ResumeID       string                   // Conversation the first agent resumes ("" = its last one)
+	Prompt         string                   // Initial prompt of the first agent (starts a fresh conversation)
+	Agents         []config.AgentDefinition // First runs in the agent window, the rest side by side in their own windows
+	Layout         *config.LayoutConfig     // Optional jean.json layout
+	Env            []string                 // KEY=VALUE pairs set in the session environment (e.g. allocated ports)
+}
+
+// PrepareSession makes sure a session exists with its agents and layout, without attaching.
+// For existing sessions only missing windows and panes are added.
+func (m *Manager) PrepareSession(sessionName, path string, opts SessionOptions) error {
+	if !m.SessionExists(sessionName) {
+		args := []string{"new-session", "-d", "-s", sessionName, "-c", path, "-n", "terminal"}
+		for _, env := range opts.Env {
+			args = append(args, "-e", env) // The first window doesn't pick up set-environment
+		}
+		cmd := exec.Command("tmux", args...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to create session: %s", string(output))
+		}
+		agent := DefaultAgent().Name
+		if len(opts.Agents) > 0 {
+			agent = opts.Agents[0].Name
+		}
+		m.tagNewSession(sessionName, path, agent)
+	}
+
+	// Windows created from now on (agents, layout) inherit the session environment
+	for _, env := range opts.Env {
+		if key, value, ok := strings.Cut(env, "="); ok {
+			exec.Command("tmux", "set-environment", "-t", sessionName, key, value).Run()
+		}
+	}
+
+	if opts.AutoStartAgent && len(opts.Agents) > 0 {
+		if err := m.StartAgents(sessionName, path, opts.Agents, opts.Resume, opts.ResumeID, opts.Prompt); err != nil {
+			return err
+		}
+	}
+
+	return m.ApplyLayout(sessionName, path, opts.Layout)
+}
+
+// RestartAgent replaces the process in the agent window (2) with the first agent, resuming
+// the conversation with resumeID, or starting a fresh conversation when resume is false
+func (m *Manager) RestartAgent(sessionName, path string, agent config.AgentDefinition, resume bool, resumeID string) error {
+	command := BuildAgentCommand(agent, path, resume, resumeID, "")
+	if command == "" || !IsAgentInstalled(agent) {
+		return fmt.Errorf("%s is not installed", agent.Name)
+	}
+	windows, err := listWindows(sessionName)
+	if err != nil {
+		return err
+	}
+	if !isIndexTaken(windows, 2) {
+		return m.StartAgents(sessionName, path, []config.AgentDefinition{agent}, resume, resumeID, "")
+	}
+	if output, err := exec.Command("tmux", "respawn-window", "-k", "-t", sessionName+":2", "-c", path, command).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to restart %s: %s", agent.Name, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package session
+
+import (
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/andrew-bierman/jean-tui/config"
+)
+
+// TestSplitArgs tests splitting command lines with quotes and escapes
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    []string
+		wantErr bool
+	}{
+		{"empty", "   ", nil, false},
+		{"plain", "--model opus  --verbose", []string{"--model", "opus", "--verbose"}, false},
+		{"double quotes", `--append-system-prompt "be brief"`, []string{"--append-system-prompt", "be brief"}, false},
+		{"single quotes keep backslashes", `--pattern 'a\b'`, []string{"--pattern", `a\b`}, false},
+		{"escaped space", `--dir my\ dir`, []string{"--dir", "my dir"}, false},
+		{"escaped quote in double quotes", `"say \"hi\""`, []string{`say "hi"`}, false},
+		{"empty quoted argument", `--name ""`, []string{"--name", ""}, false},
+		{"adjacent quotes", `--x='a b'c`, []string{"--x=a bc"}, false},
+		{"tabs and newlines", "a\tb\nc", []string{"a", "b", "c"}, false},
+		{"unterminated quote", `--name "open`, nil, true},
+		{"trailing backslash", `--name \`, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SplitArgs(tt.line)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestFormatArgs_RoundTrip tests that formatted arguments split back into the same arguments
+func TestFormatArgs_RoundTrip(t *testing.T) {
+	args := []string{"--model", "opus 4", "it's", `a"b`, `back\slash`, "", "--x=1"}
+	line := FormatArgs(args)
+	got, err := SplitArgs(line)
+	if err != nil {
+		t.Fatalf("SplitArgs(%q) failed: %v", line, err)
+	}
+	if !reflect.DeepEqual(got, args) {
+		t.Errorf("Expected %q, got %q (from %q)", args, got, line)
+	}
+}
+
+// TestBuildAgentCommand tests the agent start commands, including the resume fallback
+func TestBuildAgentCommand(t *testing.T) {
+	claude := builtinAgent(t, "claude")
+	aider := builtinAgent(t, "aider")
+	const path = "/work/my tree"
+	tests := []struct {
+		name     string
+		agent    config.AgentDefinition
+		resume   bool
+		resumeID string
+		prompt   string
+		want     string
+	}{
+		{
+			name:  "fresh",
+			agent: claude,
+			want:  "claude --add-dir '/work/my tree' --permission-mode plan",
+		},
+		{
+			name:   "resume last or start fresh",
+			agent:  claude,
+			resume: true,
+			want:   "claude --continue --add-dir '/work/my tree' --permission-mode plan || claude --add-dir '/work/my tree' --permission-mode plan",
+		},
+		{
+			name:     "resume by ID or start fresh",
+			agent:    claude,
+			resume:   true,
+			resumeID: "0b1c-42",
+			want:     "claude --resume 0b1c-42 --add-dir '/work/my tree' --permission-mode plan || claude --add-dir '/work/my tree' --permission-mode plan",
+		},
+		{
+			name:   "prompt starts fresh",
+			agent:  claude,
+			resume: true,
+			prompt: "Fix Bob's bug; then run `make`",
+			want:   `claude --add-dir '/work/my tree' --permission-mode plan 'Fix Bob'\''s bug; then run ` + "`make`'",
+		},
+		{
+			name:     "resume by ID unsupported",
+			agent:    aider,
+			resume:   true,
+			resumeID: "0b1c-42",
+			want:     "aider --restore-chat-history || aider",
+		},
+		{
+			name:   "prompt unsupported",
+			agent:  aider,
+			prompt: "ignored",
+			want:   "aider",
+		},
+		{
+			name:  "arguments applied",
+			agent: claude.WithArgs(config.AgentArgs{PermissionMode: "acceptEdits", ExtraArgs: []string{"--model", "opus 4"}}),
+			want:  "claude --model 'opus 4' --add-dir '/work/my tree' --permission-mode acceptEdits",
+		},
+		{
+			name:   "no command",
+			agent:  config.AgentDefinition{Name: "none"},
+			resume: true,
+			want:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BuildAgentCommand(tt.agent, path, tt.resume, tt.resumeID, tt.prompt); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestBuildAgentCommand_ResumeFallback tests that the shell starts a fresh agent when resuming fails
+func TestBuildAgentCommand_ResumeFallback(t *testing.T) {
+	// The fake agent fails when asked to resume and prints its arguments otherwise
+	agent := config.AgentDefinition{
+		Name:       "fake",
+		Command:    `sh -c 'test "$1" != --resume && echo "$@"' fake`,
+		ResumeArgs: []string{"--resume"},
+		StartArgs:  []string{"it's here"},
+	}
+	command := BuildAgentCommand(agent, "/work", true, "", "")
+	output, err := exec.Command("sh", "-c", command).Output()
+	if err != nil {
+		t.Fatalf("%s failed: %v", command, err)
+	}
+	if got := strings.TrimSpace(string(output)); got != "it's here" {
+		t.Errorf("Expected the fresh start's arguments, got %q", got)
+	}
+}
@@ -0,0 +1,187 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andrew-bierman/jean-tui/internal/branding"
+)
+
+// autosaveFileName is the rolling snapshot written periodically for running sessions
+const autosaveFileName = "autosave.log"
+
+// archiveTimeFormat names archive files so they sort chronologically
+const archiveTimeFormat = "20060102-150405"
+
+// Archive is a saved scrollback transcript of a session
+type Archive struct {
+	Session  string    // tmux session name (jean-<repo>-<branch>)
+	Path     string    // Log file path
+	Time     time.Time // When the scrollback was captured
+	Autosave bool      // Periodic snapshot of a session that may still be running
+	Size     int64
+}
+
+// ArchiveMatch is a line in an archive that matches a search
+type ArchiveMatch struct {
+	Archive Archive
+	Line    int // 0-based line number
+	Text    string
+}
+
+// ArchiveDir returns the directory scrollback archives are stored in (~/.config/<cli>/archives)
+func ArchiveDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", branding.ConfigDirName, "archives"), nil
+}
+
+// CaptureScrollback returns the full history of every pane in a session
+func (m *Manager) CaptureScrollback(sessionName string) (string, error) {
+	output, err := exec.Command("tmux", "list-panes", "-s", "-t", sessionName, "-F", "#{window_index}\t#{window_name}\t#{window_panes}\t#{pane_index}\t#{pane_id}").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to list panes: %w", err)
+	}
+
+	var b strings.Builder
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		parts := strings.SplitN(line, "\t", 5)
+		if len(parts) != 5 {
+			continue
+		}
+
+		// -J joins wrapped lines, -S - -E - captures the entire history
+		content, err := exec.Command("tmux", "capture-pane", "-p", "-J", "-S", "-", "-E", "-", "-t", parts[4]).Output()
+		if err != nil {
+			continue
+		}
+		if parts[2] == "1" {
+			fmt.Fprintf(&b, "===== window %s: %s =====\n", parts[0], parts[1])
+		} else {
+			fmt.Fprintf(&b, "===== window %s: %s (pane %s) =====\n", parts[0], parts[1], parts[3])
+		}
+		b.WriteString(strings.TrimRight(string(content), "\n"))
+		b.WriteString("\n\n")
+	}
+	return b.String(), nil
+}
+
+// writeArchive captures a session into a file in its archive directory
+func (m *Manager) writeArchive(sessionName, fileName string) (string, error) {
+	content, err := m.CaptureScrollback(sessionName)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(content) == "" {
+		return "", nil
+	}
+
+	dir, err := ArchiveDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, sessionName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	path := filepath.Join(dir, fileName)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		return "", fmt.Errorf("failed to write archive: %w", err)
+	}
+	return path, nil
+}
+
+// ArchiveScrollback saves the session's full scrollback to a timestamped log file.
+// Returns the file path ("" if there was nothing to save).
+func (m *Manager) ArchiveScrollback(sessionName string) (string, error) {
+	path, err := m.writeArchive(sessionName, time.Now().Format(archiveTimeFormat)+".log")
+	if err == nil && path != "" {
+		// The final archive supersedes the rolling snapshot
+		if dir, dirErr := ArchiveDir(); dirErr == nil {
+			os.Remove(filepath.Join(dir, sessionName, autosaveFileName))
+		}
+	}
+	return path, err
+}
+
+// AutosaveScrollback overwrites the session's rolling snapshot (survives tmux server crashes/reboots)
+func (m *Manager) AutosaveScrollback(sessionName string) error {
+	_, err := m.writeArchive(sessionName, autosaveFileName)
+	return err
+}
+
+// ListArchives returns archives of sessions whose name starts with prefix, newest first
+func ListArchives(prefix string) ([]Archive, error) {
+	dir, err := ArchiveDir()
+	if err != nil {
+		return nil, err
+	}
+
+	sessionDirs, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Archive{}, nil
+		}
+		return nil, fmt.Errorf("failed to read archives: %w", err)
+	}
+
+	var archives []Archive
+	for _, sessionDir := range sessionDirs {
+		if !sessionDir.IsDir() || !strings.HasPrefix(sessionDir.Name(), prefix) {
+			continue
+		}
+		files, err := os.ReadDir(filepath.Join(dir, sessionDir.Name()))
+		if err != nil {
+			continue
+		}
+		for _, file := range files {
+			info, err := file.Info()
+			if err != nil || file.IsDir() || !strings.HasSuffix(file.Name(), ".log") {
+				continue
+			}
+
+			archive := Archive{
+				Session:  sessionDir.Name(),
+				Path:     filepath.Join(dir, sessionDir.Name(), file.Name()),
+				Time:     info.ModTime(),
+				Autosave: file.Name() == autosaveFileName,
+				Size:     info.Size(),
+			}
+			if t, err := time.ParseInLocation(archiveTimeFormat, strings.TrimSuffix(file.Name(), ".log"), time.Local); err == nil {
+				archive.Time = t
+			}
+			archives = append(archives, archive)
+		}
+	}
+
+	sort.Slice(archives, func(i, j int) bool {
+		return archives[i].Time.After(archives[j].Time)
+	})
+	return archives, nil
+}
+
+// SearchArchives returns the lines of the archives containing query (case-insensitive)
+func SearchArchives(archives []Archive, query string) []ArchiveMatch {
+	query = strings.ToLower(query)
+	var matches []ArchiveMatch
+	for _, archive := range archives {
+		content, err := os.ReadFile(archive.Path)
+		if err != nil {
+			continue
+		}
+		for i, line := range strings.Split(string(content), "\n") {
+			if strings.Contains(strings.ToLower(line), query) {
+				matches = append(matches, ArchiveMatch{Archive: archive, Line: i, Text: strings.TrimSpace(line)})
+			}
+		}
+	}
+	return matches
+}
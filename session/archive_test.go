@@ -0,0 +1,42 @@
+package session
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrew-bierman/jean-tui/internal/branding"
+)
+
+// TestCaptureScrollback_Panes tests that every pane of a split window is captured
+func TestCaptureScrollback_Panes(t *testing.T) {
+	startTestTmux(t)
+	name := branding.SessionPrefix + "repo-capture"
+	newTestSession(t, name, t.TempDir())
+	for _, args := range [][]string{
+		{"respawn-pane", "-k", "-t", name + ":", "echo first-pane; sleep 60"},
+		{"split-window", "-d", "-t", name + ":", "echo second-pane; sleep 60"},
+	} {
+		if output, err := exec.Command("tmux", args...).CombinedOutput(); err != nil {
+			t.Fatalf("tmux %v: %s", args, output)
+		}
+	}
+	m := NewManager()
+
+	var content string
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		content, _ = m.CaptureScrollback(name)
+		if strings.Contains(content, "first-pane") && strings.Contains(content, "second-pane") {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if !strings.Contains(content, "first-pane") || !strings.Contains(content, "second-pane") {
+		t.Errorf("Expected the output of both panes, got %q", content)
+	}
+	if strings.Count(content, "(pane ") != 2 {
+		t.Errorf("Expected a header per pane, got %q", content)
+	}
+}
@@ -0,0 +1,168 @@
+package session
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/andrew-bierman/jean-tui/internal/branding"
+)
+
+// Multiplexer backends worktree sessions can run in
+const (
+	BackendTmux   = "tmux"
+	BackendZellij = "zellij"
+	BackendNone   = "none" // No multiplexer: switch into the worktree directory (and run the agent in the foreground)
+)
+
+// BackendNames lists the available backends in the order shown in settings
+var BackendNames = []string{BackendTmux, BackendZellij, BackendNone}
+
+// Capabilities describes which session features a backend supports
+type Capabilities struct {
+	Windows  bool // Several windows per session (terminal, agents, jean.json layout)
+	Capture  bool // Reading pane output (agent state, alerts, scrollback archives, restore)
+	Detached bool // Starting sessions in the background (parallel agent attempts)
+	Layouts  bool // Re-applying the jean.json layout and adding agents to running sessions
+	Input    bool // Sending prompts to agents without attaching
+}
+
+// Backend runs worktree sessions in a terminal multiplexer.
+// The shell wrapper enters sessions after jean exits by running the command from AttachCommand.
+type Backend interface {
+	Name() string
+	IsAvailable() bool
+	Capabilities() Capabilities
+	SessionExists(sessionName string) bool
+	List(repoPath string) ([]Session, error)
+	Kill(sessionName string) error
+	// AttachCommand prepares the session and returns the sh command the wrapper runs in the
+	// worktree to enter it ("" means the wrapper just switches into the worktree directory)
+	AttachCommand(sessionName, path, targetWindow string, opts SessionOptions) (string, error)
+}
+
+// NewBackend returns the backend with the given name (tmux for unknown names)
+func NewBackend(name string) Backend {
+	switch name {
+	case BackendZellij:
+		return &ZellijBackend{}
+	case BackendNone:
+		return &NoneBackend{}
+	default:
+		return NewManager()
+	}
+}
+
+// Name returns the backend name
+func (m *Manager) Name() string {
+	return BackendTmux
+}
+
+// IsAvailable reports whether tmux is installed
+func (m *Manager) IsAvailable() bool {
+	return m.IsTmuxAvailable()
+}
+
+// Capabilities returns the features tmux supports (all of them)
+func (m *Manager) Capabilities() Capabilities {
+	return Capabilities{Windows: true, Capture: true, Detached: true, Layouts: true, Input: true}
+}
+
+// AttachCommand prepares the session (agents, jean.json layout, environment) and returns a command
+// that attaches to the target window, or switches the client to it when run inside tmux
+func (m *Manager) AttachCommand(sessionName, path, targetWindow string, opts SessionOptions) (string, error) {
+	if !m.IsTmuxAvailable() {
+		return "", nil
+	}
+	if err := m.PrepareSession(sessionName, path, opts); err != nil {
+		return "", err
+	}
+	if err := ensureDefaultWindow(sessionName, path, targetWindow); err != nil {
+		return "", err
+	}
+	target := shellQuote(WindowTarget(sessionName, targetWindow))
+	return fmt.Sprintf(`if [ -n "$TMUX" ]; then tmux switch-client -t %s; else tmux attach-session -t %s; fi`, target, target), nil
+}
+
+// ensureDefaultWindow adds the terminal (1) or agent (2) window as a shell when it's the target
+// but doesn't exist, e.g. the agent window of a session started without its agent
+func ensureDefaultWindow(sessionName, path, targetWindow string) error {
+	index, name := 1, "terminal"
+	switch {
+	case isAgentWindow(targetWindow):
+		index, name = 2, branding.AgentWindowName
+	case targetWindow != "" && targetWindow != "terminal":
+		return nil // Layout windows are created with the layout
+	}
+	windows, err := listWindows(sessionName)
+	if err != nil {
+		return err
+	}
+	if isIndexTaken(windows, index) {
+		return nil
+	}
+	if output, err := exec.Command("tmux", "new-window", "-d", "-t", fmt.Sprintf("%s:%d", sessionName, index), "-c", path, "-n", name).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to create %s window: %s", name, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// NoneBackend runs without a multiplexer: the wrapper switches into the worktree directory
+// and, when the agent is requested, runs it in the foreground
+type NoneBackend struct{}
+
+// Name returns the backend name
+func (b *NoneBackend) Name() string {
+	return BackendNone
+}
+
+// IsAvailable always reports true (only a shell is needed)
+func (b *NoneBackend) IsAvailable() bool {
+	return true
+}
+
+// Capabilities returns no session features
+func (b *NoneBackend) Capabilities() Capabilities {
+	return Capabilities{}
+}
+
+// SessionExists always reports false
+func (b *NoneBackend) SessionExists(sessionName string) bool {
+	return false
+}
+
+// List returns no sessions
+func (b *NoneBackend) List(repoPath string) ([]Session, error) {
+	return []Session{}, nil
+}
+
+// Kill does nothing
+func (b *NoneBackend) Kill(sessionName string) error {
+	return nil
+}
+
+// AttachCommand runs the first agent in the foreground when the agent window was requested
+func (b *NoneBackend) AttachCommand(sessionName, path, targetWindow string, opts SessionOptions) (string, error) {
+	if !isAgentWindow(targetWindow) || !opts.AutoStartAgent || len(opts.Agents) == 0 || !IsAgentInstalled(opts.Agents[0]) {
+		return "", nil
+	}
+	return withEnv(opts.Env, BuildAgentCommand(opts.Agents[0], path, opts.Resume, opts.ResumeID, opts.Prompt)), nil
+}
+
+// withEnv wraps a sh command so it runs with extra KEY=VALUE environment variables
+func withEnv(env []string, command string) string {
+	if len(env) == 0 {
+		return command
+	}
+	parts := []string{"env"}
+	for _, kv := range env {
+		parts = append(parts, shellArg(kv))
+	}
+	return strings.Join(append(parts, "sh", "-c", shellQuote(command)), " ")
+}
+
+// commandExists reports whether a program is on the PATH
+func commandExists(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
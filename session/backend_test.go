@@ -0,0 +1,38 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/andrew-bierman/jean-tui/config"
+	"github.com/andrew-bierman/jean-tui/internal/branding"
+)
+
+// TestNoneBackend_AttachCommand tests that the agent runs for either name of the agent window
+func TestNoneBackend_AttachCommand(t *testing.T) {
+	defer func(name string) { branding.AgentWindowName = name }(branding.AgentWindowName)
+	branding.AgentWindowName = "agent"
+	opts := SessionOptions{AutoStartAgent: true, Agents: []config.AgentDefinition{{Name: "shell", Command: "sh"}}}
+	backend := &NoneBackend{}
+
+	tests := []struct {
+		targetWindow string
+		wantAgent    bool
+	}{
+		{"claude", true},
+		{"agent", true},
+		{"terminal", false},
+		{"", false},
+		{"server", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.targetWindow, func(t *testing.T) {
+			command, err := backend.AttachCommand("jean-repo-main", "/tmp", tt.targetWindow, opts)
+			if err != nil {
+				t.Fatalf("AttachCommand failed: %v", err)
+			}
+			if (command != "") != tt.wantAgent {
+				t.Errorf("Expected agent command %v, got %q", tt.wantAgent, command)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package session
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// controlNotifications are the control-mode notifications that change the session list or its activity
+var controlNotifications = map[string]bool{
+	"sessions-changed":        true,
+	"session-renamed":         true,
+	"session-window-changed":  true,
+	"window-add":              true,
+	"window-close":            true,
+	"window-renamed":          true,
+	"unlinked-window-add":     true,
+	"unlinked-window-close":   true,
+	"unlinked-window-renamed": true,
+	"subscription-changed":    true,
+}
+
+// activitySubscription names the control-mode subscription reporting activity in any session
+const activitySubscription = "activity"
+
+// activityFormat changes whenever a session sees input or output (tmux checks subscriptions every second)
+const activityFormat = "#{S:#{session_name}=#{session_activity}/#{window_activity} }"
+
+// ControlEvent is a notification from a tmux control-mode client
+type ControlEvent struct {
+	Name string // Notification without the leading % (e.g. "session-renamed")
+	Args string // Rest of the line (e.g. "$1 new-name")
+}
+
+// ControlClient follows the tmux server through a read-only control-mode client (tmux -C),
+// so session changes made outside jean are reported as they happen instead of being polled
+type ControlClient struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	events chan ControlEvent
+	done   chan struct{}
+}
+
+// StartControlClient attaches a control-mode client to the most recently used session (tmux 3.2+).
+// It fails when no tmux server or session is running; the client also ends once the server exits
+// or its session is killed, which closes Events.
+func StartControlClient() (*ControlClient, error) {
+	// ignore-size keeps the client from resizing the session, no-output drops pane output
+	// (the flags also tell jean's clients apart in controlClientCounts)
+	cmd := exec.Command("tmux", "-C", "attach-session", "-f", "read-only,ignore-size,no-output")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start tmux control client: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start tmux control client: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start tmux control client: %w", err)
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	// Wait for the reply to the attach command: %end once attached, %error (e.g. "no sessions") otherwise
+	var reply []string
+	attached := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "%end") {
+			attached = true
+			break
+		}
+		if strings.HasPrefix(line, "%error") {
+			break
+		}
+		if !strings.HasPrefix(line, "%") {
+			reply = append(reply, line)
+		}
+	}
+	if !attached {
+		stdin.Close()
+		cmd.Wait()
+		reason := strings.TrimSpace(strings.Join(reply, "\n") + "\n" + stderr.String())
+		if reason == "" {
+			reason = "tmux exited"
+		}
+		return nil, fmt.Errorf("failed to start tmux control client: %s", reason)
+	}
+
+	c := &ControlClient{
+		cmd:    cmd,
+		stdin:  stdin,
+		events: make(chan ControlEvent, 64),
+		done:   make(chan struct{}),
+	}
+	// Ask for a notification whenever activity changes in any session (an %error on older tmux is ignored)
+	fmt.Fprintf(stdin, "refresh-client -B %s\n", shellQuote(activitySubscription+"::"+activityFormat))
+
+	go c.read(scanner)
+	return c, nil
+}
+
+// read forwards notifications until the client exits, then closes the events channel
+func (c *ControlClient) read(scanner *bufio.Scanner) {
+	defer close(c.events)
+	defer c.cmd.Wait()
+	inReply := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		// Command replies are wrapped in %begin/%end (or %error) and may contain anything
+		switch {
+		case strings.HasPrefix(line, "%begin"):
+			inReply = true
+			continue
+		case strings.HasPrefix(line, "%end"), strings.HasPrefix(line, "%error"):
+			inReply = false
+			continue
+		}
+		if inReply {
+			continue
+		}
+		event, ok := parseControlLine(line)
+		if !ok {
+			continue
+		}
+		select {
+		case c.events <- event:
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// parseControlLine parses a notification line, reporting false for notifications jean ignores
+func parseControlLine(line string) (ControlEvent, bool) {
+	if !strings.HasPrefix(line, "%") {
+		return ControlEvent{}, false
+	}
+	name, args, _ := strings.Cut(line[1:], " ")
+	if !controlNotifications[name] {
+		return ControlEvent{}, false
+	}
+	return ControlEvent{Name: name, Args: args}, true
+}
+
+// Events returns the notifications of the client; the channel is closed when the client exits
+func (c *ControlClient) Events() <-chan ControlEvent {
+	return c.events
+}
+
+// Close detaches the client
+func (c *ControlClient) Close() error {
+	select {
+	case <-c.done:
+		return nil
+	default:
+		close(c.done)
+	}
+	return c.stdin.Close()
+}
+
+// controlClientFlags are the flags jean's control clients attach with (see StartControlClient)
+var controlClientFlags = []string{"control-mode", "read-only", "ignore-size", "no-output"}
+
+// isJeanControlClient reports whether a client's #{client_flags} are those of a jean control client.
+// Other control clients (e.g. iTerm2's tmux integration) display the session and count as attached.
+func isJeanControlClient(flags string) bool {
+	set := make(map[string]bool)
+	for _, flag := range strings.Split(flags, ",") {
+		set[flag] = true
+	}
+	for _, flag := range controlClientFlags {
+		if !set[flag] {
+			return false
+		}
+	}
+	return true
+}
+
+// controlClientCounts returns how many jean control clients are attached to each session.
+// They count towards session_attached but don't mean anyone is looking at the session.
+func controlClientCounts() map[string]int {
+	output, err := exec.Command("tmux", "list-clients", "-F", "#{client_flags}"+listSeparator+"#{client_session}").Output()
+	counts := make(map[string]int)
+	if err != nil {
+		return counts
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if flags, sessionName, ok := strings.Cut(line, listSeparator); ok && isJeanControlClient(flags) {
+			counts[sessionName]++
+		}
+	}
+	return counts
+}
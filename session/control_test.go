@@ -0,0 +1,54 @@
+package session
+
+import "testing"
+
+// TestParseControlLine tests which control-mode lines are reported as events
+func TestParseControlLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want ControlEvent
+		ok   bool
+	}{
+		{"sessions changed", "%sessions-changed", ControlEvent{Name: "sessions-changed"}, true},
+		{"session renamed", "%session-renamed $1 jean-repo-feature", ControlEvent{Name: "session-renamed", Args: "$1 jean-repo-feature"}, true},
+		{"window added", "%window-add @4", ControlEvent{Name: "window-add", Args: "@4"}, true},
+		{"subscription", "%subscription-changed activity $0 - - - : jean-a=1/2 ", ControlEvent{Name: "subscription-changed", Args: "activity $0 - - - : jean-a=1/2 "}, true},
+		{"pane output ignored", "%output %1 hello", ControlEvent{}, false},
+		{"layout change ignored", "%layout-change @1 b25d,80x24,0,0,1", ControlEvent{}, false},
+		{"reply line ignored", "jean-repo-main: 2 windows", ControlEvent{}, false},
+		{"empty", "", ControlEvent{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseControlLine(tt.line)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("Expected %+v (%v), got %+v (%v)", tt.want, tt.ok, got, ok)
+			}
+		})
+	}
+}
+
+// TestIsJeanControlClient tests telling jean's control clients apart from other clients
+func TestIsJeanControlClient(t *testing.T) {
+	tests := []struct {
+		name  string
+		flags string
+		want  bool
+	}{
+		{"jean client", "attached,focused,control-mode,ignore-size,no-output,read-only,UTF-8", true},
+		{"iTerm2 integration", "attached,focused,control-mode,UTF-8", false},
+		{"terminal client", "attached,focused,UTF-8", false},
+		{"read-only terminal", "attached,read-only,ignore-size,UTF-8", false},
+		{"empty", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isJeanControlClient(tt.flags); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
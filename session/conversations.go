@@ -0,0 +1,377 @@
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andrew-bierman/jean-tui/config"
+)
+
+// Formats of agent conversation files (config.AgentDefinition.ConversationFormat)
+const (
+	ConversationFormatClaude = "claude" // ~/.claude/projects/<encoded worktree path>/<id>.jsonl
+	ConversationFormatCodex  = "codex"  // ~/.codex/sessions/YYYY/MM/DD/rollout-<time>-<id>.jsonl
+)
+
+// conversationSummaryLength caps the summary taken from the first prompt
+const conversationSummaryLength = 80
+
+// Conversation is a past agent conversation in a worktree, read from the agent's local session files
+type Conversation struct {
+	ID       string
+	Agent    string
+	Path     string // Session file
+	Started  time.Time
+	Updated  time.Time
+	Summary  string // First prompt of the conversation
+	Messages int    // Prompts and replies
+}
+
+// codexSessionDays is how many days of Codex session directories are searched, newest first
+const codexSessionDays = 30
+
+// sessionFileCache keeps what was read from session files while their modification time and size are unchanged,
+// as the worktree's conversations are looked up on every activity check
+var sessionFileCache = struct {
+	sync.Mutex
+	headers       map[string]cachedCodexHeader
+	conversations map[string]cachedConversation
+}{
+	headers:       make(map[string]cachedCodexHeader),
+	conversations: make(map[string]cachedConversation),
+}
+
+// cachedCodexHeader is the session metadata from the first line of a Codex rollout file
+type cachedCodexHeader struct {
+	modTime time.Time
+	size    int64
+	id      string
+	cwd     string
+}
+
+// cachedConversation is a session file read by readConversation
+type cachedConversation struct {
+	modTime time.Time
+	size    int64
+	conv    Conversation
+	ok      bool
+}
+
+// claudeProjectChars matches the characters Claude replaces with '-' in project directory names
+var claudeProjectChars = regexp.MustCompile(`[^A-Za-z0-9]`)
+
+// conversationFiles returns an agent's session files that may belong to a worktree, newest first
+func conversationFiles(agent config.AgentDefinition, worktreePath string) []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+
+	var files []string
+	switch agent.ConversationFormat {
+	case ConversationFormatClaude:
+		dir := filepath.Join(home, ".claude", "projects", claudeProjectChars.ReplaceAllString(worktreePath, "-"))
+		files, _ = filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	case ConversationFormatCodex:
+		// Codex keeps every directory's sessions together in date directories (local time)
+		today := time.Now()
+		for i := 0; i < codexSessionDays; i++ {
+			dir := filepath.Join(home, ".codex", "sessions", today.AddDate(0, 0, -i).Format("2006/01/02"))
+			dayFiles, _ := filepath.Glob(filepath.Join(dir, "rollout-*.jsonl"))
+			files = append(files, dayFiles...)
+		}
+	}
+
+	infos := make(map[string]os.FileInfo, len(files))
+	matching := files[:0]
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		if agent.ConversationFormat == ConversationFormatCodex {
+			// Only the first line is read to leave out other directories' sessions
+			if header := codexHeader(file, info); header.id == "" || !samePath(header.cwd, worktreePath) {
+				continue
+			}
+		}
+		infos[file] = info
+		matching = append(matching, file)
+	}
+	sort.Slice(matching, func(i, j int) bool { return infos[matching[i]].ModTime().After(infos[matching[j]].ModTime()) })
+	return matching
+}
+
+// codexHeader returns the session ID and directory of a Codex rollout file, read from its first line
+func codexHeader(file string, info os.FileInfo) cachedCodexHeader {
+	sessionFileCache.Lock()
+	cached, ok := sessionFileCache.headers[file]
+	sessionFileCache.Unlock()
+	if ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached
+	}
+
+	header := cachedCodexHeader{modTime: info.ModTime(), size: info.Size()}
+	scanJSONLines(file, func(data []byte) bool {
+		var line codexLine
+		if json.Unmarshal(data, &line) == nil && line.Type == "session_meta" {
+			header.id, header.cwd = line.Payload.ID, line.Payload.Cwd
+		}
+		return false
+	})
+	sessionFileCache.Lock()
+	sessionFileCache.headers[file] = header
+	sessionFileCache.Unlock()
+	return header
+}
+
+// readConversation reads a session file, reporting false for empty ones and other directories' conversations.
+// Files unchanged since they were last read aren't read again.
+func readConversation(agent config.AgentDefinition, worktreePath, file string) (Conversation, bool) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return Conversation{}, false
+	}
+	sessionFileCache.Lock()
+	cached, found := sessionFileCache.conversations[file]
+	sessionFileCache.Unlock()
+	if found && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		cached.conv.Agent = agent.Name
+		return cached.conv, cached.ok
+	}
+
+	var conv Conversation
+	var ok bool
+	switch agent.ConversationFormat {
+	case ConversationFormatClaude:
+		conv, ok = readClaudeConversation(file)
+	case ConversationFormatCodex:
+		conv, ok = readCodexConversation(file, worktreePath)
+	}
+	sessionFileCache.Lock()
+	sessionFileCache.conversations[file] = cachedConversation{modTime: info.ModTime(), size: info.Size(), conv: conv, ok: ok}
+	sessionFileCache.Unlock()
+	conv.Agent = agent.Name
+	return conv, ok
+}
+
+// ListConversations returns an agent's conversations in a worktree, most recently updated first.
+// Agents without a ConversationFormat have none.
+func ListConversations(agent config.AgentDefinition, worktreePath string) []Conversation {
+	var conversations []Conversation
+	for _, file := range conversationFiles(agent, worktreePath) {
+		if conv, ok := readConversation(agent, worktreePath, file); ok {
+			conversations = append(conversations, conv)
+		}
+	}
+	return conversations
+}
+
+// LatestConversation returns the most recently updated conversation of an agent in a worktree
+func LatestConversation(agent config.AgentDefinition, worktreePath string) (Conversation, bool) {
+	for _, file := range conversationFiles(agent, worktreePath) {
+		if conv, ok := readConversation(agent, worktreePath, file); ok {
+			return conv, true
+		}
+	}
+	return Conversation{}, false
+}
+
+// CurrentConversationID returns the ID of an agent's most recently written conversation in a worktree
+// and when it was written. It only reads file names and headers, as it's checked while agents work.
+func CurrentConversationID(agent config.AgentDefinition, worktreePath string) (string, time.Time, bool) {
+	for _, file := range conversationFiles(agent, worktreePath) {
+		info, err := os.Stat(file)
+		if err != nil || info.Size() == 0 {
+			continue
+		}
+		id := ""
+		switch agent.ConversationFormat {
+		case ConversationFormatClaude:
+			id = strings.TrimSuffix(filepath.Base(file), ".jsonl")
+		case ConversationFormatCodex:
+			id = codexHeader(file, info).id // conversationFiles only returns the worktree's sessions
+		}
+		if id != "" {
+			return id, info.ModTime(), true
+		}
+	}
+	return "", time.Time{}, false
+}
+
+// FindConversation looks up a conversation of an agent in a worktree by ID
+func FindConversation(agent config.AgentDefinition, worktreePath, id string) (Conversation, bool) {
+	for _, conv := range ListConversations(agent, worktreePath) {
+		if conv.ID == id {
+			return conv, true
+		}
+	}
+	return Conversation{}, false
+}
+
+// scanJSONLines calls fn with every line of a JSON lines file until fn returns false
+func scanJSONLines(path string, fn func(line []byte) bool) (os.FileInfo, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if !fn(scanner.Bytes()) {
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return info, nil
+}
+
+// messageText returns the text of a message whose content is a string or a list of content blocks
+func messageText(content json.RawMessage) string {
+	var text string
+	if json.Unmarshal(content, &text) == nil {
+		return text
+	}
+	var blocks []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if json.Unmarshal(content, &blocks) != nil {
+		return ""
+	}
+	var parts []string
+	for _, block := range blocks {
+		if block.Text != "" && (block.Type == "text" || block.Type == "input_text" || block.Type == "output_text") {
+			parts = append(parts, block.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// isPromptText reports whether a user message is a typed prompt rather than
+// a tool result or text injected by the agent (e.g. "<command-name>" or "<environment_context>")
+func isPromptText(text string) bool {
+	text = strings.TrimSpace(text)
+	return text != "" && !strings.HasPrefix(text, "<")
+}
+
+// summarize turns a prompt into a one-line summary
+func summarize(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if len([]rune(text)) > conversationSummaryLength {
+		text = string([]rune(text)[:conversationSummaryLength-3]) + "..."
+	}
+	return text
+}
+
+// claudeLine is the part of a Claude session file line jean reads
+type claudeLine struct {
+	Type      string    `json:"type"`
+	SessionID string    `json:"sessionId"`
+	Timestamp time.Time `json:"timestamp"`
+	IsMeta    bool      `json:"isMeta"`
+	Message   struct {
+		Content json.RawMessage `json:"content"`
+	} `json:"message"`
+}
+
+// readClaudeConversation reads a Claude session file (the file name is the session ID)
+func readClaudeConversation(path string) (Conversation, bool) {
+	conv := Conversation{ID: strings.TrimSuffix(filepath.Base(path), ".jsonl"), Path: path}
+	info, err := scanJSONLines(path, func(data []byte) bool {
+		var line claudeLine
+		if json.Unmarshal(data, &line) != nil {
+			return true
+		}
+		if line.Type != "user" && line.Type != "assistant" {
+			return true
+		}
+		if conv.Started.IsZero() && !line.Timestamp.IsZero() {
+			conv.Started = line.Timestamp
+		}
+		if line.Type == "user" {
+			text := messageText(line.Message.Content)
+			if line.IsMeta || !isPromptText(text) {
+				return true // Tool results and injected context aren't prompts
+			}
+			if conv.Summary == "" {
+				conv.Summary = summarize(text)
+			}
+		}
+		conv.Messages++
+		return true
+	})
+	if err != nil || conv.Messages == 0 {
+		return Conversation{}, false // Empty files are left behind by sessions that never got a prompt
+	}
+	conv.Updated = info.ModTime()
+	return conv, true
+}
+
+// codexLine is the part of a Codex rollout file line jean reads
+type codexLine struct {
+	Type    string `json:"type"`
+	Payload struct {
+		ID        string          `json:"id"`
+		Cwd       string          `json:"cwd"`
+		Timestamp time.Time       `json:"timestamp"`
+		Type      string          `json:"type"`
+		Role      string          `json:"role"`
+		Content   json.RawMessage `json:"content"`
+	} `json:"payload"`
+}
+
+// readCodexConversation reads a Codex rollout file, skipping conversations of other directories
+func readCodexConversation(path, worktreePath string) (Conversation, bool) {
+	conv := Conversation{Path: path}
+	matches := true
+	info, err := scanJSONLines(path, func(data []byte) bool {
+		var line codexLine
+		if json.Unmarshal(data, &line) != nil {
+			return true
+		}
+		switch {
+		case line.Type == "session_meta":
+			if !samePath(line.Payload.Cwd, worktreePath) {
+				matches = false
+				return false
+			}
+			conv.ID = line.Payload.ID
+			conv.Started = line.Payload.Timestamp
+		case line.Type == "response_item" && line.Payload.Type == "message":
+			if line.Payload.Role == "user" {
+				text := messageText(line.Payload.Content)
+				if !isPromptText(text) {
+					return true
+				}
+				if conv.Summary == "" {
+					conv.Summary = summarize(text)
+				}
+			} else if line.Payload.Role != "assistant" {
+				return true
+			}
+			conv.Messages++
+		}
+		return true
+	})
+	if err != nil || !matches || conv.ID == "" || conv.Messages == 0 {
+		return Conversation{}, false
+	}
+	conv.Updated = info.ModTime()
+	return conv, true
+}
@@ -0,0 +1,210 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrew-bierman/jean-tui/config"
+)
+
+// writeSessionFile writes JSON lines to a session file under dir, creating its directories
+func writeSessionFile(t *testing.T, dir, name string, lines ...string) string {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// TestReadClaudeConversation tests reading the summary and message count of Claude session files
+func TestReadClaudeConversation(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []string
+		ok       bool
+		summary  string
+		messages int
+	}{
+		{
+			name: "prompt and reply",
+			lines: []string{
+				`{"type":"user","timestamp":"2024-03-01T10:00:00Z","message":{"content":"Fix the login bug"}}`,
+				`{"type":"assistant","timestamp":"2024-03-01T10:00:05Z","message":{"content":[{"type":"text","text":"On it"}]}}`,
+			},
+			ok:       true,
+			summary:  "Fix the login bug",
+			messages: 2,
+		},
+		{
+			name: "injected context and tool results skipped",
+			lines: []string{
+				`{"type":"summary","summary":"Old"}`,
+				`{"type":"user","isMeta":true,"timestamp":"2024-03-01T10:00:00Z","message":{"content":"Caveat: injected"}}`,
+				`{"type":"user","timestamp":"2024-03-01T10:00:01Z","message":{"content":"<command-name>/clear</command-name>"}}`,
+				`{"type":"user","timestamp":"2024-03-01T10:00:02Z","message":{"content":[{"type":"text","text":"Add   a\ntest"}]}}`,
+				`{"type":"user","timestamp":"2024-03-01T10:00:03Z","message":{"content":[{"type":"tool_result","tool_use_id":"t1","content":"ok"}]}}`,
+				`not json`,
+			},
+			ok:       true,
+			summary:  "Add a test",
+			messages: 1,
+		},
+		{
+			name:  "no messages",
+			lines: []string{`{"type":"summary","summary":"Old"}`},
+			ok:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeSessionFile(t, t.TempDir(), "abc-123.jsonl", tt.lines...)
+			conv, ok := readClaudeConversation(path)
+			if ok != tt.ok {
+				t.Fatalf("Expected ok %v, got %v", tt.ok, ok)
+			}
+			if !ok {
+				return
+			}
+			if conv.ID != "abc-123" {
+				t.Errorf("Expected ID abc-123, got %q", conv.ID)
+			}
+			if conv.Summary != tt.summary {
+				t.Errorf("Expected summary %q, got %q", tt.summary, conv.Summary)
+			}
+			if conv.Messages != tt.messages {
+				t.Errorf("Expected %d messages, got %d", tt.messages, conv.Messages)
+			}
+		})
+	}
+}
+
+// TestReadCodexConversation tests reading Codex rollout files of a worktree
+func TestReadCodexConversation(t *testing.T) {
+	meta := func(cwd string) string {
+		return `{"type":"session_meta","payload":{"id":"sess-1","cwd":"` + cwd + `","timestamp":"2024-03-01T10:00:00Z"}}`
+	}
+	tests := []struct {
+		name     string
+		lines    []string
+		ok       bool
+		summary  string
+		messages int
+	}{
+		{
+			name: "worktree conversation",
+			lines: []string{
+				meta("/work/tree"),
+				`{"type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"<environment_context>cwd</environment_context>"}]}}`,
+				`{"type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"Rename the flag"}]}}`,
+				`{"type":"response_item","payload":{"type":"reasoning","summary":[]}}`,
+				`{"type":"response_item","payload":{"type":"message","role":"assistant","content":[{"type":"output_text","text":"Done"}]}}`,
+			},
+			ok:       true,
+			summary:  "Rename the flag",
+			messages: 2,
+		},
+		{
+			name: "other directory",
+			lines: []string{
+				meta("/elsewhere"),
+				`{"type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"Hi"}]}}`,
+			},
+			ok: false,
+		},
+		{
+			name:  "no messages",
+			lines: []string{meta("/work/tree")},
+			ok:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeSessionFile(t, t.TempDir(), "rollout-1.jsonl", tt.lines...)
+			conv, ok := readCodexConversation(path, "/work/tree")
+			if ok != tt.ok {
+				t.Fatalf("Expected ok %v, got %v", tt.ok, ok)
+			}
+			if !ok {
+				return
+			}
+			if conv.ID != "sess-1" || conv.Summary != tt.summary || conv.Messages != tt.messages {
+				t.Errorf("Expected sess-1 %q with %d messages, got %s %q with %d", tt.summary, tt.messages, conv.ID, conv.Summary, conv.Messages)
+			}
+		})
+	}
+}
+
+// TestListConversations_Codex tests that only recent rollout files of the worktree are listed, newest first
+func TestListConversations_Codex(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	agent := config.AgentDefinition{Name: "codex", ConversationFormat: ConversationFormatCodex}
+	prompt := `{"type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"Prompt"}]}}`
+	sessions := filepath.Join(home, ".codex", "sessions")
+	dayDir := func(daysAgo int) string {
+		return filepath.Join(sessions, time.Now().AddDate(0, 0, -daysAgo).Format("2006/01/02"))
+	}
+
+	older := writeSessionFile(t, dayDir(1), "rollout-a.jsonl", `{"type":"session_meta","payload":{"id":"older","cwd":"/work/tree"}}`, prompt)
+	writeSessionFile(t, dayDir(0), "rollout-b.jsonl", `{"type":"session_meta","payload":{"id":"newer","cwd":"/work/tree"}}`, prompt)
+	writeSessionFile(t, dayDir(0), "rollout-c.jsonl", `{"type":"session_meta","payload":{"id":"other","cwd":"/other"}}`, prompt)
+	writeSessionFile(t, dayDir(codexSessionDays+1), "rollout-d.jsonl", `{"type":"session_meta","payload":{"id":"stale","cwd":"/work/tree"}}`, prompt)
+	past := time.Now().Add(-time.Hour)
+	os.Chtimes(older, past, past)
+
+	var ids []string
+	for _, conv := range ListConversations(agent, "/work/tree") {
+		ids = append(ids, conv.ID)
+		if conv.Agent != "codex" {
+			t.Errorf("Expected agent codex, got %q", conv.Agent)
+		}
+	}
+	if got := strings.Join(ids, ","); got != "newer,older" {
+		t.Errorf("Expected newer,older, got %s", got)
+	}
+
+	id, _, ok := CurrentConversationID(agent, "/work/tree")
+	if !ok || id != "newer" {
+		t.Errorf("Expected current conversation newer, got %q (%v)", id, ok)
+	}
+
+	// A rewritten file is read again
+	writeSessionFile(t, dayDir(1), "rollout-a.jsonl", `{"type":"session_meta","payload":{"id":"older","cwd":"/work/tree"}}`, prompt, prompt)
+	if conv, ok := FindConversation(agent, "/work/tree", "older"); !ok || conv.Messages != 2 {
+		t.Errorf("Expected the updated conversation with 2 messages, got %+v (%v)", conv, ok)
+	}
+}
+
+// TestScanJSONLines_ReadError tests that a read error ends the scan with an error instead of a partial result
+func TestScanJSONLines_ReadError(t *testing.T) {
+	dir := t.TempDir()
+	long := writeSessionFile(t, dir, "long.jsonl", `{"type":"user","timestamp":"2024-03-01T10:00:00Z","message":{"content":"Fix the login bug"}}`, `{"text":"`+strings.Repeat("x", 16*1024*1024)+`"}`)
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{"line over the limit", long},
+		{"directory", dir},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := scanJSONLines(tt.path, func(line []byte) bool { return true }); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+
+	if _, ok := readClaudeConversation(long); ok {
+		t.Error("Expected a file that can't be read to be skipped")
+	}
+}
@@ -0,0 +1,182 @@
+package session
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/andrew-bierman/jean-tui/config"
+	"github.com/andrew-bierman/jean-tui/internal/branding"
+)
+
+// firstLayoutWindowIndex is where additional layout windows start;
+// windows 1 (terminal) and 2 (agent) are reserved for the default windows
+const firstLayoutWindowIndex = 3
+
+// LoadLayout returns the jean.json layout for a worktree, or nil if none is configured.
+// The worktree's own jean.json is preferred; otherwise the main repository's is used.
+func (m *Manager) LoadLayout(path string) *config.LayoutConfig {
+	if scripts, err := config.LoadScripts(path); err == nil && scripts.GetLayout() != nil {
+		return scripts.GetLayout()
+	}
+
+	cmd := exec.Command("git", "-C", path, "rev-parse", "--path-format=absolute", "--git-common-dir")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+	repoRoot := filepath.Dir(strings.TrimSpace(string(output)))
+	if scripts, err := config.LoadScripts(repoRoot); err == nil {
+		return scripts.GetLayout()
+	}
+	return nil
+}
+
+// isDefaultWindow reports whether a window name refers to the terminal or agent window
+func isDefaultWindow(name string) bool {
+	return name == "terminal" || isAgentWindow(name)
+}
+
+// isAgentWindow reports whether a window name refers to the agent window
+// ("claude" is accepted whatever the configured agent window name is)
+func isAgentWindow(name string) bool {
+	return name == "claude" || name == branding.AgentWindowName
+}
+
+// listWindows returns the session's windows as name -> index
+func listWindows(sessionName string) (map[string]int, error) {
+	cmd := exec.Command("tmux", "list-windows", "-t", sessionName, "-F", "#{window_index}\t#{window_name}")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list windows: %w", err)
+	}
+
+	windows := make(map[string]int)
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		parts := strings.SplitN(line, "\t", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		if index, err := strconv.Atoi(parts[0]); err == nil {
+			windows[parts[1]] = index
+		}
+	}
+	return windows, nil
+}
+
+// paneCount returns the number of panes in a window
+func paneCount(target string) int {
+	output, err := exec.Command("tmux", "display-message", "-p", "-t", target, "#{window_panes}").Output()
+	if err != nil {
+		return 0
+	}
+	count, _ := strconv.Atoi(strings.TrimSpace(string(output)))
+	return count
+}
+
+// layoutDir resolves a layout working directory relative to the worktree
+func layoutDir(path, dir string) string {
+	if dir == "" {
+		return path
+	}
+	if filepath.IsAbs(dir) {
+		return dir
+	}
+	return filepath.Join(path, dir)
+}
+
+// ApplyLayout creates the windows and panes described by a layout in an existing session.
+// It is safe to re-apply: windows that already exist are kept, and only panes that are
+// missing from a window are added. Startup commands only run in newly created windows/panes.
+func (m *Manager) ApplyLayout(sessionName, path string, layout *config.LayoutConfig) error {
+	if layout == nil {
+		return nil
+	}
+
+	windows, err := listWindows(sessionName)
+	if err != nil {
+		return err
+	}
+
+	nextIndex := firstLayoutWindowIndex
+	for _, window := range layout.Windows {
+		if window.Name == "" {
+			continue
+		}
+
+		name := window.Name
+		if name == "claude" {
+			name = branding.AgentWindowName
+		}
+
+		index, exists := windows[name]
+		if !exists {
+			if isDefaultWindow(name) {
+				continue // Default windows are created by Create/AttachToWindow, not the layout
+			}
+			for isIndexTaken(windows, nextIndex) {
+				nextIndex++
+			}
+			index = nextIndex
+
+			args := []string{"new-window", "-d", "-t", fmt.Sprintf("%s:%d", sessionName, index), "-c", layoutDir(path, window.Dir), "-n", name}
+			if window.Command != "" {
+				args = append(args, window.Command)
+			}
+			if output, err := exec.Command("tmux", args...).CombinedOutput(); err != nil {
+				return fmt.Errorf("failed to create window %s: %s", name, string(output))
+			}
+			windows[name] = index
+		}
+
+		target := fmt.Sprintf("%s:%d", sessionName, index)
+		// The window's first pane is the window itself; only split off the panes still missing
+		start := paneCount(target) - 1
+		if start < 0 {
+			start = 0
+		}
+		for _, pane := range window.Panes[min(start, len(window.Panes)):] {
+			args := []string{"split-window", "-d", "-t", target, "-c", layoutDir(path, pane.Dir)}
+			if pane.Split == "horizontal" {
+				args = append(args, "-h")
+			} else {
+				args = append(args, "-v")
+			}
+			if pane.Size > 0 && pane.Size < 100 {
+				args = append(args, "-l", fmt.Sprintf("%d%%", pane.Size))
+			}
+			if pane.Command != "" {
+				args = append(args, pane.Command)
+			}
+			if output, err := exec.Command("tmux", args...).CombinedOutput(); err != nil {
+				return fmt.Errorf("failed to split window %s: %s", name, string(output))
+			}
+		}
+	}
+
+	return nil
+}
+
+// isIndexTaken reports whether a window index is in use
+func isIndexTaken(windows map[string]int, index int) bool {
+	for _, i := range windows {
+		if i == index {
+			return true
+		}
+	}
+	return false
+}
+
+// WindowTarget returns the tmux target for a window: the default windows by index, others by name
+func WindowTarget(sessionName, targetWindow string) string {
+	switch {
+	case isAgentWindow(targetWindow):
+		return sessionName + ":2"
+	case targetWindow == "" || targetWindow == "terminal":
+		return sessionName + ":1"
+	default:
+		return sessionName + ":" + targetWindow
+	}
+}
@@ -0,0 +1,88 @@
+package session
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/andrew-bierman/jean-tui/internal/branding"
+)
+
+// SessionMeta is what jean records about a session in tmux user options,
+// so the repository, branch and worktree don't have to be guessed from the session name
+type SessionMeta struct {
+	Repo     string // Main repository path
+	Branch   string
+	Worktree string // Worktree path
+	Agent    string // Agent running in the agent window
+}
+
+// metaOption returns the tmux user option a field is stored in (e.g. @jean_branch)
+func metaOption(field string) string {
+	return "@" + strings.ReplaceAll(branding.CLIName, "-", "_") + "_" + field
+}
+
+// listSeparator separates the fields of List's tmux output (ASCII unit separator)
+const listSeparator = "\x1f"
+
+// metaFields lists the user options in the order they are read back by List
+var metaFields = []string{"repo", "branch", "worktree", "agent"}
+
+// values returns the fields in metaFields order
+func (meta SessionMeta) values() []string {
+	return []string{meta.Repo, meta.Branch, meta.Worktree, meta.Agent}
+}
+
+// TagSession stores session metadata in tmux user options. Empty fields are left unchanged.
+func (m *Manager) TagSession(sessionName string, meta SessionMeta) error {
+	for i, value := range meta.values() {
+		if value == "" {
+			continue
+		}
+		if output, err := exec.Command("tmux", "set-option", "-t", sessionName, metaOption(metaFields[i]), value).CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to tag session: %s", strings.TrimSpace(string(output)))
+		}
+	}
+	return nil
+}
+
+// worktreeMeta derives the metadata of a session from its worktree (agent is left empty)
+func worktreeMeta(path string) SessionMeta {
+	meta := SessionMeta{Worktree: path}
+	if repoPath, branch, err := worktreeRepo(path); err == nil {
+		meta.Repo = repoPath
+		if branch != "HEAD" {
+			meta.Branch = branch
+		}
+	}
+	return meta
+}
+
+// tagNewSession tags a session jean just created (best effort: untagged sessions still work)
+func (m *Manager) tagNewSession(sessionName, path, agent string) {
+	meta := worktreeMeta(path)
+	meta.Agent = agent
+	_ = m.TagSession(sessionName, meta)
+}
+
+// MigrateSessions tags sessions created by older versions of jean, deriving the metadata from
+// their directory. Returns the number of sessions tagged.
+func (m *Manager) MigrateSessions() (int, error) {
+	sessions, err := m.List("")
+	if err != nil {
+		return 0, err
+	}
+	migrated := 0
+	for _, sess := range sessions {
+		if sess.Worktree != "" || sess.Path == "" {
+			continue
+		}
+		// The agent is left unknown: it may be configured per worktree
+		meta := worktreeMeta(sess.Path)
+		if err := m.TagSession(sess.Name, meta); err != nil {
+			return migrated, err
+		}
+		migrated++
+	}
+	return migrated, nil
+}
@@ -0,0 +1,89 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/andrew-bierman/jean-tui/config"
+	"github.com/andrew-bierman/jean-tui/internal/branding"
+)
+
+// Alert describes an agent event worth notifying about
+type Alert struct {
+	Title   string
+	Message string
+	Branch  string
+	Session string
+}
+
+// clientTTYs returns the terminals of all attached tmux clients
+func clientTTYs() []string {
+	output, err := exec.Command("tmux", "list-clients", "-F", "#{client_tty}").Output()
+	if err != nil {
+		return nil
+	}
+	return strings.Fields(string(output))
+}
+
+// CurrentTTY returns the terminal device of the current process ("" if not a terminal)
+func CurrentTTY() string {
+	cmd := exec.Command("tty")
+	cmd.Stdin = os.Stdin
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// Notify delivers an alert through every configured channel.
+// Bell and desktop escape sequences are written straight to the terminals (tmux clients plus
+// extraTTYs), so they reach the user whichever session they are attached to.
+func Notify(cfg config.NotificationConfig, alert Alert, extraTTYs []string) error {
+	var sequence strings.Builder
+	if cfg.Bell {
+		sequence.WriteString("\a")
+	}
+	if cfg.Desktop {
+		// OSC 9 (iTerm2, WezTerm, Windows Terminal) and OSC 777 (rxvt, foot, Ghostty, VTE)
+		fmt.Fprintf(&sequence, "\x1b]9;%s: %s\a", alert.Title, alert.Message)
+		fmt.Fprintf(&sequence, "\x1b]777;notify;%s;%s\a", alert.Title, alert.Message)
+	}
+
+	if sequence.Len() > 0 {
+		seen := make(map[string]bool)
+		for _, tty := range append(clientTTYs(), extraTTYs...) {
+			if tty == "" || seen[tty] {
+				continue
+			}
+			seen[tty] = true
+			if f, err := os.OpenFile(tty, os.O_WRONLY, 0); err == nil {
+				f.WriteString(sequence.String())
+				f.Close()
+			}
+		}
+	}
+
+	if cfg.TmuxMessage {
+		for _, tty := range clientTTYs() {
+			exec.Command("tmux", "display-message", "-c", tty, fmt.Sprintf("%s: %s", alert.Title, alert.Message)).Run()
+		}
+	}
+
+	if cfg.Command != "" {
+		cmd := exec.Command("sh", "-c", cfg.Command)
+		cmd.Env = append(os.Environ(),
+			branding.GetEnvVar("NOTIFY_TITLE")+"="+alert.Title,
+			branding.GetEnvVar("NOTIFY_MESSAGE")+"="+alert.Message,
+			branding.GetEnvVar("BRANCH")+"="+alert.Branch,
+			branding.GetEnvVar("SESSION")+"="+alert.Session,
+		)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("notification command failed: %s", string(output))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,195 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// OrphanKind describes why a session no longer matches a worktree
+type OrphanKind int
+
+const (
+	NotOrphaned       OrphanKind = iota // The session belongs to a worktree
+	OrphanMissingPath                   // The session's directory was deleted
+	OrphanNotWorktree                   // The directory exists but isn't a worktree (anymore)
+	OrphanRenamed                       // The worktree's branch was renamed, so the session name is outdated
+	OrphanUnknown                       // The directory couldn't be checked (see Orphan.Err); never pruned
+)
+
+// String returns a short description of the kind
+func (k OrphanKind) String() string {
+	switch k {
+	case OrphanMissingPath:
+		return "directory deleted"
+	case OrphanNotWorktree:
+		return "not a worktree"
+	case OrphanRenamed:
+		return "branch renamed"
+	case OrphanUnknown:
+		return "couldn't be checked"
+	default:
+		return ""
+	}
+}
+
+// Orphan is a session that no longer matches a worktree
+type Orphan struct {
+	Session      Session
+	Kind         OrphanKind
+	Branch       string // Branch now checked out in the session's directory (OrphanRenamed)
+	ExpectedName string // Session name the worktree expects (OrphanRenamed)
+	Err          error  // Why the directory couldn't be checked (OrphanUnknown)
+}
+
+// Prunable reports whether the session can safely be killed: its worktree is known to be gone
+func (o Orphan) Prunable() bool {
+	return o.Kind == OrphanMissingPath || o.Kind == OrphanNotWorktree
+}
+
+// CheckOrphan cross-checks a session against the worktree in its directory
+func (m *Manager) CheckOrphan(sess Session) Orphan {
+	orphan := Orphan{Session: sess}
+
+	path := sess.Worktree
+	if path == "" {
+		path = sess.Path
+	}
+	if _, err := os.Stat(path); err != nil {
+		orphan.Kind = OrphanMissingPath
+		if !os.IsNotExist(err) {
+			orphan.Kind = OrphanUnknown
+			orphan.Err = err
+		}
+		return orphan
+	}
+
+	output, err := exec.Command("git", "-C", path, "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		// Only git saying so means the directory isn't a worktree; a missing git,
+		// permission problems or a corrupt repository are reported as unknown
+		if isNotRepository(err) {
+			orphan.Kind = OrphanNotWorktree
+		} else {
+			orphan.Kind = OrphanUnknown
+			orphan.Err = fmt.Errorf("failed to check %s: %w", path, err)
+		}
+		return orphan
+	}
+	if !samePath(strings.TrimSpace(string(output)), path) {
+		orphan.Kind = OrphanNotWorktree
+		return orphan
+	}
+
+	repoPath, branch, err := worktreeRepo(path)
+	if err != nil || branch == "HEAD" {
+		return orphan // Detached HEAD: nothing to compare the name against
+	}
+	if expected := m.SanitizeName(filepath.Base(repoPath), branch); expected != sess.Name {
+		orphan.Kind = OrphanRenamed
+		orphan.Branch = branch
+		orphan.ExpectedName = expected
+	}
+	return orphan
+}
+
+// isNotRepository reports whether git failed because the directory isn't inside a repository
+func isNotRepository(err error) bool {
+	exitErr, ok := err.(*exec.ExitError)
+	return ok && exitErr.ExitCode() == 128 && strings.Contains(string(exitErr.Stderr), "not a git repository")
+}
+
+// samePath reports whether two paths refer to the same directory (resolving symlinks)
+func samePath(a, b string) bool {
+	if resolved, err := filepath.EvalSymlinks(a); err == nil {
+		a = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(b); err == nil {
+		b = resolved
+	}
+	return filepath.Clean(a) == filepath.Clean(b)
+}
+
+// FindOrphans returns the sessions (of a repository, or all if repoPath is "") that don't match a worktree
+func (m *Manager) FindOrphans(repoPath string) ([]Orphan, error) {
+	sessions, err := m.List(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	var orphans []Orphan
+	for _, sess := range sessions {
+		if orphan := m.CheckOrphan(sess); orphan.Kind != NotOrphaned {
+			orphans = append(orphans, orphan)
+		}
+	}
+	return orphans, nil
+}
+
+// Reattach hands an orphaned session over to a worktree: it gets the worktree's session name
+// and directory (for new windows; running programs keep their directory)
+func (m *Manager) Reattach(sessionName, newName, path string) error {
+	if sessionName != newName {
+		if m.SessionExists(newName) {
+			return fmt.Errorf("session %s already exists", newName)
+		}
+		if output, err := exec.Command("tmux", "rename-session", "-t", sessionName, newName).CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to rename session: %s", strings.TrimSpace(string(output)))
+		}
+	}
+
+	// attach-session -c is the only way to change a session's directory. tmux applies it before
+	// opening the client terminal, so the attach itself failing here (no terminal) is expected.
+	_ = exec.Command("tmux", "attach-session", "-t", newName, "-c", path).Run()
+	output, err := exec.Command("tmux", "display-message", "-p", "-t", newName, "#{session_path}").Output()
+	if err != nil || !samePath(strings.TrimSpace(string(output)), path) {
+		return fmt.Errorf("renamed to %s but failed to change its directory", newName)
+	}
+	return m.TagSession(newName, worktreeMeta(path))
+}
+
+// PruneOrphans kills sessions whose worktree is gone and renames sessions of renamed branches.
+// With dryRun nothing is changed. Returns what was (or would be) killed and renamed ("old -> new").
+func (m *Manager) PruneOrphans(repoPath string, dryRun bool) (killed, renamed []string, err error) {
+	orphans, err := m.FindOrphans(repoPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var failures []string
+	for _, orphan := range orphans {
+		name := orphan.Session.Name
+		if orphan.Kind == OrphanUnknown {
+			// Never kill a session whose worktree might still exist
+			failures = append(failures, fmt.Sprintf("%s: %v", name, orphan.Err))
+			continue
+		}
+		if orphan.Kind == OrphanRenamed {
+			if m.SessionExists(orphan.ExpectedName) {
+				continue // The worktree already has a session of its own, leave both alone
+			}
+			if !dryRun {
+				if err := m.RenameSession(name, orphan.ExpectedName); err != nil {
+					failures = append(failures, fmt.Sprintf("%s: %v", name, err))
+					continue
+				}
+				_ = m.TagSession(orphan.ExpectedName, SessionMeta{Branch: orphan.Branch})
+			}
+			renamed = append(renamed, name+" -> "+orphan.ExpectedName)
+			continue
+		}
+		if !dryRun {
+			if err := m.Kill(name); err != nil {
+				failures = append(failures, fmt.Sprintf("%s: %v", name, err))
+				continue
+			}
+		}
+		killed = append(killed, name)
+	}
+
+	if len(failures) > 0 {
+		return killed, renamed, fmt.Errorf("failed to prune %s", strings.Join(failures, "; "))
+	}
+	return killed, renamed, nil
+}
@@ -0,0 +1,64 @@
+package session
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// TestCheckOrphan tests how sessions are matched against the worktree in their directory
+func TestCheckOrphan(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	repo := filepath.Join(t.TempDir(), "repo")
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main", repo},
+		{"-C", repo, "-c", "user.email=test@example.com", "-c", "user.name=Test", "commit", "-q", "--allow-empty", "-m", "Initial commit"},
+	} {
+		if output, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s", args, output)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(repo, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	plain := t.TempDir()
+	m := NewManager()
+	current := m.SanitizeName("repo", "main")
+
+	tests := []struct {
+		name    string
+		sess    Session
+		noGit   bool // Run without git on the PATH
+		want    OrphanKind
+		prune   bool
+		wantErr bool
+	}{
+		{"worktree", Session{Name: current, Path: repo}, false, NotOrphaned, false, false},
+		{"renamed branch", Session{Name: m.SanitizeName("repo", "old"), Path: repo}, false, OrphanRenamed, false, false},
+		{"directory deleted", Session{Name: current, Path: filepath.Join(plain, "gone")}, false, OrphanMissingPath, true, false},
+		{"not a repository", Session{Name: current, Path: plain}, false, OrphanNotWorktree, true, false},
+		{"subdirectory of a repository", Session{Name: current, Path: filepath.Join(repo, "sub")}, false, OrphanNotWorktree, true, false},
+		{"git unavailable", Session{Name: current, Path: repo}, true, OrphanUnknown, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.noGit {
+				t.Setenv("PATH", t.TempDir())
+			}
+			orphan := m.CheckOrphan(tt.sess)
+			if orphan.Kind != tt.want {
+				t.Errorf("Expected %v, got %v (%v)", tt.want, orphan.Kind, orphan.Err)
+			}
+			if orphan.Prunable() != tt.prune {
+				t.Errorf("Expected prunable %v, got %v", tt.prune, orphan.Prunable())
+			}
+			if (orphan.Err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, orphan.Err)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package session
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/andrew-bierman/jean-tui/internal/branding"
+)
+
+// pasteSettleDelay gives the agent time to take in a pasted prompt before it is submitted
+const pasteSettleDelay = 100 * time.Millisecond
+
+// SendPrompt types a prompt into the agent window (window 2) of a session and submits it,
+// without attaching. Multi-line prompts are pasted (bracketed) so their newlines don't submit early.
+func (m *Manager) SendPrompt(sessionName, prompt string) error {
+	prompt = strings.TrimRight(prompt, "\n")
+	if strings.TrimSpace(prompt) == "" {
+		return fmt.Errorf("prompt is empty")
+	}
+	if !m.SessionExists(sessionName) {
+		return fmt.Errorf("session %s is not running", sessionName)
+	}
+	target := sessionName + ":2"
+
+	if strings.Contains(prompt, "\n") {
+		buffer := branding.CLIName + "-prompt"
+		load := exec.Command("tmux", "load-buffer", "-b", buffer, "-")
+		load.Stdin = strings.NewReader(prompt)
+		if output, err := load.CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to load prompt: %s", strings.TrimSpace(string(output)))
+		}
+		if output, err := exec.Command("tmux", "paste-buffer", "-p", "-d", "-b", buffer, "-t", target).CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to paste prompt: %s", strings.TrimSpace(string(output)))
+		}
+		time.Sleep(pasteSettleDelay)
+	} else if output, err := exec.Command("tmux", "send-keys", "-t", target, "-l", "--", prompt).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to type prompt: %s", strings.TrimSpace(string(output)))
+	}
+
+	if output, err := exec.Command("tmux", "send-keys", "-t", target, "Enter").CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to submit prompt: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// usageSampleInterval is how long CPU time is measured for to compute CPU usage
+const usageSampleInterval = 250 * time.Millisecond
+
+// clockTicks is the kernel's USER_HZ, the unit of CPU times in /proc (100 on all common Linux builds)
+const clockTicks = 100
+
+// ProcessInfo describes a process running in a session
+type ProcessInfo struct {
+	PID     int
+	PPID    int
+	Command string
+	CPU     float64 // Percent of one core
+	RSS     int64   // Resident memory in bytes
+}
+
+// SessionUsage summarizes the processes running under a session's panes
+type SessionUsage struct {
+	Session   string
+	Processes []ProcessInfo // Sorted by CPU, then memory
+	CPU       float64
+	RSS       int64
+}
+
+// procStat is what is read from /proc/<pid>/stat
+type procStat struct {
+	ppid  int
+	comm  string
+	ticks int64 // utime + stime
+	rss   int64 // Bytes
+}
+
+// readProcStats reads every process from /proc (nil if /proc isn't available, e.g. on macOS)
+func readProcStats() map[int]procStat {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil
+	}
+	pageSize := int64(os.Getpagesize())
+	stats := make(map[int]procStat)
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join("/proc", entry.Name(), "stat"))
+		if err != nil {
+			continue
+		}
+		if stat, ok := parseProcStat(string(data), pageSize); ok {
+			stats[pid] = stat
+		}
+	}
+	return stats
+}
+
+// parseProcStat parses a /proc/<pid>/stat line (rss is converted from pages to bytes)
+func parseProcStat(line string, pageSize int64) (procStat, bool) {
+	// The command is in parentheses and may contain spaces and ')', the other fields follow it
+	open, end := strings.IndexByte(line, '('), strings.LastIndexByte(line, ')')
+	if open < 0 || end < open {
+		return procStat{}, false
+	}
+	fields := strings.Fields(line[end+1:])
+	if len(fields) < 22 {
+		return procStat{}, false
+	}
+	ppid, _ := strconv.Atoi(fields[1])
+	utime, _ := strconv.ParseInt(fields[11], 10, 64)
+	stime, _ := strconv.ParseInt(fields[12], 10, 64)
+	rss, _ := strconv.ParseInt(fields[21], 10, 64)
+	return procStat{ppid: ppid, comm: line[open+1 : end], ticks: utime + stime, rss: rss * pageSize}, true
+}
+
+// psProcesses lists processes with ps (used where /proc isn't available)
+func psProcesses() map[int]ProcessInfo {
+	output, err := exec.Command("ps", "-A", "-o", "pid=,ppid=,pcpu=,rss=,comm=").Output()
+	if err != nil {
+		return nil
+	}
+	processes := make(map[int]ProcessInfo)
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 5 {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		ppid, _ := strconv.Atoi(fields[1])
+		cpu, _ := strconv.ParseFloat(fields[2], 64)
+		rss, _ := strconv.ParseInt(fields[3], 10, 64)
+		processes[pid] = ProcessInfo{PID: pid, PPID: ppid, Command: strings.Join(fields[4:], " "), CPU: cpu, RSS: rss * 1024}
+	}
+	return processes
+}
+
+// sampleProcesses returns all processes with their CPU usage over a short interval
+func sampleProcesses() map[int]ProcessInfo {
+	before := readProcStats()
+	if before == nil {
+		return psProcesses()
+	}
+	start := time.Now()
+	time.Sleep(usageSampleInterval)
+	after := readProcStats()
+	elapsed := time.Since(start).Seconds()
+
+	processes := make(map[int]ProcessInfo, len(after))
+	for pid, stat := range after {
+		cpu := 0.0
+		if previous, ok := before[pid]; ok && elapsed > 0 {
+			cpu = float64(stat.ticks-previous.ticks) / clockTicks / elapsed * 100
+		}
+		processes[pid] = ProcessInfo{PID: pid, PPID: stat.ppid, Command: processCommand(pid, stat.comm), CPU: cpu, RSS: stat.rss}
+	}
+	return processes
+}
+
+// processCommand returns the command line of a process, or its name if that can't be read
+func processCommand(pid int, comm string) string {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+	if err != nil || len(data) == 0 {
+		return comm
+	}
+	return strings.TrimSpace(strings.ReplaceAll(string(data), "\x00", " "))
+}
+
+// panePIDs returns the PIDs of the pane processes of every tmux session
+func panePIDs() map[string][]int {
+	output, err := exec.Command("tmux", "list-panes", "-a", "-F", "#{session_name}"+listSeparator+"#{pane_pid}").Output()
+	if err != nil {
+		return nil
+	}
+	pids := make(map[string][]int)
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		name, pidStr, ok := strings.Cut(line, listSeparator)
+		if !ok {
+			continue
+		}
+		if pid, err := strconv.Atoi(pidStr); err == nil {
+			pids[name] = append(pids[name], pid)
+		}
+	}
+	return pids
+}
+
+// SessionUsages collects the process trees under the panes of the given sessions and sums
+// their CPU and memory. Takes about usageSampleInterval to measure CPU usage.
+func (m *Manager) SessionUsages(sessionNames []string) map[string]SessionUsage {
+	panes := panePIDs()
+	processes := sampleProcesses()
+
+	children := make(map[int][]int)
+	for pid, proc := range processes {
+		children[proc.PPID] = append(children[proc.PPID], pid)
+	}
+
+	usages := make(map[string]SessionUsage)
+	for _, name := range sessionNames {
+		usage := SessionUsage{Session: name}
+		queue := append([]int{}, panes[name]...)
+		seen := make(map[int]bool)
+		for len(queue) > 0 {
+			pid := queue[0]
+			queue = queue[1:]
+			if seen[pid] {
+				continue
+			}
+			seen[pid] = true
+			if proc, ok := processes[pid]; ok {
+				usage.Processes = append(usage.Processes, proc)
+				usage.CPU += proc.CPU
+				usage.RSS += proc.RSS
+			}
+			queue = append(queue, children[pid]...)
+		}
+		sort.Slice(usage.Processes, func(i, j int) bool {
+			a, b := usage.Processes[i], usage.Processes[j]
+			if a.CPU != b.CPU {
+				return a.CPU > b.CPU
+			}
+			return a.RSS > b.RSS
+		})
+		usages[name] = usage
+	}
+	return usages
+}
+
+// Heaviest returns the session using the most CPU (memory breaks ties), or false if there are none
+func Heaviest(usages map[string]SessionUsage) (SessionUsage, bool) {
+	var heaviest SessionUsage
+	found := false
+	for _, usage := range usages {
+		if len(usage.Processes) == 0 {
+			continue
+		}
+		if !found || usage.CPU > heaviest.CPU || (usage.CPU == heaviest.CPU && usage.RSS > heaviest.RSS) {
+			heaviest = usage
+			found = true
+		}
+	}
+	return heaviest, found
+}
+
+// KillProcess asks a process to terminate (SIGTERM)
+func KillProcess(pid int) error {
+	if err := syscall.Kill(pid, syscall.SIGTERM); err != nil {
+		return fmt.Errorf("failed to kill process %d: %w", pid, err)
+	}
+	return nil
+}
+
+// FormatBytes formats a memory size for display (e.g. "340 MB")
+func FormatBytes(bytes int64) string {
+	switch {
+	case bytes >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(bytes)/(1<<30))
+	case bytes >= 1<<20:
+		return fmt.Sprintf("%d MB", bytes>>20)
+	default:
+		return fmt.Sprintf("%d KB", bytes>>10)
+	}
+}
@@ -0,0 +1,34 @@
+package session
+
+import "testing"
+
+// TestParseProcStat tests parsing /proc/<pid>/stat lines, including commands with spaces and parentheses
+func TestParseProcStat(t *testing.T) {
+	// Fields after the command: state ppid pgrp session tty tpgid flags minflt cminflt majflt cmajflt
+	// utime stime cutime cstime priority nice threads itrealvalue starttime vsize rss ...
+	const rest = " S 4321 100 100 0 -1 4194304 81 0 0 0 150 50 7 3 20 0 1 0 504333 2703360 306 18446744073709551615 0 0 0"
+	tests := []struct {
+		name string
+		line string
+		want procStat
+		ok   bool
+	}{
+		{"plain", "1234 (node)" + rest + "\n", procStat{ppid: 4321, comm: "node", ticks: 200, rss: 306 * 4096}, true},
+		{"spaces", "1234 (Web Content)" + rest, procStat{ppid: 4321, comm: "Web Content", ticks: 200, rss: 306 * 4096}, true},
+		{"closing parenthesis", "1234 (a) b)" + rest, procStat{ppid: 4321, comm: "a) b", ticks: 200, rss: 306 * 4096}, true},
+		{"looks like fields", "1234 (x) R 1 2 3)" + rest, procStat{ppid: 4321, comm: "x) R 1 2 3", ticks: 200, rss: 306 * 4096}, true},
+		{"empty command", "1234 ()" + rest, procStat{ppid: 4321, comm: "", ticks: 200, rss: 306 * 4096}, true},
+		{"no parentheses", "1234 node" + rest, procStat{}, false},
+		{"truncated", "1234 (node) S 4321 100", procStat{}, false},
+		{"empty", "", procStat{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseProcStat(tt.line, 4096)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("Expected %+v (%v), got %+v (%v)", tt.want, tt.ok, got, ok)
+			}
+		})
+	}
+}
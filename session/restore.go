@@ -0,0 +1,317 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andrew-bierman/jean-tui/config"
+	"github.com/andrew-bierman/jean-tui/internal/branding"
+)
+
+// SnapshotInterval is how often running sessions are recorded for restoring
+const SnapshotInterval = 30 * time.Second
+
+// SessionSnapshot records a session so it can be recreated after a tmux server restart or reboot
+type SessionSnapshot struct {
+	Name         string           `json:"name"`
+	Path         string           `json:"path"`
+	Windows      []SnapshotWindow `json:"windows"`
+	AgentRunning bool             `json:"agent_running"` // Whether the agent in window 2 was running
+	SavedAt      time.Time        `json:"saved_at"`
+}
+
+// SnapshotWindow records a window of a session
+type SnapshotWindow struct {
+	Index  int            `json:"index"`
+	Name   string         `json:"name"`
+	Dir    string         `json:"dir"`              // Directory of the first pane
+	Layout string         `json:"layout,omitempty"` // tmux window_layout, to put split panes back in place
+	Panes  []SnapshotPane `json:"panes,omitempty"`
+}
+
+// SnapshotPane records a pane of a window
+type SnapshotPane struct {
+	Index int    `json:"index"`
+	Dir   string `json:"dir"`
+}
+
+// sessionSnapshotFile is the on-disk format of the snapshot
+type sessionSnapshotFile struct {
+	Server   string            `json:"server"` // Identifies the tmux server the sessions were running on
+	Sessions []SessionSnapshot `json:"sessions"`
+}
+
+// snapshotPath returns the path of the session snapshot (~/.config/<cli>/sessions.json)
+func snapshotPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", branding.ConfigDirName, "sessions.json"), nil
+}
+
+// serverID identifies the running tmux server ("" if none is running)
+func serverID() string {
+	output, err := exec.Command("tmux", "display-message", "-p", "#{pid}:#{start_time}").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+func loadSnapshot() sessionSnapshotFile {
+	var snapshot sessionSnapshotFile
+	path, err := snapshotPath()
+	if err != nil {
+		return snapshot
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &snapshot)
+	}
+	return snapshot
+}
+
+func writeSnapshot(snapshot sessionSnapshotFile) error {
+	path, err := snapshotPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode session snapshot: %w", err)
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// snapshotSession records the windows of a running session
+func (m *Manager) snapshotSession(sess Session) SessionSnapshot {
+	snapshot := SessionSnapshot{Name: sess.Name, Path: sess.Path, SavedAt: time.Now()}
+
+	output, err := exec.Command("tmux", "list-panes", "-s", "-t", sess.Name, "-F", "#{window_index}\t#{window_name}\t#{window_layout}\t#{pane_index}\t#{pane_current_path}").Output()
+	if err == nil {
+		for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+			parts := strings.SplitN(line, "\t", 5)
+			if len(parts) != 5 {
+				continue
+			}
+			index, err := strconv.Atoi(parts[0])
+			if err != nil {
+				continue
+			}
+			paneIndex, err := strconv.Atoi(parts[3])
+			if err != nil {
+				continue
+			}
+			// Panes are listed window by window
+			if n := len(snapshot.Windows); n == 0 || snapshot.Windows[n-1].Index != index {
+				snapshot.Windows = append(snapshot.Windows, SnapshotWindow{Index: index, Name: parts[1], Dir: parts[4], Layout: parts[2]})
+			}
+			window := &snapshot.Windows[len(snapshot.Windows)-1]
+			window.Panes = append(window.Panes, SnapshotPane{Index: paneIndex, Dir: parts[4]})
+		}
+	}
+
+	state := m.DetectAgentState(sess.Name, DefaultAgent())
+	snapshot.AgentRunning = state != AgentStateNone && state != AgentStateExited
+	return snapshot
+}
+
+// SaveSessionSnapshot records the running jean sessions. Recorded sessions that aren't running are
+// kept until they're restored or forgotten (killed from jean), unless their worktree is gone.
+func (m *Manager) SaveSessionSnapshot() error {
+	server := serverID()
+	if server == "" {
+		return nil // No tmux server, nothing to record (and keep what was recorded before)
+	}
+
+	sessions, err := m.List("")
+	if err != nil {
+		return err
+	}
+
+	previous := loadSnapshot()
+	snapshot := sessionSnapshotFile{Server: server}
+	running := make(map[string]bool)
+	for _, sess := range sessions {
+		running[sess.Name] = true
+		snapshot.Sessions = append(snapshot.Sessions, m.snapshotSession(sess))
+	}
+	for _, sess := range previous.Sessions {
+		if running[sess.Name] {
+			continue
+		}
+		if _, err := os.Stat(sess.Path); err != nil {
+			continue // The worktree was deleted, so there's nothing to restore
+		}
+		snapshot.Sessions = append(snapshot.Sessions, sess)
+	}
+
+	sort.Slice(snapshot.Sessions, func(i, j int) bool {
+		return snapshot.Sessions[i].Name < snapshot.Sessions[j].Name
+	})
+	return writeSnapshot(snapshot)
+}
+
+// forgetSession removes a session from the snapshot (it was closed on purpose)
+func forgetSession(sessionName string) {
+	snapshot := loadSnapshot()
+	for i, sess := range snapshot.Sessions {
+		if sess.Name == sessionName {
+			snapshot.Sessions = append(snapshot.Sessions[:i], snapshot.Sessions[i+1:]...)
+			writeSnapshot(snapshot)
+			return
+		}
+	}
+}
+
+// ForgetRestorableSessions drops the recorded sessions that aren't running, so they're no longer offered for restoring
+func (m *Manager) ForgetRestorableSessions() error {
+	snapshot := loadSnapshot()
+	var kept []SessionSnapshot
+	for _, sess := range snapshot.Sessions {
+		if m.SessionExists(sess.Name) {
+			kept = append(kept, sess)
+		}
+	}
+	snapshot.Sessions = kept
+	return writeSnapshot(snapshot)
+}
+
+// RestorableSessions returns recorded sessions that aren't running and whose worktree still exists
+func (m *Manager) RestorableSessions() []SessionSnapshot {
+	var restorable []SessionSnapshot
+	for _, sess := range loadSnapshot().Sessions {
+		if m.SessionExists(sess.Name) {
+			continue
+		}
+		if _, err := os.Stat(sess.Path); err != nil {
+			continue
+		}
+		restorable = append(restorable, sess)
+	}
+	return restorable
+}
+
+// worktreeRepo returns the main repository path and the checked out branch of a worktree
+func worktreeRepo(path string) (repoPath, branch string, err error) {
+	branchOutput, err := exec.Command("git", "-C", path, "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get branch: %w", err)
+	}
+	commonDir, err := exec.Command("git", "-C", path, "rev-parse", "--path-format=absolute", "--git-common-dir").Output()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get repository: %w", err)
+	}
+	return filepath.Dir(strings.TrimSpace(string(commonDir))), strings.TrimSpace(string(branchOutput)), nil
+}
+
+// RestoreSession recreates a recorded session: its agents (resuming their conversation),
+// the jean.json layout and any other windows it had. configManager may be nil.
+func (m *Manager) RestoreSession(snapshot SessionSnapshot, configManager *config.Manager) error {
+	opts := SessionOptions{
+		AutoStartAgent: snapshot.AgentRunning,
+		Resume:         true,
+		Agents:         []config.AgentDefinition{DefaultAgent()},
+		Layout:         m.LoadLayout(snapshot.Path),
+	}
+	if repoPath, branch, err := worktreeRepo(snapshot.Path); err == nil && configManager != nil {
+		var agents []config.AgentDefinition
+		for _, name := range configManager.GetWorktreeAgents(repoPath, branch) {
+			if agent, ok := configManager.GetAgent(name); ok {
+				agents = append(agents, agent)
+			}
+		}
+		if len(agents) > 0 {
+			agents[0] = agents[0].WithArgs(configManager.GetAgentArgs(repoPath, branch))
+			opts.Agents = agents
+		}
+		if conv, ok := configManager.GetConversation(repoPath, branch); ok && conv.Agent == opts.Agents[0].Name {
+			opts.ResumeID = conv.ID
+		}
+		opts.Env = config.PortEnv(configManager.GetPorts(repoPath, branch))
+	}
+
+	if err := m.PrepareSession(snapshot.Name, snapshot.Path, opts); err != nil {
+		return err
+	}
+
+	// Recreate the remaining windows (e.g. opened by hand) as shells in their directory
+	windows, err := listWindows(snapshot.Name)
+	if err != nil {
+		return err
+	}
+	for _, window := range snapshot.Windows {
+		if _, exists := windows[window.Name]; exists || isIndexTaken(windows, window.Index) {
+			continue
+		}
+		dir := window.Dir
+		if _, err := os.Stat(dir); err != nil {
+			dir = snapshot.Path
+		}
+		target := fmt.Sprintf("%s:%d", snapshot.Name, window.Index)
+		if output, err := exec.Command("tmux", "new-window", "-d", "-t", target, "-c", dir, "-n", window.Name).CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to restore window %s: %s", window.Name, string(output))
+		}
+		windows[window.Name] = window.Index
+		if err := restorePanes(target, window, snapshot.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restorePanes splits a recreated window into its recorded panes and puts them back in their layout
+func restorePanes(target string, window SnapshotWindow, fallbackDir string) error {
+	if len(window.Panes) < 2 {
+		return nil
+	}
+	last := target
+	for _, pane := range window.Panes[1:] {
+		dir := pane.Dir
+		if _, err := os.Stat(dir); err != nil {
+			dir = fallbackDir
+		}
+		// Splitting the newest pane keeps the panes in their recorded order
+		output, err := exec.Command("tmux", "split-window", "-d", "-t", last, "-c", dir, "-P", "-F", "#{pane_id}").CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("failed to restore a pane of window %s: %s", window.Name, string(output))
+		}
+		last = strings.TrimSpace(string(output))
+	}
+	if window.Layout != "" {
+		// A layout that doesn't fit (e.g. a smaller terminal) still leaves every pane in place
+		_ = exec.Command("tmux", "select-layout", "-t", target, window.Layout).Run()
+	}
+	return nil
+}
+
+// RestoreSessions recreates every restorable session and returns the names of those restored
+func (m *Manager) RestoreSessions(configManager *config.Manager) ([]string, error) {
+	var restored []string
+	var failures []string
+	for _, snapshot := range m.RestorableSessions() {
+		if err := m.RestoreSession(snapshot, configManager); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", snapshot.Name, err))
+			continue
+		}
+		restored = append(restored, snapshot.Name)
+	}
+
+	// Record the restored sessions under the current server
+	_ = m.SaveSessionSnapshot()
+
+	if len(failures) > 0 {
+		return restored, fmt.Errorf("failed to restore %s", strings.Join(failures, "; "))
+	}
+	return restored, nil
+}
@@ -0,0 +1,121 @@
+package session
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/andrew-bierman/jean-tui/internal/branding"
+)
+
+// startTestTmux points tmux at a private server for the test and kills it afterwards
+func startTestTmux(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux is not installed")
+	}
+	t.Setenv("TMUX_TMPDIR", t.TempDir())
+	t.Setenv("TMUX", "")
+	t.Cleanup(func() { exec.Command("tmux", "kill-server").Run() })
+}
+
+// newTestSession starts a detached session in dir
+func newTestSession(t *testing.T, name, dir string) {
+	t.Helper()
+	if output, err := exec.Command("tmux", "new-session", "-d", "-s", name, "-c", dir).CombinedOutput(); err != nil {
+		t.Fatalf("Failed to start session: %s", output)
+	}
+}
+
+// snapshotNames returns the names of the recorded sessions
+func snapshotNames() []string {
+	var names []string
+	for _, sess := range loadSnapshot().Sessions {
+		names = append(names, sess.Name)
+	}
+	return names
+}
+
+// TestSaveSessionSnapshot_KeepsEndedSessions tests that sessions which ended stay restorable
+// on the same server until they're restored or forgotten
+func TestSaveSessionSnapshot_KeepsEndedSessions(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	startTestTmux(t)
+	worktree := t.TempDir()
+	kept := branding.SessionPrefix + "repo-kept"
+	ended := branding.SessionPrefix + "repo-ended"
+	deleted := branding.SessionPrefix + "repo-deleted"
+	deletedDir := t.TempDir()
+	m := NewManager()
+
+	newTestSession(t, kept, worktree)
+	newTestSession(t, ended, worktree)
+	newTestSession(t, deleted, deletedDir)
+	if err := m.SaveSessionSnapshot(); err != nil {
+		t.Fatalf("SaveSessionSnapshot failed: %v", err)
+	}
+
+	exec.Command("tmux", "kill-session", "-t", ended).Run()
+	exec.Command("tmux", "kill-session", "-t", deleted).Run()
+	os.RemoveAll(deletedDir)
+	for i := 0; i < 2; i++ { // Repeated snapshots on the same server keep the ended session
+		if err := m.SaveSessionSnapshot(); err != nil {
+			t.Fatalf("SaveSessionSnapshot failed: %v", err)
+		}
+	}
+	if got := snapshotNames(); len(got) != 2 || got[0] != ended || got[1] != kept {
+		t.Errorf("Expected %s and %s recorded, got %v", ended, kept, got)
+	}
+	if restorable := m.RestorableSessions(); len(restorable) != 1 || restorable[0].Name != ended {
+		t.Errorf("Expected %s to be restorable, got %+v", ended, restorable)
+	}
+
+	if err := m.ForgetRestorableSessions(); err != nil {
+		t.Fatalf("ForgetRestorableSessions failed: %v", err)
+	}
+	if got := snapshotNames(); len(got) != 1 || got[0] != kept {
+		t.Errorf("Expected only %s recorded, got %v", kept, got)
+	}
+
+	// Killing from jean forgets the session
+	if err := m.Kill(kept); err != nil {
+		t.Fatalf("Kill failed: %v", err)
+	}
+	if got := snapshotNames(); len(got) != 0 {
+		t.Errorf("Expected nothing recorded, got %v", got)
+	}
+}
+
+// TestRestoreSession_Panes tests that split windows are restored with a pane per recorded pane
+func TestRestoreSession_Panes(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	startTestTmux(t)
+	worktree := t.TempDir()
+	left, right := t.TempDir(), t.TempDir()
+	name := branding.SessionPrefix + "repo-split"
+	m := NewManager()
+
+	newTestSession(t, name, worktree)
+	for _, args := range [][]string{
+		{"new-window", "-d", "-t", name + ":5", "-n", "split", "-c", left},
+		{"split-window", "-d", "-h", "-t", name + ":5", "-c", right},
+	} {
+		if output, err := exec.Command("tmux", args...).CombinedOutput(); err != nil {
+			t.Fatalf("tmux %v: %s", args, output)
+		}
+	}
+	snapshot := m.snapshotSession(Session{Name: name, Path: worktree})
+	exec.Command("tmux", "kill-session", "-t", name).Run()
+
+	if err := m.RestoreSession(snapshot, nil); err != nil {
+		t.Fatalf("RestoreSession failed: %v", err)
+	}
+	output, err := exec.Command("tmux", "list-panes", "-t", name+":5", "-F", "#{pane_start_path}").Output()
+	if err != nil {
+		t.Fatalf("Expected the split window to be restored: %v", err)
+	}
+	if got := strings.TrimSpace(string(output)); got != left+"\n"+right {
+		t.Errorf("Expected panes in %s and %s, got %q", left, right, got)
+	}
+}
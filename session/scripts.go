@@ -0,0 +1,233 @@
+package session
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/andrew-bierman/jean-tui/internal/branding"
+)
+
+// ScriptStatus is the state of a background script run
+type ScriptStatus int
+
+const (
+	ScriptRunning ScriptStatus = iota // The script is still running
+	ScriptPassed                      // The script exited with status 0
+	ScriptFailed                      // The script exited with a non-zero status or was killed
+)
+
+// String returns a short label for the status
+func (s ScriptStatus) String() string {
+	switch s {
+	case ScriptRunning:
+		return "running"
+	case ScriptPassed:
+		return "passed"
+	default:
+		return "failed"
+	}
+}
+
+// ScriptRun is the latest run of a jean.json script in a worktree
+type ScriptRun struct {
+	Name     string       `json:"name"`
+	Command  string       `json:"command"`
+	PID      int          `json:"pid"`
+	Start    time.Time    `json:"start"`
+	End      time.Time    `json:"-"` // Zero while running
+	ExitCode int          `json:"-"`
+	Status   ScriptStatus `json:"-"`
+	LogPath  string       `json:"-"` // Captured stdout and stderr
+}
+
+// Duration returns how long the script ran (or has been running)
+func (r ScriptRun) Duration() time.Duration {
+	if r.End.IsZero() {
+		return time.Since(r.Start)
+	}
+	if r.End.Before(r.Start) {
+		return 0 // File times can be coarser than the recorded start
+	}
+	return r.End.Sub(r.Start)
+}
+
+// ScriptRunDir returns the directory a session's script runs are stored in (~/.config/<cli>/runs/<session>)
+func ScriptRunDir(sessionName string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", branding.ConfigDirName, "runs", sessionName), nil
+}
+
+// unsafeScriptChars matches characters not kept in script run file names (like SanitizeBranchName)
+var unsafeScriptChars = regexp.MustCompile(`[^a-zA-Z0-9\-_]+`)
+
+// scriptRunStem returns the file name (without extension) of a script's runs. jean.json script names
+// may contain anything, so other characters are replaced and a hash keeps such names apart.
+func scriptRunStem(name string) string {
+	stem := strings.Trim(unsafeScriptChars.ReplaceAllString(name, "-"), "-")
+	if stem == name {
+		return stem
+	}
+	sum := sha1.Sum([]byte(name))
+	return stem + "-" + hex.EncodeToString(sum[:4])
+}
+
+// RunScript starts a script in the background in a worktree, detached from the terminal.
+// Output goes to <name>.log and the exit status to <name>.exit in the session's run directory,
+// so the result survives jean quitting while the script runs. env adds KEY=VALUE pairs (e.g. allocated ports).
+func RunScript(sessionName, worktreePath, repoRoot, name, command string, env []string) (*ScriptRun, error) {
+	if run, err := GetScriptRun(sessionName, name); err == nil && run.Status == ScriptRunning {
+		return nil, fmt.Errorf("script %q is already running", name)
+	}
+
+	dir, err := ScriptRunDir(sessionName)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create run directory: %w", err)
+	}
+
+	stem := scriptRunStem(name)
+	logPath := filepath.Join(dir, stem+".log")
+	exitPath := filepath.Join(dir, stem+".exit")
+	os.Remove(exitPath)
+
+	logFile, err := os.Create(logPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create log file: %w", err)
+	}
+	defer logFile.Close()
+
+	// The outer shell records the script's exit status once it finishes
+	cmd := exec.Command("sh", "-c", `sh -c "$1"; echo $? > "$2"`, branding.CLIName+"-script", command, exitPath)
+	cmd.Dir = worktreePath
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("%s=%s", branding.GetEnvVar("WORKSPACE_PATH"), worktreePath),
+		fmt.Sprintf("%s=%s", branding.GetEnvVar("ROOT_PATH"), repoRoot),
+	)
+	cmd.Env = append(cmd.Env, env...)
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start script: %w", err)
+	}
+	// Reap the process so it doesn't linger as a zombie that looks alive
+	go cmd.Wait()
+
+	run := &ScriptRun{
+		Name:    name,
+		Command: command,
+		PID:     cmd.Process.Pid,
+		Start:   start,
+		Status:  ScriptRunning,
+		LogPath: logPath,
+	}
+	data, err := json.Marshal(run)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode run: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, stem+".json"), data, 0600); err != nil {
+		return nil, fmt.Errorf("failed to save run: %w", err)
+	}
+	return run, nil
+}
+
+// GetScriptRun loads the latest run of a script and determines its status
+func GetScriptRun(sessionName, name string) (*ScriptRun, error) {
+	dir, err := ScriptRunDir(sessionName)
+	if err != nil {
+		return nil, err
+	}
+	return loadScriptRun(dir, scriptRunStem(name))
+}
+
+// loadScriptRun loads a run from the files <stem>.json, .log and .exit in a run directory
+func loadScriptRun(dir, stem string) (*ScriptRun, error) {
+	data, err := os.ReadFile(filepath.Join(dir, stem+".json"))
+	if err != nil {
+		return nil, err
+	}
+	var run ScriptRun
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("failed to parse run: %w", err)
+	}
+	run.LogPath = filepath.Join(dir, stem+".log")
+
+	exitPath := filepath.Join(dir, stem+".exit")
+	if exitData, err := os.ReadFile(exitPath); err == nil {
+		run.ExitCode, _ = strconv.Atoi(strings.TrimSpace(string(exitData)))
+		if info, err := os.Stat(exitPath); err == nil {
+			run.End = info.ModTime()
+		}
+		run.Status = ScriptPassed
+		if run.ExitCode != 0 {
+			run.Status = ScriptFailed
+		}
+		return &run, nil
+	}
+
+	if syscall.Kill(run.PID, 0) == nil {
+		run.Status = ScriptRunning
+		return &run, nil
+	}
+
+	// The process is gone without recording a status (killed, or the machine restarted)
+	run.Status = ScriptFailed
+	run.ExitCode = -1
+	if info, err := os.Stat(run.LogPath); err == nil {
+		run.End = info.ModTime()
+	}
+	return &run, nil
+}
+
+// ListScriptRuns returns the latest run of every script started in a session's worktree, sorted by name
+func ListScriptRuns(sessionName string) []ScriptRun {
+	dir, err := ScriptRunDir(sessionName)
+	if err != nil {
+		return nil
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var runs []ScriptRun
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		if run, err := loadScriptRun(dir, strings.TrimSuffix(file.Name(), ".json")); err == nil {
+			runs = append(runs, *run)
+		}
+	}
+
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].Name < runs[j].Name
+	})
+	return runs
+}
+
+// RemoveScriptRuns deletes the run history of a session (e.g. when its worktree is deleted)
+func RemoveScriptRuns(sessionName string) error {
+	dir, err := ScriptRunDir(sessionName)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
@@ -0,0 +1,87 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestScriptRunStem tests the file names of script runs
+func TestScriptRunStem(t *testing.T) {
+	tests := []struct {
+		name       string
+		script     string
+		want       string // Expected stem, or its prefix when hashed
+		wantHashed bool
+	}{
+		{"plain", "test", "test", false},
+		{"dashes and underscores", "lint_fix-all", "lint_fix-all", false},
+		{"path", "../../.ssh/config", "ssh-config-", true},
+		{"colon", "build:prod", "build-prod-", true},
+		{"spaces", "run tests", "run-tests-", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := scriptRunStem(tt.script)
+			if !tt.wantHashed && got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+			if tt.wantHashed && (!strings.HasPrefix(got, tt.want) || len(got) != len(tt.want)+8) {
+				t.Errorf("Expected %q followed by a hash, got %q", tt.want, got)
+			}
+			if strings.ContainsAny(got, `/\.: `) {
+				t.Errorf("Unsafe characters in %q", got)
+			}
+		})
+	}
+
+	if scriptRunStem("build:prod") == scriptRunStem("build-prod") || scriptRunStem("build:prod") == scriptRunStem("build/prod") {
+		t.Error("Expected different stems for names that sanitize the same")
+	}
+}
+
+// TestRunScript_UnsafeName tests that a script name can't place run files outside the run directory
+func TestRunScript_UnsafeName(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	worktree := t.TempDir()
+	const name = "../../escape"
+
+	run, err := RunScript("jean-repo-main", worktree, worktree, name, "echo done", nil)
+	if err != nil {
+		t.Fatalf("RunScript failed: %v", err)
+	}
+	dir, _ := ScriptRunDir("jean-repo-main")
+	if filepath.Dir(run.LogPath) != dir {
+		t.Errorf("Expected the log in %s, got %s", dir, run.LogPath)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		run, err = GetScriptRun("jean-repo-main", name)
+		if err != nil {
+			t.Fatalf("GetScriptRun failed: %v", err)
+		}
+		if run.Status != ScriptRunning || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if run.Status != ScriptPassed || run.Name != name {
+		t.Errorf("Expected %s to pass, got %s %v", name, run.Name, run.Status)
+	}
+	if output, _ := os.ReadFile(run.LogPath); strings.TrimSpace(string(output)) != "done" {
+		t.Errorf("Expected output done, got %q", output)
+	}
+
+	runs := ListScriptRuns("jean-repo-main")
+	if len(runs) != 1 || runs[0].Name != name {
+		t.Errorf("Expected one run of %s, got %+v", name, runs)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(home, ".config", "*", "escape*")); len(matches) > 0 {
+		t.Errorf("Run files escaped the run directory: %v", matches)
+	}
+}
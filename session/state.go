@@ -0,0 +1,142 @@
+package session
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andrew-bierman/jean-tui/config"
+)
+
+// AgentState is the classified state of an agent window
+type AgentState int
+
+const (
+	AgentStateNone    AgentState = iota // No agent window (or state unknown)
+	AgentStateBusy                      // The agent is working
+	AgentStateWaiting                   // The agent waits for input or a permission decision
+	AgentStateIdle                      // The agent is running but not doing anything
+	AgentStateExited                    // The agent process has ended
+)
+
+// busyActivityWindow is how recent pane output must be to count as busy when no pattern matches
+const busyActivityWindow = 3 * time.Second
+
+// stateScanLines is how many lines from the bottom of the pane are matched against patterns
+const stateScanLines = 15
+
+// shellCommands are foreground commands that mean the agent is no longer running in a shell pane
+var shellCommands = map[string]bool{"bash": true, "zsh": true, "fish": true, "sh": true, "dash": true}
+
+// String returns a short label for the state
+func (s AgentState) String() string {
+	switch s {
+	case AgentStateBusy:
+		return "busy"
+	case AgentStateWaiting:
+		return "waiting"
+	case AgentStateIdle:
+		return "idle"
+	case AgentStateExited:
+		return "exited"
+	default:
+		return ""
+	}
+}
+
+// agentPatterns caches the compiled state patterns of the agent definitions (nil for invalid ones),
+// as agent windows are classified on every activity check
+var agentPatterns = struct {
+	sync.Mutex
+	compiled map[string]*regexp.Regexp
+}{compiled: make(map[string]*regexp.Regexp)}
+
+// compilePattern returns the compiled pattern, or nil if it is invalid
+func compilePattern(pattern string) *regexp.Regexp {
+	agentPatterns.Lock()
+	defer agentPatterns.Unlock()
+	re, ok := agentPatterns.compiled[pattern]
+	if !ok {
+		re, _ = regexp.Compile(pattern)
+		agentPatterns.compiled[pattern] = re
+	}
+	return re
+}
+
+// matchesAny reports whether any of the patterns matches the text (invalid patterns are ignored)
+func matchesAny(patterns []string, text string) bool {
+	for _, pattern := range patterns {
+		if re := compilePattern(pattern); re != nil && re.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClassifyAgentOutput classifies captured pane output using the agent's patterns.
+// Waiting takes precedence over busy; recentActivity is the fallback for busy when nothing matches.
+func ClassifyAgentOutput(agent config.AgentDefinition, output string, recentActivity bool) AgentState {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(lines) > stateScanLines {
+		lines = lines[len(lines)-stateScanLines:]
+	}
+	tail := strings.Join(lines, "\n")
+
+	switch {
+	case matchesAny(agent.WaitingPatterns, tail):
+		return AgentStateWaiting
+	case matchesAny(agent.BusyPatterns, tail):
+		return AgentStateBusy
+	case recentActivity:
+		return AgentStateBusy
+	default:
+		return AgentStateIdle
+	}
+}
+
+// DetectAgentState captures the agent window (window 2) of a session and classifies it
+func (m *Manager) DetectAgentState(sessionName string, agent config.AgentDefinition) AgentState {
+	target := sessionName + ":2"
+
+	info, err := exec.Command("tmux", "display-message", "-p", "-t", target, "#{pane_dead}\t#{pane_current_command}\t#{window_activity}\t#{pane_start_command}").Output()
+	if err != nil {
+		return AgentStateNone
+	}
+	fields := strings.SplitN(strings.TrimRight(string(info), "\n"), "\t", 4)
+	if len(fields) < 4 {
+		return AgentStateNone
+	}
+	if fields[0] == "1" {
+		return AgentStateExited
+	}
+	// A plain shell window showing its shell again means the agent was quit. Windows started with
+	// the agent command run it via `sh -c`, so a shell in the foreground doesn't say anything there.
+	if fields[3] == "" && shellCommands[fields[1]] {
+		return AgentStateExited
+	}
+
+	recentActivity := false
+	if activity, err := strconv.ParseInt(fields[2], 10, 64); err == nil {
+		recentActivity = time.Since(time.Unix(activity, 0)) < busyActivityWindow
+	}
+
+	output, err := exec.Command("tmux", "capture-pane", "-p", "-t", target).Output()
+	if err != nil {
+		return AgentStateNone
+	}
+	return ClassifyAgentOutput(agent, string(output), recentActivity)
+}
+
+// CaptureAgentOutput returns the visible output of a session's agent window (window 2)
+// with colours and other escape sequences preserved
+func (m *Manager) CaptureAgentOutput(sessionName string) (string, error) {
+	output, err := exec.Command("tmux", "capture-pane", "-p", "-e", "-J", "-t", sessionName+":2").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to capture agent window: %w", err)
+	}
+	return string(output), nil
+}
@@ -0,0 +1,81 @@
+package session
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrew-bierman/jean-tui/config"
+)
+
+// builtinAgent returns a built-in agent definition by name
+func builtinAgent(t *testing.T, name string) config.AgentDefinition {
+	t.Helper()
+	for _, agent := range config.BuiltinAgents() {
+		if agent.Name == name {
+			return agent
+		}
+	}
+	t.Fatalf("No built-in agent %s", name)
+	return config.AgentDefinition{}
+}
+
+// TestClassifyAgentOutput tests classifying pane output with the built-in agents' patterns
+func TestClassifyAgentOutput(t *testing.T) {
+	claude := builtinAgent(t, "claude")
+	codex := builtinAgent(t, "codex")
+	aider := builtinAgent(t, "aider")
+	tests := []struct {
+		name           string
+		agent          config.AgentDefinition
+		output         string
+		recentActivity bool
+		want           AgentState
+	}{
+		{"claude busy", claude, "✻ Thinking… (12s · esc to interrupt)\n", false, AgentStateBusy},
+		{"claude permission prompt", claude, "Bash command\n  rm -rf build\nDo you want to proceed?\n❯ 1. Yes\n  2. No\n", false, AgentStateWaiting},
+		{"waiting wins over busy", claude, "esc to interrupt\nDo you want to make this edit?\n", true, AgentStateWaiting},
+		{"claude idle", claude, "> \n  ? for shortcuts\n", false, AgentStateIdle},
+		{"recent activity without match", claude, "Compiling...\n", true, AgentStateBusy},
+		{"codex approval", codex, "Allow command?\n  go test ./...\n", false, AgentStateWaiting},
+		{"aider confirmation", aider, "Add file to the chat? (Y)es/(N)o [Yes]:", false, AgentStateWaiting},
+		{"aider tokens", aider, "Tokens: 2.1k sent, 300 received.\n", false, AgentStateBusy},
+		{"empty output", claude, "", false, AgentStateIdle},
+		{
+			name:   "prompt scrolled out of the scanned lines",
+			agent:  claude,
+			output: "Do you want to proceed?\n" + strings.Repeat("output\n", stateScanLines),
+			want:   AgentStateIdle,
+		},
+		{
+			name:   "trailing blank lines ignored",
+			agent:  claude,
+			output: "Do you want to proceed?\n" + strings.Repeat("output\n", stateScanLines-1) + "\n\n\n",
+			want:   AgentStateWaiting,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyAgentOutput(tt.agent, tt.output, tt.recentActivity); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestClassifyAgentOutput_InvalidPattern tests that invalid custom patterns are ignored
+func TestClassifyAgentOutput_InvalidPattern(t *testing.T) {
+	agent := config.AgentDefinition{
+		Name:            "custom",
+		WaitingPatterns: []string{`(unclosed`, `\[y/N\]`},
+		BusyPatterns:    []string{`[broken`},
+	}
+	for i := 0; i < 2; i++ { // The second round uses the cached patterns
+		if got := ClassifyAgentOutput(agent, "Continue? [y/N]", false); got != AgentStateWaiting {
+			t.Errorf("Expected waiting, got %v", got)
+		}
+		if got := ClassifyAgentOutput(agent, "[broken", false); got != AgentStateIdle {
+			t.Errorf("Expected idle, got %v", got)
+		}
+	}
+}
@@ -0,0 +1,356 @@
+package session
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/andrew-bierman/jean-tui/internal/branding"
+)
+
+// Kinds of tracked time
+const (
+	TrackAttached = "attached" // A terminal was attached to the worktree's session
+	TrackAgent    = "agent"    // The worktree's agent was busy
+)
+
+// trackGap is how long an interval may go unobserved before the next observation starts a new one
+const trackGap = 2 * time.Minute
+
+// trackFlushInterval is how often the intervals being extended are written to disk
+const trackFlushInterval = 30 * time.Second
+
+// trackDayLayout names the daily time log files (local time)
+const trackDayLayout = "2006-01-02"
+
+// TrackedInterval is a stretch of time a worktree was attached to or its agent was busy
+type TrackedInterval struct {
+	Repo    string    `json:"repo"`
+	Branch  string    `json:"branch"`
+	Session string    `json:"session"`
+	Kind    string    `json:"kind"` // TrackAttached or TrackAgent
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+}
+
+// sameInterval reports whether two records describe the same interval (its end moves as it's extended)
+func (iv TrackedInterval) sameInterval(other TrackedInterval) bool {
+	return iv.Session == other.Session && iv.Kind == other.Kind && iv.Start.Equal(other.Start)
+}
+
+// TimeTracker turns periodic session observations into intervals stored in daily time logs
+type TimeTracker struct {
+	open      map[string]*TrackedInterval // session + kind -> interval being extended
+	pending   []TrackedInterval           // Intervals replaced since the last flush
+	lastFlush time.Time
+}
+
+// NewTimeTracker creates a time tracker
+func NewTimeTracker() *TimeTracker {
+	return &TimeTracker{open: make(map[string]*TrackedInterval)}
+}
+
+// Observe records that a worktree's session was seen attached and/or with a busy agent at now
+func (t *TimeTracker) Observe(now time.Time, sessionName, repo, branch string, attached, agentBusy bool) {
+	if attached {
+		t.extend(now, TrackedInterval{Repo: repo, Branch: branch, Session: sessionName, Kind: TrackAttached})
+	}
+	if agentBusy {
+		t.extend(now, TrackedInterval{Repo: repo, Branch: branch, Session: sessionName, Kind: TrackAgent})
+	}
+}
+
+// extend extends the open interval of a session and kind, or starts a new one after a gap or at midnight
+func (t *TimeTracker) extend(now time.Time, sample TrackedInterval) {
+	key := sample.Session + "\x00" + sample.Kind
+	if iv, ok := t.open[key]; ok {
+		if now.Sub(iv.End) <= trackGap && iv.Start.Format(trackDayLayout) == now.Format(trackDayLayout) {
+			iv.End = now
+			return
+		}
+		t.pending = append(t.pending, *iv)
+	}
+	sample.Start, sample.End = now, now
+	t.open[key] = &sample
+}
+
+// Flush writes the intervals to the time log, at most every trackFlushInterval unless force is set.
+// Intervals not observed for trackGap are written one last time and forgotten.
+func (t *TimeTracker) Flush(now time.Time, force bool) error {
+	if !force && now.Sub(t.lastFlush) < trackFlushInterval {
+		return nil
+	}
+	t.lastFlush = now
+
+	intervals := t.pending
+	for key, iv := range t.open {
+		intervals = append(intervals, *iv)
+		if now.Sub(iv.End) > trackGap {
+			delete(t.open, key)
+		}
+	}
+	t.pending = nil
+	if len(intervals) == 0 {
+		return nil
+	}
+
+	byDay := make(map[string][]TrackedInterval)
+	for _, iv := range intervals {
+		day := iv.Start.Format(trackDayLayout)
+		byDay[day] = append(byDay[day], iv)
+	}
+	for day, updates := range byDay {
+		if err := updateTimeLog(day, updates); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// timeLogPath returns the time log of a day (~/.config/<cli>/timelog/<YYYY-MM-DD>.json)
+func timeLogPath(day string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", branding.ConfigDirName, "timelog", day+".json"), nil
+}
+
+// loadTimeLog reads the intervals of a day (none if there is no log)
+func loadTimeLog(day string) ([]TrackedInterval, error) {
+	path, err := timeLogPath(day)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read time log: %w", err)
+	}
+	var intervals []TrackedInterval
+	if err := json.Unmarshal(data, &intervals); err != nil {
+		return nil, fmt.Errorf("failed to parse time log %s: %w", filepath.Base(path), err)
+	}
+	return intervals, nil
+}
+
+// updateTimeLog replaces or adds intervals in a day's log
+func updateTimeLog(day string, updates []TrackedInterval) error {
+	intervals, err := loadTimeLog(day)
+	if err != nil {
+		return err
+	}
+	for _, update := range updates {
+		replaced := false
+		for i := range intervals {
+			if intervals[i].sameInterval(update) {
+				intervals[i] = update
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			intervals = append(intervals, update)
+		}
+	}
+
+	path, err := timeLogPath(day)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create time log directory: %w", err)
+	}
+	data, err := json.MarshalIndent(intervals, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode time log: %w", err)
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// TimeReportRow summarises the tracked time of a branch on one day
+type TimeReportRow struct {
+	Day      string // YYYY-MM-DD (local time)
+	Repo     string
+	Branch   string
+	Active   time.Duration // Attached or agent busy (overlaps counted once)
+	Attached time.Duration
+	Agent    time.Duration
+}
+
+// TimeReport summarises the time tracked since the given time per branch and day,
+// for one repository or all of them when repoPath is ""
+func TimeReport(since time.Time, repoPath string) ([]TimeReportRow, error) {
+	type rowKey struct{ day, repo, branch string }
+	grouped := make(map[rowKey][]TrackedInterval)
+
+	now := time.Now()
+	first := time.Date(since.Year(), since.Month(), since.Day(), 0, 0, 0, 0, since.Location())
+	for day := first; !day.After(now); day = day.AddDate(0, 0, 1) {
+		name := day.Format(trackDayLayout)
+		intervals, err := loadTimeLog(name)
+		if err != nil {
+			return nil, err
+		}
+		for _, iv := range intervals {
+			if repoPath != "" && !samePath(iv.Repo, repoPath) {
+				continue
+			}
+			if iv.End.Before(since) {
+				continue
+			}
+			if iv.Start.Before(since) {
+				iv.Start = since
+			}
+			key := rowKey{name, iv.Repo, iv.Branch}
+			grouped[key] = append(grouped[key], iv)
+		}
+	}
+
+	rows := make([]TimeReportRow, 0, len(grouped))
+	for key, intervals := range grouped {
+		var attached, agent []TrackedInterval
+		for _, iv := range intervals {
+			if iv.Kind == TrackAttached {
+				attached = append(attached, iv)
+			} else {
+				agent = append(agent, iv)
+			}
+		}
+		rows = append(rows, TimeReportRow{
+			Day:      key.day,
+			Repo:     key.repo,
+			Branch:   key.branch,
+			Active:   coveredTime(intervals),
+			Attached: coveredTime(attached),
+			Agent:    coveredTime(agent),
+		})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Day != rows[j].Day {
+			return rows[i].Day < rows[j].Day
+		}
+		if rows[i].Repo != rows[j].Repo {
+			return rows[i].Repo < rows[j].Repo
+		}
+		return rows[i].Branch < rows[j].Branch
+	})
+	return rows, nil
+}
+
+// coveredTime returns the time covered by the intervals, counting overlaps once
+func coveredTime(intervals []TrackedInterval) time.Duration {
+	sorted := append([]TrackedInterval(nil), intervals...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+
+	var total time.Duration
+	var start, end time.Time
+	for i, iv := range sorted {
+		if i > 0 && !iv.Start.After(end) {
+			if iv.End.After(end) {
+				end = iv.End
+			}
+			continue
+		}
+		total += end.Sub(start)
+		start, end = iv.Start, iv.End
+	}
+	return total + end.Sub(start)
+}
+
+// BranchTotals adds up report rows per repository and branch (ordered by active time, most first)
+func BranchTotals(rows []TimeReportRow) []TimeReportRow {
+	type branchKey struct{ repo, branch string }
+	totals := make(map[branchKey]*TimeReportRow)
+	var order []branchKey
+	for _, row := range rows {
+		key := branchKey{row.Repo, row.Branch}
+		total, ok := totals[key]
+		if !ok {
+			total = &TimeReportRow{Repo: row.Repo, Branch: row.Branch}
+			totals[key] = total
+			order = append(order, key)
+		}
+		total.Active += row.Active
+		total.Attached += row.Attached
+		total.Agent += row.Agent
+	}
+
+	result := make([]TimeReportRow, 0, len(order))
+	for _, key := range order {
+		result = append(result, *totals[key])
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Active > result[j].Active })
+	return result
+}
+
+// FormatTrackedTime formats a tracked duration for display (e.g. "2h 05m", "12m")
+func FormatTrackedTime(d time.Duration) string {
+	minutes := int(d.Round(time.Minute) / time.Minute)
+	if minutes < 60 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return fmt.Sprintf("%dh %02dm", minutes/60, minutes%60)
+}
+
+// WriteTimeReportCSV writes report rows as CSV (durations in minutes)
+func WriteTimeReportCSV(w io.Writer, rows []TimeReportRow) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"day", "repo", "branch", "active_minutes", "attached_minutes", "agent_minutes"})
+	for _, row := range rows {
+		writer.Write([]string{
+			row.Day, row.Repo, row.Branch,
+			fmt.Sprintf("%.1f", row.Active.Minutes()),
+			fmt.Sprintf("%.1f", row.Attached.Minutes()),
+			fmt.Sprintf("%.1f", row.Agent.Minutes()),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteTimeReportJSON writes report rows as a JSON array (durations in seconds)
+func WriteTimeReportJSON(w io.Writer, rows []TimeReportRow) error {
+	type jsonRow struct {
+		Day             string `json:"day"`
+		Repo            string `json:"repo"`
+		Branch          string `json:"branch"`
+		ActiveSeconds   int64  `json:"active_seconds"`
+		AttachedSeconds int64  `json:"attached_seconds"`
+		AgentSeconds    int64  `json:"agent_seconds"`
+	}
+	out := make([]jsonRow, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, jsonRow{
+			Day:             row.Day,
+			Repo:            row.Repo,
+			Branch:          row.Branch,
+			ActiveSeconds:   int64(row.Active.Seconds()),
+			AttachedSeconds: int64(row.Attached.Seconds()),
+			AgentSeconds:    int64(row.Agent.Seconds()),
+		})
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
+
+// ExportTimeReport writes report rows to a file, as JSON for a .json path and CSV otherwise
+func ExportTimeReport(path string, rows []TimeReportRow) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+	defer file.Close()
+	if filepath.Ext(path) == ".json" {
+		return WriteTimeReportJSON(file, rows)
+	}
+	return WriteTimeReportCSV(file, rows)
+}
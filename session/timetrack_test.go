@@ -0,0 +1,160 @@
+package session
+
+import (
+	"testing"
+	"time"
+)
+
+// span builds an interval from minute offsets of a fixed base time
+func span(kind string, startMin, endMin int) TrackedInterval {
+	base := time.Date(2024, 3, 1, 10, 0, 0, 0, time.Local)
+	return TrackedInterval{
+		Repo:    "/repo",
+		Branch:  "feature",
+		Session: "jean-repo-feature",
+		Kind:    kind,
+		Start:   base.Add(time.Duration(startMin) * time.Minute),
+		End:     base.Add(time.Duration(endMin) * time.Minute),
+	}
+}
+
+// TestCoveredTime tests that overlapping intervals are counted once
+func TestCoveredTime(t *testing.T) {
+	tests := []struct {
+		name      string
+		intervals []TrackedInterval
+		want      time.Duration
+	}{
+		{"empty", nil, 0},
+		{"single", []TrackedInterval{span(TrackAttached, 0, 10)}, 10 * time.Minute},
+		{"disjoint", []TrackedInterval{span(TrackAttached, 0, 10), span(TrackAttached, 20, 25)}, 15 * time.Minute},
+		{"overlapping", []TrackedInterval{span(TrackAttached, 0, 10), span(TrackAgent, 5, 15)}, 15 * time.Minute},
+		{"contained", []TrackedInterval{span(TrackAttached, 0, 30), span(TrackAgent, 5, 10)}, 30 * time.Minute},
+		{"touching", []TrackedInterval{span(TrackAttached, 0, 10), span(TrackAgent, 10, 20)}, 20 * time.Minute},
+		{"unsorted", []TrackedInterval{span(TrackAgent, 20, 30), span(TrackAttached, 0, 25)}, 30 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := coveredTime(tt.intervals); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestTimeTracker_SplitsAtMidnight tests that an interval crossing midnight is logged on both days
+func TestTimeTracker_SplitsAtMidnight(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	today := time.Now()
+	midnight := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.Local)
+	tracker := NewTimeTracker()
+	for _, at := range []time.Time{
+		midnight.Add(-2 * time.Minute),
+		midnight.Add(-time.Minute),
+		midnight.Add(time.Minute),
+	} {
+		tracker.Observe(at, "jean-repo-feature", "/repo", "feature", true, false)
+	}
+	if err := tracker.Flush(midnight.Add(time.Minute), true); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	rows, err := TimeReport(midnight.AddDate(0, 0, -1), "/repo")
+	if err != nil {
+		t.Fatalf("TimeReport failed: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("Expected 2 rows, got %d: %+v", len(rows), rows)
+	}
+	if rows[0].Day != midnight.AddDate(0, 0, -1).Format(trackDayLayout) || rows[0].Attached != time.Minute {
+		t.Errorf("Expected 1m on the first day, got %+v", rows[0])
+	}
+	if rows[1].Day != midnight.Format(trackDayLayout) || rows[1].Attached != 0 {
+		t.Errorf("Expected an empty interval after midnight, got %+v", rows[1])
+	}
+}
+
+// TestTimeReport tests the per-day report of overlapping attached and agent time
+func TestTimeReport(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	now := time.Now()
+	start := now.Add(-30 * time.Minute)
+	at := func(min int) time.Time { return start.Add(time.Duration(min) * time.Minute) }
+	day := start.Format(trackDayLayout)
+	err := updateTimeLog(day, []TrackedInterval{
+		{Repo: "/repo", Branch: "feature", Session: "s", Kind: TrackAttached, Start: at(0), End: at(10)},
+		{Repo: "/repo", Branch: "feature", Session: "s", Kind: TrackAgent, Start: at(5), End: at(20)},
+		{Repo: "/other", Branch: "main", Session: "o", Kind: TrackAttached, Start: at(0), End: at(3)},
+	})
+	if err != nil {
+		t.Fatalf("updateTimeLog failed: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		since time.Time
+		repo  string
+		want  []TimeReportRow
+	}{
+		{
+			name:  "overlapping kinds",
+			since: start,
+			repo:  "/repo",
+			want:  []TimeReportRow{{Day: day, Repo: "/repo", Branch: "feature", Active: 20 * time.Minute, Attached: 10 * time.Minute, Agent: 15 * time.Minute}},
+		},
+		{
+			name:  "clipped to since",
+			since: at(8),
+			repo:  "/repo",
+			want:  []TimeReportRow{{Day: day, Repo: "/repo", Branch: "feature", Active: 12 * time.Minute, Attached: 2 * time.Minute, Agent: 12 * time.Minute}},
+		},
+		{
+			name:  "all repositories",
+			since: start,
+			repo:  "",
+			want: []TimeReportRow{
+				{Day: day, Repo: "/other", Branch: "main", Active: 3 * time.Minute, Attached: 3 * time.Minute},
+				{Day: day, Repo: "/repo", Branch: "feature", Active: 20 * time.Minute, Attached: 10 * time.Minute, Agent: 15 * time.Minute},
+			},
+		},
+		{
+			name:  "unknown repository",
+			since: start,
+			repo:  "/missing",
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := TimeReport(tt.since, tt.repo)
+			if err != nil {
+				t.Fatalf("TimeReport failed: %v", err)
+			}
+			if len(rows) != len(tt.want) {
+				t.Fatalf("Expected %d rows, got %d: %+v", len(tt.want), len(rows), rows)
+			}
+			for i := range rows {
+				if rows[i] != tt.want[i] {
+					t.Errorf("Row %d: expected %+v, got %+v", i, tt.want[i], rows[i])
+				}
+			}
+		})
+	}
+}
+
+// TestTimeReport_EmptyLog tests that days without a time log report nothing
+func TestTimeReport_EmptyLog(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	rows, err := TimeReport(time.Now().AddDate(0, 0, -7), "")
+	if err != nil {
+		t.Fatalf("TimeReport failed: %v", err)
+	}
+	if len(rows) != 0 {
+		t.Errorf("Expected no rows, got %+v", rows)
+	}
+}
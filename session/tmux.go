@@ -18,6 +18,9 @@ type Session struct {
 	Name         string
 	Branch       string
 	Path         string // Working directory of the session
+	Repo         string // Main repository path ("" for sessions not tagged by jean yet)
+	Worktree     string // Worktree path ("" for sessions not tagged by jean yet)
+	Agent        string // Agent in the agent window ("" if unknown)
 	Active       bool
 	Windows      int
 	LastActivity time.Time
@@ -80,24 +83,19 @@ func (m *Manager) isAgentAvailable() bool {
 	return err == nil
 }
 
-// buildAgentCommand constructs the proper agent command with flags
-// path is the worktree path to add with --add-dir
-// isInitialized determines whether to use --continue flag
+// buildAgentCommand constructs the command for the build-time default agent
+// path is the worktree path the agent gets access to
+// isInitialized resumes the previous conversation (falling back to a fresh start)
 func (m *Manager) buildAgentCommand(path string, isInitialized bool) string {
 	if !branding.IsAgentEnabled() {
 		return "" // Blank terminal mode
 	}
+	return BuildAgentCommand(DefaultAgent(), path, isInitialized, "", "")
+}
 
-	// For Claude, use specific flags
-	if branding.IsClaudeAgent() {
-		if isInitialized {
-			return fmt.Sprintf("claude --add-dir %s --continue --permission-mode plan", path)
-		}
-		return fmt.Sprintf("claude --add-dir %s --permission-mode plan", path)
-	}
-
-	// For other agents, just run the command as-is
-	return branding.AgentCommand
+// shellQuote quotes a string for safe use as a single sh argument
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
 }
 
 // createOrAttach creates a new session or attaches to existing one
@@ -119,12 +117,14 @@ func (m *Manager) createOrAttach(path, branch, repoName string, autoStartClaude
 // Create creates a new tmux session with both windows
 // Window 1: terminal (shell) - created automatically by new-session with base-index 1
 // Window 2: agent window (if autoStartAgent is true)
+// Window 3+: additional windows from the jean.json layout (if any)
 func (m *Manager) Create(sessionName, path string, autoStartAgent bool, targetWindow string) error {
 	// Create detached session with window 1 (terminal) - base-index 1 makes first window = 1
 	cmd := exec.Command("tmux", "new-session", "-d", "-s", sessionName, "-c", path, "-n", "terminal")
 	if err := cmd.Run(); err != nil {
 		return err
 	}
+	m.tagNewSession(sessionName, path, DefaultAgent().Name)
 
 	// Create window 2 (agent) if autoStartAgent is true
 	if autoStartAgent {
@@ -144,20 +144,33 @@ func (m *Manager) Create(sessionName, path string, autoStartAgent bool, targetWi
 		}
 	}
 
+	if err := m.ApplyLayout(sessionName, path, m.LoadLayout(path)); err != nil {
+		// Layout errors leave a usable session behind, so attach anyway
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
 	// Attach to the target window
 	return m.AttachToWindow(sessionName, path, autoStartAgent, targetWindow)
 }
 
 // AttachToWindow attaches to a specific window in a session
-// Creates the window if it doesn't exist
+// Creates the window if it doesn't exist. targetWindow may also name a window from the
+// jean.json layout, in which case the layout is (re-)applied before attaching.
 func (m *Manager) AttachToWindow(sessionName, path string, autoStartAgent bool, targetWindow string) error {
+	if targetWindow != "" && !isDefaultWindow(targetWindow) {
+		if err := m.ApplyLayout(sessionName, path, m.LoadLayout(path)); err != nil {
+			return err
+		}
+		return m.Attach(WindowTarget(sessionName, targetWindow))
+	}
+
 	var windowIndex string
 	var windowName string
 	var windowCommand string
 
 	// Check if targeting the agent window (could be "claude" or the configured agent window name)
 	agentWindowName := branding.AgentWindowName
-	if targetWindow == "claude" || targetWindow == agentWindowName {
+	if isAgentWindow(targetWindow) {
 		windowIndex = "2"
 		windowName = agentWindowName
 		// Use proper agent command with flags, or fallback to shell
@@ -406,9 +419,14 @@ func (m *Manager) NewWindowAndAttach(sessionName, path string) error {
 // List returns all jean tmux sessions, optionally filtered by repository path
 // If repoPath is empty string, returns all jean sessions
 func (m *Manager) List(repoPath string) ([]Session, error) {
-	// List all sessions with format: name:windows:attached:activity:path
+	// List all sessions with format: name, windows, attached, activity, path and the jean user options,
+	// separated by the ASCII unit separator (paths and branches may contain ':')
 	// activity is the maximum window_activity timestamp in the session
-	cmd := exec.Command("tmux", "list-sessions", "-F", "#{session_name}:#{session_windows}:#{session_attached}:#{session_activity}:#{session_path}")
+	fields := []string{"#{session_name}", "#{session_windows}", "#{session_attached}", "#{session_activity}", "#{session_path}"}
+	for _, field := range metaFields {
+		fields = append(fields, "#{"+metaOption(field)+"}")
+	}
+	cmd := exec.Command("tmux", "list-sessions", "-F", strings.Join(fields, listSeparator))
 	output, err := cmd.Output()
 	if err != nil {
 		// No sessions exist
@@ -417,27 +435,43 @@ func (m *Manager) List(repoPath string) ([]Session, error) {
 
 	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
 	var sessions []Session
+	var controlClients map[string]int // Loaded once a session has clients
 
 	for _, line := range lines {
 		if !strings.HasPrefix(line, branding.SessionPrefix) {
 			continue
 		}
 
-		parts := strings.Split(line, ":")
-		if len(parts) < 5 {
+		parts := strings.Split(line, listSeparator)
+		if len(parts) < len(fields) {
 			continue
 		}
 
 		name := parts[0]
 		sessionPath := parts[4]
+		meta := SessionMeta{Repo: parts[5], Branch: parts[6], Worktree: parts[7], Agent: parts[8]}
 
-		// Filter by repository path if provided
-		if repoPath != "" && !strings.HasPrefix(sessionPath, repoPath) {
-			continue
+		// Filter by repository path if provided (by path prefix for sessions not tagged yet)
+		if repoPath != "" {
+			if meta.Repo != "" && !samePath(meta.Repo, repoPath) {
+				continue
+			}
+			if meta.Repo == "" && !strings.HasPrefix(sessionPath, repoPath) {
+				continue
+			}
 		}
 
-		branch := strings.TrimPrefix(name, branding.SessionPrefix)
-		active := parts[2] == "1"
+		branch := meta.Branch
+		if branch == "" {
+			// Untagged session: best guess from the name (which also includes the repo)
+			branch = strings.TrimPrefix(name, branding.SessionPrefix)
+		}
+		// jean's control clients (its event clients) don't make a session active
+		attached, _ := strconv.Atoi(parts[2])
+		if attached > 0 && controlClients == nil {
+			controlClients = controlClientCounts()
+		}
+		active := attached > controlClients[name]
 
 		// Parse window count
 		windows := 1
@@ -455,6 +489,9 @@ func (m *Manager) List(repoPath string) ([]Session, error) {
 			Name:         name,
 			Branch:       branch,
 			Path:         sessionPath,
+			Repo:         meta.Repo,
+			Worktree:     meta.Worktree,
+			Agent:        meta.Agent,
 			Active:       active,
 			Windows:      windows,
 			LastActivity: lastActivity,
@@ -466,6 +503,14 @@ func (m *Manager) List(repoPath string) ([]Session, error) {
 
 // Kill terminates a tmux session and all its windows
 func (m *Manager) Kill(sessionName string) error {
+	// Save the scrollback first so the agent conversation isn't lost (best effort)
+	if m.SessionExists(sessionName) {
+		_, _ = m.ArchiveScrollback(sessionName)
+	}
+
+	// Closed on purpose, so don't offer to restore it
+	forgetSession(sessionName)
+
 	// tmux kill-session handles killing all windows in the session efficiently
 	cmd := exec.Command("tmux", "kill-session", "-t", sessionName)
 	return cmd.Run()
@@ -481,7 +526,12 @@ func (m *Manager) RenameSession(oldName, newName string) error {
 	}
 
 	cmd := exec.Command("tmux", "rename-session", "-t", oldName, newName)
-	return cmd.Run()
+	if err := cmd.Run(); err != nil {
+		return err
+	}
+	// The next snapshot records the new name; the old one must not look restorable
+	forgetSession(oldName)
+	return nil
 }
 
 // IsTmuxAvailable checks if tmux is installed
@@ -0,0 +1,220 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andrew-bierman/jean-tui/config"
+)
+
+// Kinds of transcript entries
+const (
+	TranscriptPrompt     = "prompt"      // Typed by the user
+	TranscriptReply      = "reply"       // Text written by the agent
+	TranscriptToolCall   = "tool_call"   // Tool the agent ran, with a summary of its input
+	TranscriptToolResult = "tool_result" // Output the tool returned to the agent
+)
+
+// toolInputKeys are the tool input fields that best describe a call, in order of preference
+var toolInputKeys = []string{"command", "cmd", "file_path", "path", "notebook_path", "pattern", "url", "query", "description", "prompt"}
+
+// TranscriptEntry is a message or tool call of an agent conversation
+type TranscriptEntry struct {
+	Kind  string // TranscriptPrompt, TranscriptReply, TranscriptToolCall or TranscriptToolResult
+	Time  time.Time
+	Tool  string // Tool name (calls and results)
+	Text  string // Message text, tool input summary or tool output
+	Error bool   // The tool reported a failure
+}
+
+// ReadTranscript reads the messages and tool calls of a conversation from the agent's session file
+func ReadTranscript(agent config.AgentDefinition, conv Conversation) ([]TranscriptEntry, error) {
+	var entries []TranscriptEntry
+	var err error
+	switch agent.ConversationFormat {
+	case ConversationFormatClaude:
+		entries, err = readClaudeTranscript(conv.Path)
+	case ConversationFormatCodex:
+		entries, err = readCodexTranscript(conv.Path)
+	default:
+		return nil, fmt.Errorf("%s conversations can't be read", agent.Name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transcript: %w", err)
+	}
+	return entries, nil
+}
+
+// summarizeToolInput describes a tool call in one line: its most telling field, or the compact JSON input
+func summarizeToolInput(input json.RawMessage) string {
+	var fields map[string]any
+	if json.Unmarshal(input, &fields) != nil {
+		var text string
+		if json.Unmarshal(input, &text) == nil {
+			return text // Free-form input (e.g. a patch)
+		}
+		return string(input)
+	}
+	for _, key := range toolInputKeys {
+		switch value := fields[key].(type) {
+		case string:
+			if value != "" {
+				return value
+			}
+		case []any:
+			parts := make([]string, 0, len(value))
+			for _, part := range value {
+				parts = append(parts, fmt.Sprint(part))
+			}
+			return strings.Join(parts, " ")
+		}
+	}
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, key := range keys {
+		value, _ := json.Marshal(fields[key])
+		parts = append(parts, key+"="+string(value))
+	}
+	return strings.Join(parts, " ")
+}
+
+// claudeBlock is a content block of a Claude message
+type claudeBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text"`
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Input     json.RawMessage `json:"input"`
+	ToolUseID string          `json:"tool_use_id"`
+	Content   json.RawMessage `json:"content"`
+	IsError   bool            `json:"is_error"`
+}
+
+// readClaudeTranscript reads a Claude session file. Thinking blocks and injected context are left out.
+func readClaudeTranscript(path string) ([]TranscriptEntry, error) {
+	var entries []TranscriptEntry
+	tools := make(map[string]string) // tool_use id -> tool name
+	_, err := scanJSONLines(path, func(data []byte) bool {
+		var line claudeLine
+		if json.Unmarshal(data, &line) != nil || (line.Type != "user" && line.Type != "assistant") || line.IsMeta {
+			return true
+		}
+
+		var blocks []claudeBlock
+		if json.Unmarshal(line.Message.Content, &blocks) != nil {
+			// Plain string content
+			text := messageText(line.Message.Content)
+			if line.Type == "user" && isPromptText(text) {
+				entries = append(entries, TranscriptEntry{Kind: TranscriptPrompt, Time: line.Timestamp, Text: strings.TrimSpace(text)})
+			} else if line.Type == "assistant" && strings.TrimSpace(text) != "" {
+				entries = append(entries, TranscriptEntry{Kind: TranscriptReply, Time: line.Timestamp, Text: strings.TrimSpace(text)})
+			}
+			return true
+		}
+
+		for _, block := range blocks {
+			switch block.Type {
+			case "text":
+				if line.Type == "user" && isPromptText(block.Text) {
+					entries = append(entries, TranscriptEntry{Kind: TranscriptPrompt, Time: line.Timestamp, Text: strings.TrimSpace(block.Text)})
+				} else if line.Type == "assistant" && strings.TrimSpace(block.Text) != "" {
+					entries = append(entries, TranscriptEntry{Kind: TranscriptReply, Time: line.Timestamp, Text: strings.TrimSpace(block.Text)})
+				}
+			case "tool_use":
+				tools[block.ID] = block.Name
+				entries = append(entries, TranscriptEntry{Kind: TranscriptToolCall, Time: line.Timestamp, Tool: block.Name, Text: summarizeToolInput(block.Input)})
+			case "tool_result":
+				entries = append(entries, TranscriptEntry{
+					Kind:  TranscriptToolResult,
+					Time:  line.Timestamp,
+					Tool:  tools[block.ToolUseID],
+					Text:  strings.TrimRight(messageText(block.Content), "\n"),
+					Error: block.IsError,
+				})
+			}
+		}
+		return true
+	})
+	return entries, err
+}
+
+// codexTranscriptLine is the part of a Codex rollout file line the transcript reads
+type codexTranscriptLine struct {
+	Timestamp time.Time `json:"timestamp"`
+	Type      string    `json:"type"`
+	Payload   struct {
+		Type      string          `json:"type"`
+		Role      string          `json:"role"`
+		Content   json.RawMessage `json:"content"`
+		Name      string          `json:"name"`
+		Arguments string          `json:"arguments"` // JSON-encoded function arguments
+		Input     string          `json:"input"`     // Custom tool input
+		CallID    string          `json:"call_id"`
+		Output    json.RawMessage `json:"output"`
+	} `json:"payload"`
+}
+
+// codexOutputText returns the text of a function call output, which is a string or {"output": "..."}
+func codexOutputText(output json.RawMessage) (string, bool) {
+	var text string
+	if json.Unmarshal(output, &text) != nil {
+		return string(output), false
+	}
+	var wrapped struct {
+		Output   string `json:"output"`
+		Metadata struct {
+			ExitCode int `json:"exit_code"`
+		} `json:"metadata"`
+	}
+	if json.Unmarshal([]byte(text), &wrapped) == nil && wrapped.Output != "" {
+		return wrapped.Output, wrapped.Metadata.ExitCode != 0
+	}
+	return text, false
+}
+
+// readCodexTranscript reads a Codex rollout file. Reasoning summaries are left out.
+func readCodexTranscript(path string) ([]TranscriptEntry, error) {
+	var entries []TranscriptEntry
+	tools := make(map[string]string) // call id -> tool name
+	_, err := scanJSONLines(path, func(data []byte) bool {
+		var line codexTranscriptLine
+		if json.Unmarshal(data, &line) != nil || line.Type != "response_item" {
+			return true
+		}
+		payload := line.Payload
+		switch payload.Type {
+		case "message":
+			text := strings.TrimSpace(messageText(payload.Content))
+			if payload.Role == "user" && isPromptText(text) {
+				entries = append(entries, TranscriptEntry{Kind: TranscriptPrompt, Time: line.Timestamp, Text: text})
+			} else if payload.Role == "assistant" && text != "" {
+				entries = append(entries, TranscriptEntry{Kind: TranscriptReply, Time: line.Timestamp, Text: text})
+			}
+		case "function_call", "custom_tool_call":
+			tools[payload.CallID] = payload.Name
+			input := json.RawMessage(payload.Arguments)
+			if payload.Type == "custom_tool_call" {
+				input, _ = json.Marshal(payload.Input)
+			}
+			entries = append(entries, TranscriptEntry{Kind: TranscriptToolCall, Time: line.Timestamp, Tool: payload.Name, Text: summarizeToolInput(input)})
+		case "function_call_output", "custom_tool_call_output":
+			text, failed := codexOutputText(payload.Output)
+			entries = append(entries, TranscriptEntry{
+				Kind:  TranscriptToolResult,
+				Time:  line.Timestamp,
+				Tool:  tools[payload.CallID],
+				Text:  strings.TrimRight(text, "\n"),
+				Error: failed,
+			})
+		}
+		return true
+	})
+	return entries, err
+}
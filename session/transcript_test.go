@@ -0,0 +1,114 @@
+package session
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/andrew-bierman/jean-tui/config"
+)
+
+// TestSummarizeToolInput tests the one-line summaries of tool inputs
+func TestSummarizeToolInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"command", `{"command":"go test ./...","description":"Run tests"}`, "go test ./..."},
+		{"command list", `{"command":["bash","-lc","ls"]}`, "bash -lc ls"},
+		{"file path", `{"file_path":"/repo/main.go","old_string":"a"}`, "/repo/main.go"},
+		{"empty preferred field", `{"command":"","path":"docs"}`, "docs"},
+		{"unknown fields", `{"b":2,"a":"x"}`, `a="x" b=2`},
+		{"free-form string", `"*** Begin Patch"`, "*** Begin Patch"},
+		{"invalid", `not json`, "not json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summarizeToolInput(json.RawMessage(tt.input)); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestReadTranscript tests reading prompts, replies and tool calls from session files
+func TestReadTranscript(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		lines  []string
+		want   []TranscriptEntry
+	}{
+		{
+			name:   "claude",
+			format: ConversationFormatClaude,
+			lines: []string{
+				`{"type":"user","message":{"content":"List the files"}}`,
+				`{"type":"user","isMeta":true,"message":{"content":"Caveat: injected"}}`,
+				`{"type":"assistant","message":{"content":[{"type":"thinking","thinking":"hmm"},{"type":"text","text":"Sure "},{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"ls"}}]}}`,
+				`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"t1","content":"main.go\n","is_error":false}]}}`,
+				`{"type":"assistant","message":{"content":[{"type":"tool_use","id":"t2","name":"Read","input":{"file_path":"x"}}]}}`,
+				`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"t2","content":[{"type":"text","text":"missing"}],"is_error":true}]}}`,
+			},
+			want: []TranscriptEntry{
+				{Kind: TranscriptPrompt, Text: "List the files"},
+				{Kind: TranscriptReply, Text: "Sure"},
+				{Kind: TranscriptToolCall, Tool: "Bash", Text: "ls"},
+				{Kind: TranscriptToolResult, Tool: "Bash", Text: "main.go"},
+				{Kind: TranscriptToolCall, Tool: "Read", Text: "x"},
+				{Kind: TranscriptToolResult, Tool: "Read", Text: "missing", Error: true},
+			},
+		},
+		{
+			name:   "codex",
+			format: ConversationFormatCodex,
+			lines: []string{
+				`{"type":"session_meta","payload":{"id":"s","cwd":"/repo"}}`,
+				`{"type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"<environment_context/>"}]}}`,
+				`{"type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"Run the tests"}]}}`,
+				`{"type":"response_item","payload":{"type":"reasoning","summary":[]}}`,
+				`{"type":"response_item","payload":{"type":"function_call","name":"shell","arguments":"{\"command\":[\"go\",\"test\"]}","call_id":"c1"}}`,
+				`{"type":"response_item","payload":{"type":"function_call_output","call_id":"c1","output":"{\"output\":\"FAIL\\n\",\"metadata\":{\"exit_code\":1}}"}}`,
+				`{"type":"response_item","payload":{"type":"custom_tool_call","name":"apply_patch","input":"*** Begin Patch","call_id":"c2"}}`,
+				`{"type":"response_item","payload":{"type":"custom_tool_call_output","call_id":"c2","output":"Done!"}}`,
+				`{"type":"response_item","payload":{"type":"message","role":"assistant","content":[{"type":"output_text","text":"Fixed"}]}}`,
+			},
+			want: []TranscriptEntry{
+				{Kind: TranscriptPrompt, Text: "Run the tests"},
+				{Kind: TranscriptToolCall, Tool: "shell", Text: "go test"},
+				{Kind: TranscriptToolResult, Tool: "shell", Text: "FAIL", Error: true},
+				{Kind: TranscriptToolCall, Tool: "apply_patch", Text: "*** Begin Patch"},
+				{Kind: TranscriptToolResult, Tool: "apply_patch", Text: "Done!"},
+				{Kind: TranscriptReply, Text: "Fixed"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeSessionFile(t, t.TempDir(), "conversation.jsonl", tt.lines...)
+			agent := config.AgentDefinition{Name: tt.name, ConversationFormat: tt.format}
+			entries, err := ReadTranscript(agent, Conversation{Path: path})
+			if err != nil {
+				t.Fatalf("ReadTranscript failed: %v", err)
+			}
+			if len(entries) != len(tt.want) {
+				t.Fatalf("Expected %d entries, got %d: %+v", len(tt.want), len(entries), entries)
+			}
+			for i, entry := range entries {
+				entry.Time = tt.want[i].Time
+				if entry != tt.want[i] {
+					t.Errorf("Entry %d: expected %+v, got %+v", i, tt.want[i], entry)
+				}
+			}
+		})
+	}
+}
+
+// TestReadTranscript_UnknownFormat tests that agents without a conversation format have no transcript
+func TestReadTranscript_UnknownFormat(t *testing.T) {
+	if _, err := ReadTranscript(config.AgentDefinition{Name: "aider"}, Conversation{}); err == nil {
+		t.Error("Expected an error for an agent without a conversation format")
+	}
+}
@@ -0,0 +1,217 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/andrew-bierman/jean-tui/config"
+	"github.com/andrew-bierman/jean-tui/internal/branding"
+)
+
+// watcherIdleTimeout is how long the watcher keeps running without any agent sessions
+const watcherIdleTimeout = 5 * time.Minute
+
+// watchedSession caches what the watcher knows about a session
+type watchedSession struct {
+	repo   string // Main repository path ("" if the session isn't in a worktree)
+	branch string
+	agent  config.AgentDefinition
+	state  AgentState
+}
+
+// Watcher polls agent sessions in the background and alerts on busy -> waiting/idle transitions
+type Watcher struct {
+	manager       *Manager
+	configManager *config.Manager
+	tty           string // Terminal the watcher was started from (also alerted when not a tmux client)
+	sessions      map[string]*watchedSession
+	tracker       *TimeTracker // Records attached and agent-busy time while time tracking is enabled
+}
+
+// NewWatcher creates a watcher; tty is an additional terminal to alert (may be empty)
+func NewWatcher(configManager *config.Manager, tty string) *Watcher {
+	return &Watcher{
+		manager:       NewManager(),
+		configManager: configManager,
+		tty:           tty,
+		sessions:      make(map[string]*watchedSession),
+		tracker:       NewTimeTracker(),
+	}
+}
+
+// resolveSession finds the branch and agent of a session from its tags, or else its worktree path
+func (w *Watcher) resolveSession(sess Session) *watchedSession {
+	watched := &watchedSession{agent: DefaultAgent(), branch: sess.Branch}
+
+	repoPath := sess.Repo
+	if repoPath == "" {
+		var branch string
+		var err error
+		if repoPath, branch, err = worktreeRepo(sess.Path); err != nil {
+			return watched
+		}
+		watched.branch = branch
+	}
+	watched.repo = repoPath
+	if w.configManager == nil {
+		return watched
+	}
+	if sess.Agent != "" {
+		if agent, ok := w.configManager.GetAgent(sess.Agent); ok {
+			watched.agent = agent
+			return watched
+		}
+	}
+	if agents := w.configManager.GetWorktreeAgents(repoPath, watched.branch); len(agents) > 0 {
+		if agent, ok := w.configManager.GetAgent(agents[0]); ok {
+			watched.agent = agent
+		}
+	}
+	return watched
+}
+
+// Poll checks every agent session once and returns the alerts for state transitions
+func (w *Watcher) Poll() ([]Alert, int) {
+	sessions, _ := w.manager.List("")
+
+	var alerts []Alert
+	seen := make(map[string]bool)
+	for _, sess := range sessions {
+		seen[sess.Name] = true
+		watched, ok := w.sessions[sess.Name]
+		if !ok {
+			watched = w.resolveSession(sess)
+			w.sessions[sess.Name] = watched
+		}
+
+		state := w.manager.DetectAgentState(sess.Name, watched.agent)
+		if watched.state == AgentStateBusy {
+			switch state {
+			case AgentStateWaiting:
+				alerts = append(alerts, Alert{Title: watched.agent.Name + " needs input", Message: watched.branch, Branch: watched.branch, Session: sess.Name})
+			case AgentStateIdle:
+				alerts = append(alerts, Alert{Title: watched.agent.Name + " finished", Message: watched.branch, Branch: watched.branch, Session: sess.Name})
+			}
+		}
+		watched.state = state
+
+		if w.trackingEnabled() && watched.repo != "" {
+			w.tracker.Observe(time.Now(), sess.Name, watched.repo, watched.branch, sess.Active, state == AgentStateBusy)
+		}
+	}
+
+	// Forget sessions that are gone (a new session with the same name starts fresh)
+	for name := range w.sessions {
+		if !seen[name] {
+			delete(w.sessions, name)
+		}
+	}
+
+	return alerts, len(sessions)
+}
+
+// trackingEnabled reports whether time tracking is enabled
+func (w *Watcher) trackingEnabled() bool {
+	return w.configManager != nil && w.configManager.GetTimeTracking()
+}
+
+// autosave archives the scrollback of every running session
+func (w *Watcher) autosave() {
+	for name := range w.sessions {
+		_ = w.manager.AutosaveScrollback(name)
+	}
+}
+
+// Run polls until there have been no sessions for watcherIdleTimeout or stop is closed.
+// It also records sessions for restoring and archives them periodically when scrollback autosave is configured.
+// The tracked time is written out before returning.
+func (w *Watcher) Run(interval time.Duration, stop <-chan struct{}) error {
+	lastSeen := time.Now()
+	lastAutosave := time.Now()
+	var lastSnapshot time.Time
+
+	// Tag sessions from older versions so they resolve exactly (best effort)
+	_, _ = w.manager.MigrateSessions()
+	for {
+		alerts, count := w.Poll()
+		if count > 0 {
+			lastSeen = time.Now()
+		} else if time.Since(lastSeen) > watcherIdleTimeout {
+			return w.tracker.Flush(time.Now(), true)
+		}
+
+		if count > 0 && time.Since(lastSnapshot) >= SnapshotInterval {
+			_ = w.manager.SaveSessionSnapshot()
+			lastSnapshot = time.Now()
+		}
+
+		if w.configManager != nil {
+			_ = w.configManager.Reload() // Pick up settings changed in the TUI
+			if minutes := w.configManager.GetScrollbackAutosave(); minutes > 0 && time.Since(lastAutosave) >= time.Duration(minutes)*time.Minute {
+				w.autosave()
+				lastAutosave = time.Now()
+			}
+		}
+
+		if err := w.tracker.Flush(time.Now(), false); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+
+		if len(alerts) > 0 && w.configManager != nil {
+			cfg := w.configManager.GetNotificationConfig()
+			for _, alert := range alerts {
+				if err := Notify(cfg, alert, []string{w.tty}); err != nil {
+					fmt.Fprintln(os.Stderr, err)
+				}
+			}
+		}
+
+		select {
+		case <-stop:
+			return w.tracker.Flush(time.Now(), true)
+		case <-time.After(interval):
+		}
+	}
+}
+
+// IsWatcherRunning reports whether a watcher process from the PID file is alive
+func IsWatcherRunning() bool {
+	data, err := os.ReadFile(branding.GetWatcherPIDPath())
+	if err != nil {
+		return false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return false
+	}
+	return syscall.Kill(pid, 0) == nil
+}
+
+// StartWatcherProcess launches `<cli> watch` detached from the terminal, unless one is already running
+func StartWatcherProcess(tty string) error {
+	if IsWatcherRunning() {
+		return nil
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find %s executable: %w", branding.CLIName, err)
+	}
+
+	args := []string{"watch"}
+	if tty != "" {
+		args = append(args, "-tty", tty)
+	}
+	cmd := exec.Command(executable, args...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	cmd.Env = append(os.Environ(), branding.GetEnvVar("INIT_ATTEMPTED")+"=1") // Skip shell integration checks
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start watcher: %w", err)
+	}
+	return cmd.Process.Release()
+}
@@ -0,0 +1,181 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/andrew-bierman/jean-tui/config"
+	"github.com/andrew-bierman/jean-tui/internal/branding"
+)
+
+// ZellijBackend runs worktree sessions in zellij. Sessions are created from a generated KDL
+// layout with a tab per window (terminal, agents, jean.json layout windows).
+// Pane output can't be read, so agent state, alerts and scrollback archives are unavailable.
+type ZellijBackend struct{}
+
+// Name returns the backend name
+func (b *ZellijBackend) Name() string {
+	return BackendZellij
+}
+
+// IsAvailable reports whether zellij is installed
+func (b *ZellijBackend) IsAvailable() bool {
+	return commandExists("zellij")
+}
+
+// Capabilities returns the features zellij supports: windows (tabs) when the session is created
+func (b *ZellijBackend) Capabilities() Capabilities {
+	return Capabilities{Windows: true}
+}
+
+// sessionNames returns the names of all zellij sessions (including exited, resurrectable ones)
+func (b *ZellijBackend) sessionNames() []string {
+	output, err := exec.Command("zellij", "list-sessions", "--short", "--no-formatting").Output()
+	if err != nil {
+		return nil
+	}
+	return strings.Fields(string(output))
+}
+
+// SessionExists reports whether a zellij session with the name exists
+func (b *ZellijBackend) SessionExists(sessionName string) bool {
+	for _, name := range b.sessionNames() {
+		if name == sessionName {
+			return true
+		}
+	}
+	return false
+}
+
+// List returns the jean zellij sessions. zellij doesn't report session directories,
+// so sessions are matched to the repository by name (<prefix><repo>-...).
+func (b *ZellijBackend) List(repoPath string) ([]Session, error) {
+	prefix := branding.SessionPrefix
+	if repoPath != "" {
+		prefix = NewManager().SanitizeName(filepath.Base(repoPath), "")
+	}
+
+	sessions := []Session{}
+	for _, name := range b.sessionNames() {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		sessions = append(sessions, Session{
+			Name:    name,
+			Branch:  strings.TrimPrefix(name, branding.SessionPrefix),
+			Windows: 1,
+		})
+	}
+	return sessions, nil
+}
+
+// Kill terminates a zellij session and removes it from the resurrection list
+func (b *ZellijBackend) Kill(sessionName string) error {
+	exec.Command("zellij", "kill-session", sessionName).Run()
+	return exec.Command("zellij", "delete-session", sessionName).Run()
+}
+
+// kdlString quotes a string for a KDL document
+func kdlString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// kdlPane renders a pane running command (a shell when empty) in dir
+func kdlPane(indent, dir, command string) string {
+	if command == "" {
+		return fmt.Sprintf("%spane cwd=%s\n", indent, kdlString(dir))
+	}
+	return fmt.Sprintf("%spane cwd=%s command=\"sh\" {\n%s    args \"-c\" %s\n%s}\n", indent, kdlString(dir), indent, kdlString(command), indent)
+}
+
+// BuildLayout renders the zellij layout of a worktree session. targetWindow's tab gets the focus.
+func (b *ZellijBackend) BuildLayout(path, targetWindow string, opts SessionOptions) string {
+	var kdl strings.Builder
+	kdl.WriteString("layout {\n")
+	kdl.WriteString("    default_tab_template {\n")
+	kdl.WriteString("        pane size=1 borderless=true {\n            plugin location=\"zellij:tab-bar\"\n        }\n")
+	kdl.WriteString("        children\n")
+	kdl.WriteString("        pane size=2 borderless=true {\n            plugin location=\"zellij:status-bar\"\n        }\n")
+	kdl.WriteString("    }\n")
+
+	tab := func(name, dir string, panes []config.LayoutPane, command string) {
+		focus := ""
+		if name == targetWindow || (targetWindow == "claude" && name == branding.AgentWindowName) {
+			focus = " focus=true"
+		}
+		split := ""
+		if len(panes) > 0 && panes[0].Split == "horizontal" {
+			split = " split_direction=\"vertical\"" // Side by side
+		}
+		fmt.Fprintf(&kdl, "    tab name=%s%s%s {\n", kdlString(name), focus, split)
+		kdl.WriteString(kdlPane("        ", dir, command))
+		for _, pane := range panes {
+			kdl.WriteString(kdlPane("        ", layoutDir(path, pane.Dir), pane.Command))
+		}
+		kdl.WriteString("    }\n")
+	}
+
+	layoutWindows := make(map[string]config.LayoutWindow)
+	if opts.Layout != nil {
+		for _, window := range opts.Layout.Windows {
+			layoutWindows[window.Name] = window
+		}
+	}
+
+	terminal := layoutWindows["terminal"]
+	tab("terminal", layoutDir(path, terminal.Dir), terminal.Panes, terminal.Command)
+
+	if opts.AutoStartAgent {
+		for i, agent := range opts.Agents {
+			name := agentWindowName(agent)
+			if i == 0 {
+				name = branding.AgentWindowName
+			}
+			command := ""
+			if IsAgentInstalled(agent) {
+				id, prompt := "", ""
+				if i == 0 {
+					id, prompt = opts.ResumeID, opts.Prompt
+				}
+				command = BuildAgentCommand(agent, path, opts.Resume, id, prompt)
+			}
+			tab(name, path, nil, command)
+		}
+	}
+
+	if opts.Layout != nil {
+		for _, window := range opts.Layout.Windows {
+			if isDefaultWindow(window.Name) {
+				continue
+			}
+			tab(window.Name, layoutDir(path, window.Dir), window.Panes, window.Command)
+		}
+	}
+
+	kdl.WriteString("}\n")
+	return kdl.String()
+}
+
+// AttachCommand writes the session layout and returns a command that attaches to the
+// session, creating it from the layout if it doesn't exist yet
+func (b *ZellijBackend) AttachCommand(sessionName, path, targetWindow string, opts SessionOptions) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	dir := filepath.Join(home, ".config", branding.ConfigDirName, "zellij")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create layout directory: %w", err)
+	}
+	layoutPath := filepath.Join(dir, sessionName+".kdl")
+	if err := os.WriteFile(layoutPath, []byte(b.BuildLayout(path, targetWindow, opts)), 0644); err != nil {
+		return "", fmt.Errorf("failed to write zellij layout: %w", err)
+	}
+
+	name := shellQuote(sessionName)
+	create := withEnv(opts.Env, fmt.Sprintf("zellij --session %s --layout %s", name, shellQuote(layoutPath)))
+	return fmt.Sprintf("if zellij list-sessions --short --no-formatting 2>/dev/null | grep -qx %s; then zellij attach %s; else %s; fi", name, name, create), nil
+}
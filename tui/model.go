@@ -2,8 +2,10 @@ package tui
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -11,9 +13,11 @@ import (
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/andrew-bierman/jean-tui/config"
 	"github.com/andrew-bierman/jean-tui/git"
 	"github.com/andrew-bierman/jean-tui/github"
+	"github.com/andrew-bierman/jean-tui/internal/branding"
 	"github.com/andrew-bierman/jean-tui/internal/version"
 	"github.com/andrew-bierman/jean-tui/openrouter"
 	"github.com/andrew-bierman/jean-tui/session"
@@ -24,10 +28,13 @@ type SwitchInfo struct {
 	Path                 string
 	Branch               string
 	AutoClaude           bool
-	TargetWindow         string // Which window to attach to: "terminal" or "claude"
+	TargetWindow         string // Which window to attach to: "terminal", "claude" or a jean.json layout window name
 	ScriptCommand        string // If set, run this script command instead of shell/Claude
 	SessionName          string // Custom name for Claude session (for --session flag)
 	IsClaudeInitialized  bool   // Whether this Claude session has been initialized before
+	Backend              string // Session backend: "tmux", "zellij" or "none"
+	AttachCommand        string // sh command the wrapper runs to enter the session ("" = just cd)
+	AgentArgs            *config.AgentArgs // Main agent arguments for this launch only (nil = configured ones)
 }
 
 type modalType int
@@ -58,6 +65,25 @@ const (
 	prStateSettingsModal
 	onboardingModal
 	gitInitModal
+	moveChangesModal
+	forkModal
+	resetModal
+	exportPatchesModal
+	importPatchesModal
+	amConflictModal
+	conflictMatrixModal
+	windowSelectModal
+	agentsModal
+	notificationsModal
+	archiveListModal
+	archiveViewModal
+	scriptsModal
+	promptModal
+	reattachModal
+	processModal
+	timeReportModal
+	conversationsModal
+	agentArgsModal
 )
 
 // NotificationType defines the type of notification
@@ -82,7 +108,8 @@ type Notification struct {
 // Model represents the TUI state
 type Model struct {
 	gitManager     *git.Manager
-	sessionManager *session.Manager
+	sessionManager *session.Manager // tmux helpers (session names, layouts, agent state); tmux-only features check backend capabilities
+	backend        session.Backend  // Multiplexer sessions run in (tmux, zellij or none)
 	configManager  *config.Manager
 	githubManager  *github.Manager
 	worktrees      []git.Worktree
@@ -115,6 +142,32 @@ type Model struct {
 	// Activity tracking
 	lastActivityCheck     time.Time
 	activityCheckInterval time.Duration
+	agentStates           map[string]session.AgentState // branch -> detected agent state (from the last activity check)
+	scriptRuns            map[string][]session.ScriptRun // branch -> latest background script runs
+	lastSessionSnapshot   time.Time                      // When running sessions were last recorded for restoring
+	agentPreview          bool                           // Show the selected worktree's agent output in the details panel
+	agentPreviewBranch    string                         // Branch the captured preview belongs to
+	agentPreviewLines     []string                       // Last lines of the agent window (with ANSI colours)
+	activityTickPending   bool                           // Whether an activity tick is scheduled
+	controlClient         *session.ControlClient         // tmux control-mode client reporting session events (nil while polling)
+	controlStarting       bool                           // Whether the control client is being started
+	lastControlAttempt    time.Time                      // When the control client was last started
+	controlRefreshPending bool                           // Whether a refresh for control events is scheduled
+
+	// Sessions that can be restored after a tmux restart or reboot
+	restorableSessions []session.SessionSnapshot
+	restoreOffered     bool // Whether the user was told about restorable sessions
+
+	// Sessions that no longer match a worktree (session name -> why)
+	sessionOrphans  map[string]session.Orphan
+	reattachChoices []git.Worktree // Worktrees without a session an orphan can be handed to
+	reattachIndex   int
+
+	// Process and resource usage per session
+	sessionUsages      map[string]session.SessionUsage // session name -> processes under its panes
+	lastUsageCheck     time.Time
+	processIndex       int    // Selected process in the process modal
+	confirmKillSession string // Heaviest session waiting for a second K to be killed
 
 	// Modal state
 	modal                  modalType
@@ -243,6 +296,104 @@ type Model struct {
 
 	// Git init modal state
 	gitInitError string // Error message for git initialization
+
+	// Move changes modal state
+	moveChangesSource           string // Worktree path whose uncommitted changes are being moved
+	moveChangesSourceBranch     string // Branch of the source worktree
+	moveChangesIndex            int    // Selected target (0=new worktree, 1..n=other worktrees)
+	moveChangesIncludeStaged    bool   // Whether staged changes are moved too
+	moveChangesIncludeUntracked bool   // Whether untracked files are copied too
+
+	// Fork worktree modal state
+	forkSourcePath   string          // Worktree being forked
+	forkSourceBranch string          // Branch of the worktree being forked
+	forkPromptInput  textinput.Model // Initial prompt for the forked agent session
+	forkCarryChanges bool            // Whether uncommitted changes are copied into the fork
+	forkStartAgent   bool            // Whether to start a fresh agent session in the fork
+
+	// Reset modal state
+	resetWorktreePath   string            // Worktree being reset
+	resetBranch         string            // Branch of the worktree being reset
+	resetIndex          int               // Selected option (0=discard, 1=hard reset to base, 2=clean untracked, 3=undo last reset)
+	resetIncludeIgnored bool              // Whether clean also removes ignored files
+	resetPreview        *git.ResetPreview // Dry-run result for the selected option
+	resetConfirming     bool              // Whether the dry-run list is shown and awaiting confirmation
+	resetSafetyRefs     []string          // Safety refs available for undo (newest first)
+
+	// Patch export/import modal state
+	patchWorktreePath  string               // Worktree being exported from
+	patchBranch        string               // Branch being exported
+	exportFormatIndex  int                  // Export format (0=format-patch series, 1=bundle)
+	patchPathInput     textinput.Model      // Export destination or import source path
+	importTargetIndex  int                  // Import target (0=new worktree, 1..n=existing worktrees)
+	amWorktreePath     string               // Worktree with a stopped `git am` session
+	amBranch           string               // Branch of that worktree
+	amConflict         *git.AmConflictError // Details of the patch am stopped on
+	amOptionIndex      int                  // Selected resolution (0=continue, 1=skip, 2=abort)
+
+	// Cross-worktree conflict prediction
+	conflictMatrix        *git.ConflictMatrix // Latest predicted conflicts (nil until computed)
+	computingConflicts    bool                // Whether a conflict prediction is running
+	conflictsOutdated     bool                // Worktrees reloaded while a prediction was running, so rerun it
+	conflictMatrixScroll  int                 // Scroll offset in the conflict matrix modal
+
+	// Session layout window selection
+	layoutWindows     []string // Windows that can be opened (terminal, agent, then jean.json layout windows)
+	layoutWindowIndex int      // Selected window in the window select modal
+
+	// Agent selection
+	createAgent        string   // Agent chosen in the create modal ("" = repository default)
+	createTaskInput    textarea.Model // Task typed in the create modal: written to the task file and sent to the agent
+	agentsModalIndex   int      // Cursor in the agents modal
+	agentsModalChoices []string // Agents selected in the agents modal, in order (first is the main agent)
+
+	// Notification settings
+	notificationsIndex int // Selected option in the notifications modal
+
+	// Scrollback archive browser
+	archives           []session.Archive      // Archives of this repository's sessions, newest first
+	archiveMatches     []session.ArchiveMatch // Search results (when a search is active)
+	archiveIndex       int                    // Selected archive or match
+	archiveSearchInput textinput.Model        // Full-text search across archives
+	archiveLines       []string               // Content of the archive being viewed
+	archiveViewPath    string                 // Path of the archive being viewed
+	archiveViewTitle   string                 // Title of the viewer
+	archiveViewReturn  modalType              // Modal the viewer returns to on Esc
+	archiveViewConv    *session.Conversation  // Agent conversation shown as a transcript (reloaded by rendering it again)
+	archiveScroll      int                    // First visible line in the archive viewer
+
+	// Agent conversations of the selected worktree
+	conversations      []session.Conversation
+	conversationIndex  int
+	conversationBranch string // Branch the conversations were loaded for
+
+	// Agent arguments modal
+	agentArgsScope      int             // agentArgsRepo, agentArgsWorktree or agentArgsLaunch
+	agentArgsLaunch     bool            // Opened to start the session (offers the launch-only scope and opens it on Enter)
+	agentArgsBranch     string          // Worktree the arguments are edited for
+	agentArgsAgent      string          // Main agent of that worktree
+	agentArgsModes      []string        // Permission modes to pick from ("" = agent default first)
+	agentArgsModeIndex  int             // Selected permission mode
+	agentArgsFocus      int             // 0=scope, 1=permission mode, 2=extra arguments
+	agentArgsExtraInput textinput.Model // Extra arguments as a command line
+	agentArgsConfirm    bool            // Enter was pressed once; the next Enter restarts the running agent
+
+	// Time report
+	timeReportRows   []session.TimeReportRow // Tracked time of this repository per branch and day
+	timeReportRange  int                     // Index into timeReportRanges
+	timeReportByDay  bool                    // Show a row per branch and day instead of totals per branch
+	timeReportScroll int                     // First visible row
+
+	// Background scripts (jean.json)
+	scriptNames []string // Scripts available for the selected worktree
+	scriptIndex int      // Selected script in the scripts modal
+
+	// Sending prompts to agent sessions
+	markedWorktrees map[string]bool // Branches marked (Space) to broadcast prompts to
+	promptInput     textarea.Model  // Prompt being written
+	prompts         []config.Prompt // Prompt library (jean.json and user config)
+	promptIndex     int             // Selected prompt in the library
+	promptTargets   []string        // Branches the prompt will be sent to
 }
 
 // NewModel creates a new TUI model
@@ -294,6 +445,38 @@ func NewModel(repoPath string, autoClaude bool) Model {
 	prSearchInput.CharLimit = 100
 	prSearchInput.Width = 50
 
+	forkPromptInput := textinput.New()
+	forkPromptInput.Placeholder = "Initial prompt for the new agent session (optional)"
+	forkPromptInput.CharLimit = 2000
+	forkPromptInput.Width = 70
+
+	patchPathInput := textinput.New()
+	patchPathInput.Placeholder = "/path/to/patches"
+	patchPathInput.CharLimit = 512
+	patchPathInput.Width = 70
+
+	archiveSearchInput := textinput.New()
+	archiveSearchInput.Placeholder = "Search transcripts..."
+	archiveSearchInput.CharLimit = 200
+	archiveSearchInput.Width = 50
+
+	agentArgsExtraInput := textinput.New()
+	agentArgsExtraInput.Placeholder = "e.g. --model opus --mcp-config mcp.json"
+	agentArgsExtraInput.CharLimit = 1000
+	agentArgsExtraInput.Width = 60
+
+	createTaskInput := textarea.New()
+	createTaskInput.Placeholder = "Describe the task (optional): jean writes it to a task file and starts the agent on it"
+	createTaskInput.CharLimit = 8000
+	createTaskInput.SetWidth(70)
+	createTaskInput.SetHeight(4)
+
+	promptInput := textarea.New()
+	promptInput.Placeholder = "Prompt for the agent ({branch}, {base}, {repo} and {path} are replaced)"
+	promptInput.CharLimit = 8000
+	promptInput.SetWidth(100)
+	promptInput.SetHeight(6)
+
 	// Initialize AI prompt textareas (for customizing prompts)
 	aiPromptCommitInput := textarea.New()
 	aiPromptCommitInput.Placeholder = "Commit message prompt (must contain {diff})"
@@ -322,6 +505,13 @@ func NewModel(repoPath string, autoClaude bool) Model {
 	if root, err := gitManager.GetRepoRoot(); err == nil {
 		absoluteRepoPath = root
 	}
+	if configManager != nil {
+		// Setup scripts of new worktrees get the worktree's ports (JEAN_PORT, JEAN_PORT_<n>)
+		gitManager.SetScriptEnv(func(branch string) []string {
+			ports, _ := configManager.AllocatePorts(absoluteRepoPath, branch)
+			return config.PortEnv(ports)
+		})
+	}
 
 	// List of common editors
 	editors := []string{
@@ -353,6 +543,7 @@ func NewModel(repoPath string, autoClaude bool) Model {
 	m := Model{
 		gitManager:         gitManager,
 		sessionManager:     session.NewManager(),
+		backend:            newBackend(configManager),
 		configManager:      configManager,
 		githubManager:      github.NewManager(),
 		nameInput:          nameInput,
@@ -364,6 +555,12 @@ func NewModel(repoPath string, autoClaude bool) Model {
 		prDescriptionInput: prDescriptionInput,
 		aiAPIKeyInput:      aiAPIKeyInput,
 		prSearchInput:      prSearchInput,
+		forkPromptInput:    forkPromptInput,
+		patchPathInput:     patchPathInput,
+		archiveSearchInput: archiveSearchInput,
+		promptInput:        promptInput,
+		agentArgsExtraInput: agentArgsExtraInput,
+		createTaskInput:    createTaskInput,
 		aiPromptCommitInput: aiPromptCommitInput,
 		aiPromptBranchInput: aiPromptBranchInput,
 		aiPromptPRInput:     aiPromptPRInput,
@@ -382,6 +579,7 @@ func NewModel(repoPath string, autoClaude bool) Model {
 			m.aiAPIKeyInput.SetValue(apiKey)
 		}
 		m.aiCommitEnabled = configManager.GetAICommitEnabled()
+		m.agentPreview = configManager.GetAgentPreview()
 		m.aiBranchNameEnabled = configManager.GetAIBranchNameEnabled()
 
 		// Set model index based on saved model
@@ -431,6 +629,7 @@ func (m Model) Init() tea.Cmd {
 		m.loadSessions(),
 		m.scheduleActivityCheck(),
 		m.checkForUpdates(),
+		m.startWatcher(),
 		tea.EnterAltScreen,
 	)
 }
@@ -560,11 +759,68 @@ type (
 
 	activityTickMsg time.Time
 
-	activityCheckedMsg struct {
-		sessions []session.Session
+	controlStartedMsg struct {
+		client *session.ControlClient
+		err    error
+	}
+
+	controlEventMsg struct {
+		client *session.ControlClient
+		event  session.ControlEvent
+	}
+
+	controlClosedMsg struct {
+		client *session.ControlClient
+	}
+
+	controlRefreshMsg struct{}
+
+	timeReportLoadedMsg struct {
+		rows []session.TimeReportRow
+		err  error
+	}
+
+	archivesLoadedMsg struct {
+		archives []session.Archive
+		matches  []session.ArchiveMatch
 		err      error
 	}
 
+	activityCheckedMsg struct {
+		sessions    []session.Session
+		agentStates map[string]session.AgentState // branch -> state of its agent window
+		conversations map[string]string            // branch -> conversation its busy agent is writing
+		scriptRuns  map[string][]session.ScriptRun // branch -> latest script runs
+		err         error
+	}
+
+	conversationsLoadedMsg struct {
+		branch        string
+		conversations []session.Conversation
+	}
+
+	transcriptLoadedMsg struct {
+		conversation session.Conversation
+		entries      []session.TranscriptEntry
+		err          error
+	}
+
+	agentPreviewMsg struct {
+		branch string
+		lines  []string
+	}
+
+	promptSentMsg struct {
+		sent     []string // Branches the prompt was delivered to
+		failures []string // "branch: error" for the others
+	}
+
+	scriptStartedMsg struct {
+		branch string
+		name   string
+		err    error
+	}
+
 	commitCreatedMsg struct {
 		err        error
 		commitHash string
@@ -680,10 +936,81 @@ type (
 		err    error
 	}
 
+	changesMovedMsg struct {
+		sourceBranch string
+		targetBranch string
+		targetPath   string
+		created      bool // Whether the target worktree was created for this move
+		result       *git.TransplantResult
+		err          error
+	}
+
+	worktreeForkedMsg struct {
+		path         string
+		branch       string
+		sourceBranch string
+		conflicts    []string // Files that didn't apply cleanly when carrying changes over
+		agentStarted bool
+		warnings     []string // Non-fatal problems (setup script, copying changes, agent session) to surface to the user
+		err          error
+	}
+
+	taskBranchNamedMsg struct {
+		task string
+		name string
+		err  error
+	}
+
+	taskStartedMsg struct {
+		path         string
+		branch       string
+		file         string // Task file, relative to the worktree
+		agentStarted bool
+		warnings     []string // Non-fatal problems (setup script, task file, agent session) to surface to the user
+		err          error
+	}
+
+	resetPreviewLoadedMsg struct {
+		preview *git.ResetPreview
+		err     error
+	}
+
+	worktreeResetMsg struct {
+		branch    string
+		safetyRef string // Ref holding the pre-reset snapshot ("" for undo)
+		undo      bool
+		err       error
+	}
+
+	patchesExportedMsg struct {
+		path   string
+		count  int // Number of patch files (0 for bundles)
+		bundle bool
+		err    error
+	}
+
+	patchesImportedMsg struct {
+		worktreePath string
+		branch       string
+		created      bool   // Whether a new worktree was created for the import
+		action       string // "import", "continue", "skip" or "abort"
+		err          error
+	}
+
+	conflictMatrixLoadedMsg struct {
+		matrix *git.ConflictMatrix
+		err    error
+	}
+
 	worktreeEnsuredMsg struct {
 		err error
 	}
 
+	switchPreparedMsg struct {
+		info SwitchInfo // Switch info with the backend and attach command filled in
+		err  error
+	}
+
 )
 
 // Commands
@@ -822,10 +1149,338 @@ func (m Model) createWorktreeWithSession(path, sessionName string, newBranch boo
 		}
 
 		err := m.gitManager.Create(path, sessionName, newBranch, baseBranch)
+		if err == nil && m.createAgent != "" && m.configManager != nil {
+			_ = m.configManager.SetWorktreeAgents(m.repoPath, sessionName, []string{m.createAgent})
+		}
 		return worktreeCreatedWithSessionMsg{err: err, path: path, branch: sessionName, sessionName: sessionName}
 	}
 }
 
+// moveChanges transplants uncommitted changes from one worktree into another.
+// An empty targetPath creates a fresh worktree (random branch name off the base branch) first.
+func (m Model) moveChanges(sourcePath, sourceBranch, targetPath, targetBranch string, opts git.TransplantOptions) tea.Cmd {
+	return func() tea.Msg {
+		created := false
+		if targetPath == "" {
+			if err := m.gitManager.EnsureWorkspacesDir(); err != nil {
+				return changesMovedMsg{sourceBranch: sourceBranch, err: err}
+			}
+
+			name, err := m.gitManager.GenerateRandomName()
+			if err != nil {
+				return changesMovedMsg{sourceBranch: sourceBranch, err: err}
+			}
+			path, err := m.gitManager.GetDefaultPath(name)
+			if err != nil {
+				return changesMovedMsg{sourceBranch: sourceBranch, err: err}
+			}
+			if err := m.gitManager.Create(path, name, true, m.baseBranch); err != nil {
+				return changesMovedMsg{sourceBranch: sourceBranch, targetBranch: name, err: err}
+			}
+			targetPath = path
+			targetBranch = name
+			created = true
+		}
+
+		result, err := m.gitManager.TransplantChanges(sourcePath, targetPath, opts)
+		return changesMovedMsg{
+			sourceBranch: sourceBranch,
+			targetBranch: targetBranch,
+			targetPath:   targetPath,
+			created:      created,
+			result:       result,
+			err:          err,
+		}
+	}
+}
+
+// forkWorktree creates a new worktree whose branch starts at the source worktree's HEAD.
+// Optionally copies the source's uncommitted changes and starts a detached agent session with a prompt.
+func (m Model) forkWorktree(sourcePath, sourceBranch, branch, prompt string, carryChanges, startAgent bool) tea.Cmd {
+	return func() tea.Msg {
+		if err := m.gitManager.EnsureWorkspacesDir(); err != nil {
+			return worktreeForkedMsg{branch: branch, sourceBranch: sourceBranch, err: err}
+		}
+
+		head, err := m.gitManager.GetHeadCommit(sourcePath)
+		if err != nil {
+			return worktreeForkedMsg{branch: branch, sourceBranch: sourceBranch, err: err}
+		}
+
+		path, err := m.gitManager.GetDefaultPath(branch)
+		if err != nil {
+			return worktreeForkedMsg{branch: branch, sourceBranch: sourceBranch, err: err}
+		}
+
+		result := worktreeForkedMsg{path: path, branch: branch, sourceBranch: sourceBranch}
+		if err := m.gitManager.Create(path, branch, true, head); err != nil {
+			if !strings.Contains(err.Error(), "setup script failed") {
+				result.err = err
+				return result
+			}
+			// Worktree exists, only the setup script failed
+			result.warnings = append(result.warnings, "setup script failed")
+		}
+
+		if carryChanges {
+			transplant, err := m.gitManager.TransplantChanges(sourcePath, path, git.TransplantOptions{
+				IncludeStaged:    true,
+				IncludeUntracked: true,
+				KeepSource:       true,
+			})
+			if err != nil {
+				result.warnings = append(result.warnings, "failed to copy changes: "+err.Error())
+			} else if len(transplant.Collisions) > 0 {
+				result.warnings = append(result.warnings, "changes not copied, files already exist: "+strings.Join(transplant.Collisions, ", "))
+			} else {
+				result.conflicts = transplant.Conflicts
+			}
+		}
+
+		if m.configManager != nil && prompt != "" {
+			_ = m.configManager.SetInitialPrompt(m.repoPath, branch, prompt)
+		}
+
+		if m.configManager != nil {
+			// The fork runs the same agents with the same arguments as its source
+			if agents := m.configManager.GetWorktreeAgents(m.repoPath, sourceBranch); !slices.Equal(agents, []string{m.configManager.GetRepoAgent(m.repoPath)}) {
+				_ = m.configManager.SetWorktreeAgents(m.repoPath, branch, agents)
+			}
+			_ = m.configManager.SetWorktreeAgentArgs(m.repoPath, branch, m.configManager.GetWorktreeAgentArgs(m.repoPath, sourceBranch))
+		}
+
+		if !startAgent {
+			return result
+		}
+		if !m.backend.Capabilities().Detached {
+			result.warnings = append(result.warnings, fmt.Sprintf("background agent sessions aren't supported with %s, press Enter to start the agent", m.backend.Name()))
+			return result
+		}
+		agents := m.worktreeAgents(branch)
+		if len(agents) == 0 {
+			return result
+		}
+		opts := session.SessionOptions{
+			AutoStartAgent: true,
+			Prompt:         prompt,
+			Agents:         agents,
+			Layout:         m.sessionManager.LoadLayout(path),
+			Env:            m.worktreePortEnv(path, branch),
+		}
+		sessionName := m.sessionManager.SanitizeName(filepath.Base(m.repoPath), branch)
+		if err := m.sessionManager.PrepareSession(sessionName, path, opts); err != nil {
+			result.warnings = append(result.warnings, "failed to start agent session: "+err.Error())
+			return result
+		}
+		result.agentStarted = true
+		if m.configManager != nil {
+			// Next attach should continue this conversation
+			_ = m.configManager.SetClaudeInitialized(m.repoPath, branch)
+		}
+		return result
+	}
+}
+
+// nameTaskBranch asks the AI branch-name generator for a branch name describing a task
+func (m Model) nameTaskBranch(task string) tea.Cmd {
+	return func() tea.Msg {
+		client := openrouter.NewClient(m.configManager.GetOpenRouterAPIKey(), m.configManager.GetOpenRouterModel())
+		name, err := client.GenerateBranchNameFromTask(task)
+		if err != nil {
+			return taskBranchNamedMsg{task: task, err: fmt.Errorf("failed to generate branch name: %w", err)}
+		}
+		return taskBranchNamedMsg{task: task, name: name}
+	}
+}
+
+// taskSlug derives a branch name from the first words of a task's title (used without AI)
+func taskSlug(task string) string {
+	words := strings.Fields(config.TaskTitle(task))
+	if len(words) > 5 {
+		words = words[:5]
+	}
+	return git.SanitizeBranchName(strings.ToLower(strings.Join(words, "-")))
+}
+
+// startTask creates a worktree for a task, writes the task brief into it (templated from jean.json)
+// and starts its session in the background with the task as the agent's initial prompt
+func (m Model) startTask(path, branch, task string) tea.Cmd {
+	agent := m.createAgent
+	return func() tea.Msg {
+		if err := m.gitManager.EnsureWorkspacesDir(); err != nil {
+			return taskStartedMsg{branch: branch, err: err}
+		}
+
+		result := taskStartedMsg{path: path, branch: branch}
+		if err := m.gitManager.Create(path, branch, true, m.baseBranch); err != nil {
+			if !strings.Contains(err.Error(), "setup script failed") {
+				result.err = err
+				return result
+			}
+			// Worktree exists, only the setup script failed
+			result.warnings = append(result.warnings, "setup script failed")
+		}
+		if agent != "" && m.configManager != nil {
+			_ = m.configManager.SetWorktreeAgents(m.repoPath, branch, []string{agent})
+		}
+
+		// The worktree's jean.json (from the base branch) decides the task file, falling back to the main repository's
+		scripts, _ := config.LoadScripts(path)
+		if scripts == nil || scripts.Task == nil {
+			scripts, _ = config.LoadScripts(m.repoPath)
+		}
+		taskConfig := scripts.GetTask()
+		result.file = taskConfig.File
+		content, prompt := taskConfig.Render(task, map[string]string{
+			"branch": branch,
+			"base":   m.baseBranch,
+			"repo":   filepath.Base(m.repoPath),
+			"path":   path,
+		})
+		if err := m.gitManager.WriteTaskFile(path, taskConfig.File, content, taskConfig.Track); err != nil {
+			result.warnings = append(result.warnings, err.Error())
+		}
+		if m.configManager != nil {
+			_ = m.configManager.SetInitialPrompt(m.repoPath, branch, prompt)
+		}
+
+		if !m.backend.Capabilities().Detached {
+			result.warnings = append(result.warnings, fmt.Sprintf("background agent sessions aren't supported with %s, press Enter and point the agent at %s", m.backend.Name(), taskConfig.File))
+			return result
+		}
+		agents := m.worktreeAgents(branch)
+		if len(agents) == 0 {
+			return result // Blank terminal mode: the task file is all there is
+		}
+		opts := session.SessionOptions{
+			AutoStartAgent: true,
+			Prompt:         prompt,
+			Agents:         agents,
+			Layout:         m.sessionManager.LoadLayout(path),
+			Env:            m.worktreePortEnv(path, branch),
+		}
+		sessionName := m.sessionManager.SanitizeName(filepath.Base(m.repoPath), branch)
+		if err := m.sessionManager.PrepareSession(sessionName, path, opts); err != nil {
+			result.warnings = append(result.warnings, "failed to start agent session: "+err.Error())
+			return result
+		}
+		result.agentStarted = true
+		if m.configManager != nil {
+			// Next attach should continue this conversation
+			_ = m.configManager.SetClaudeInitialized(m.repoPath, branch)
+		}
+		return result
+	}
+}
+
+// previewReset computes the dry-run list of files and commits affected by a reset
+func (m Model) previewReset(worktreePath string, mode git.ResetMode, includeIgnored bool) tea.Cmd {
+	return func() tea.Msg {
+		preview, err := m.gitManager.PreviewReset(worktreePath, mode, m.baseBranch, includeIgnored)
+		return resetPreviewLoadedMsg{preview: preview, err: err}
+	}
+}
+
+// resetWorktree resets a worktree after taking a safety snapshot
+func (m Model) resetWorktree(worktreePath, branch string, mode git.ResetMode, includeIgnored bool) tea.Cmd {
+	return func() tea.Msg {
+		ref, err := m.gitManager.ResetWorktree(worktreePath, branch, mode, m.baseBranch, includeIgnored)
+		return worktreeResetMsg{branch: branch, safetyRef: ref, err: err}
+	}
+}
+
+// undoReset restores a worktree from a safety ref taken before a reset
+func (m Model) undoReset(worktreePath, branch, safetyRef string) tea.Cmd {
+	return func() tea.Msg {
+		err := m.gitManager.UndoReset(worktreePath, safetyRef)
+		return worktreeResetMsg{branch: branch, undo: true, err: err}
+	}
+}
+
+// defaultExportPath returns the default destination for exporting a branch's patches or bundle
+func (m Model) defaultExportPath(branch string, bundle bool) string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = os.TempDir()
+	}
+	name := m.sessionManager.SanitizeBranchName(filepath.Base(m.repoPath) + "-" + branch)
+	if bundle {
+		name += ".bundle"
+	}
+	return filepath.Join(home, branding.CLIName+"-exports", name)
+}
+
+// exportPatches exports a worktree's commits ahead of the base branch as a patch series or bundle
+func (m Model) exportPatches(worktreePath, branch, dest string, bundle bool) tea.Cmd {
+	return func() tea.Msg {
+		if bundle {
+			err := m.gitManager.ExportBundle(worktreePath, branch, m.baseBranch, dest)
+			return patchesExportedMsg{path: dest, bundle: true, err: err}
+		}
+		files, err := m.gitManager.ExportPatches(worktreePath, m.baseBranch, dest)
+		return patchesExportedMsg{path: dest, count: len(files), err: err}
+	}
+}
+
+// importPatches applies a patch series or bundle to a worktree.
+// An empty targetPath creates a fresh worktree off the base branch first.
+func (m Model) importPatches(source, targetPath, targetBranch string) tea.Cmd {
+	return func() tea.Msg {
+		created := false
+		if targetPath == "" {
+			if err := m.gitManager.EnsureWorkspacesDir(); err != nil {
+				return patchesImportedMsg{action: "import", err: err}
+			}
+			name, err := m.gitManager.GenerateRandomName()
+			if err != nil {
+				return patchesImportedMsg{action: "import", err: err}
+			}
+			path, err := m.gitManager.GetDefaultPath(name)
+			if err != nil {
+				return patchesImportedMsg{action: "import", err: err}
+			}
+			if err := m.gitManager.Create(path, name, true, m.baseBranch); err != nil && !strings.Contains(err.Error(), "setup script failed") {
+				return patchesImportedMsg{action: "import", err: err}
+			}
+			targetPath, targetBranch, created = path, name, true
+		}
+
+		err := m.gitManager.ImportPatches(targetPath, source)
+		return patchesImportedMsg{worktreePath: targetPath, branch: targetBranch, created: created, action: "import", err: err}
+	}
+}
+
+// resolveAm continues, skips or aborts a stopped `git am` session
+func (m Model) resolveAm(worktreePath, branch, action string) tea.Cmd {
+	return func() tea.Msg {
+		var err error
+		switch action {
+		case "continue":
+			err = m.gitManager.AmContinue(worktreePath)
+		case "skip":
+			err = m.gitManager.AmSkip(worktreePath)
+		case "abort":
+			err = m.gitManager.AmAbort(worktreePath)
+		}
+		return patchesImportedMsg{worktreePath: worktreePath, branch: branch, action: action, err: err}
+	}
+}
+
+// computeConflicts predicts conflicts of every worktree branch against the base branch and each other
+func (m Model) computeConflicts() tea.Cmd {
+	var branches []string
+	for _, wt := range m.worktrees {
+		if wt.Branch != "" {
+			branches = append(branches, wt.Branch)
+		}
+	}
+	baseBranch := m.baseBranch
+	return func() tea.Msg {
+		matrix, err := m.gitManager.ComputeConflictMatrix(branches, baseBranch)
+		return conflictMatrixLoadedMsg{matrix: matrix, err: err}
+	}
+}
+
 func (m Model) deleteWorktree(path, branch string, force bool) tea.Cmd {
 	return func() tea.Msg {
 		// First remove the worktree
@@ -840,10 +1495,14 @@ func (m Model) deleteWorktree(path, branch string, force bool) tea.Cmd {
 			_ = m.configManager.CleanupBranch(m.repoPath, branch) // Ignore error, not critical
 		}
 
+		// Drop reset safety snapshots for the deleted branch
+		_ = m.gitManager.DeleteSafetyRefs(branch)
+
 		// Then kill the associated tmux session if it exists
 		repoName := filepath.Base(m.repoPath)
 		sessionName := m.sessionManager.SanitizeName(repoName, branch)
-		_ = m.sessionManager.Kill(sessionName) // Ignore error if session doesn't exist
+		_ = m.backend.Kill(sessionName) // Ignore error if session doesn't exist
+		_ = session.RemoveScriptRuns(sessionName)
 
 		return worktreeDeletedMsg{err: nil}
 	}
@@ -889,6 +1548,38 @@ func (m Model) ensureWorktreeExists(worktreePath, branch string) tea.Cmd {
 	}
 }
 
+// prepareSwitch prepares the session of a switch (agents, jean.json layout, ports) in the background;
+// the shell wrapper then just runs the command that enters it
+func (m Model) prepareSwitch(info SwitchInfo) tea.Cmd {
+	return func() tea.Msg {
+		info.Backend = m.backend.Name()
+		if info.ScriptCommand != "" {
+			return switchPreparedMsg{info: info}
+		}
+		agents := m.worktreeAgents(info.Branch)
+		if info.AgentArgs != nil {
+			agents = m.worktreeAgentsWithArgs(info.Branch, *info.AgentArgs)
+		}
+		opts := session.SessionOptions{
+			AutoStartAgent: info.AutoClaude,
+			Resume:         info.IsClaudeInitialized,
+			ResumeID:       m.resumeID(info.Branch),
+			Agents:         agents,
+			Layout:         m.sessionManager.LoadLayout(info.Path),
+			Env:            m.worktreePortEnv(info.Path, info.Branch),
+		}
+		if info.AgentArgs != nil && info.AutoClaude && len(agents) > 0 && info.Backend == session.BackendTmux && m.sessionManager.SessionExists(info.SessionName) {
+			// Arguments picked for this launch replace the running agent (confirmed in the modal)
+			if err := m.sessionManager.RestartAgent(info.SessionName, info.Path, agents[0], info.IsClaudeInitialized, opts.ResumeID); err != nil {
+				m.debugLog(fmt.Sprintf("DEBUG: failed to restart agent: %v", err))
+			}
+		}
+		attachCommand, err := m.backend.AttachCommand(info.SessionName, info.Path, info.TargetWindow, opts)
+		info.AttachCommand = attachCommand
+		return switchPreparedMsg{info: info, err: err}
+	}
+}
+
 func (m Model) renameBranch(oldName, newName, worktreePath string) tea.Cmd {
 	return func() tea.Msg {
 		// Rename the git branch only - keep the directory path unchanged
@@ -926,6 +1617,8 @@ func (m Model) renameSessionsForBranch(oldBranch, newBranch string) tea.Cmd {
 		// Rename session
 		if err := m.sessionManager.RenameSession(oldSessionName, newSessionName); err != nil {
 			// Log error but continue (session might not exist)
+		} else if m.sessionManager.SessionExists(newSessionName) {
+			_ = m.sessionManager.TagSession(newSessionName, session.SessionMeta{Branch: newBranch})
 		}
 
 		return nil
@@ -1633,6 +2326,77 @@ func (m Model) selectedWorktree() *git.Worktree {
 	return &m.worktrees[m.selectedIndex]
 }
 
+// moveChangesTargets returns the worktrees that changes can be moved into (all except the source)
+func (m Model) moveChangesTargets() []git.Worktree {
+	var targets []git.Worktree
+	for _, wt := range m.worktrees {
+		if wt.Path != m.moveChangesSource {
+			targets = append(targets, wt)
+		}
+	}
+	return targets
+}
+
+// agentNames returns the names of all available agents
+func (m Model) agentNames() []string {
+	var names []string
+	if m.configManager == nil {
+		for _, agent := range config.BuiltinAgents() {
+			names = append(names, agent.Name)
+		}
+		return names
+	}
+	for _, agent := range m.configManager.GetAgents() {
+		names = append(names, agent.Name)
+	}
+	return names
+}
+
+// worktreeAgents resolves the agents selected for a worktree (falls back to the repository default).
+// The main agent gets the worktree's launch arguments.
+func (m Model) worktreeAgents(branch string) []config.AgentDefinition {
+	if m.configManager == nil {
+		return m.worktreeAgentsWithArgs(branch, config.AgentArgs{})
+	}
+	return m.worktreeAgentsWithArgs(branch, m.configManager.GetAgentArgs(m.repoPath, branch))
+}
+
+// worktreeAgentsWithArgs resolves the agents of a worktree, launching the main agent with args
+func (m Model) worktreeAgentsWithArgs(branch string, args config.AgentArgs) []config.AgentDefinition {
+	if m.configManager == nil {
+		if branding.IsAgentEnabled() {
+			return []config.AgentDefinition{session.DefaultAgent()}
+		}
+		return nil
+	}
+
+	var agents []config.AgentDefinition
+	for _, name := range m.configManager.GetWorktreeAgents(m.repoPath, branch) {
+		if agent, ok := m.configManager.GetAgent(name); ok {
+			agents = append(agents, agent)
+		}
+	}
+	if len(agents) > 0 {
+		agents[0] = agents[0].WithArgs(args)
+	}
+	return agents
+}
+
+// resumeID returns the recorded conversation a worktree's main agent resumes ("" = its last one)
+func (m Model) resumeID(branch string) string {
+	if m.configManager == nil {
+		return ""
+	}
+	conv, ok := m.configManager.GetConversation(m.repoPath, branch)
+	if !ok {
+		return ""
+	}
+	if agents := m.worktreeAgents(branch); len(agents) == 0 || agents[0].Name != conv.Agent {
+		return "" // Recorded for another agent
+	}
+	return conv.ID
+}
+
 func (m Model) selectedBranch() string {
 	// Use filtered branches if search is active
 	branches := m.branches
@@ -1694,16 +2458,105 @@ func (m Model) GetConfigManager() *config.Manager {
 // loadSessions loads tmux sessions for the current repository only
 func (m Model) loadSessions() tea.Cmd {
 	return func() tea.Msg {
-		sessions, err := m.sessionManager.List(m.repoPath)
+		if m.backend.Name() == session.BackendTmux {
+			// Tag sessions from older versions so they resolve exactly (best effort)
+			_, _ = m.sessionManager.MigrateSessions()
+		}
+		sessions, err := m.backend.List(m.repoPath)
 		if err != nil {
 			return statusMsg("Failed to load sessions")
 		}
-		return sessionsLoadedMsg{sessions: sessions}
+		var restorable []session.SessionSnapshot
+		if m.backend.Capabilities().Capture {
+			restorable = m.sessionManager.RestorableSessions()
+		}
+		orphans := make(map[string]session.Orphan)
+		for _, sess := range sessions {
+			if sess.Path == "" {
+				continue // The backend doesn't report session directories
+			}
+			if orphan := m.sessionManager.CheckOrphan(sess); orphan.Kind != session.NotOrphaned {
+				orphans[sess.Name] = orphan
+			}
+		}
+		return sessionsLoadedMsg{sessions: sessions, restorable: restorable, orphans: orphans}
 	}
 }
 
 type sessionsLoadedMsg struct {
-	sessions []session.Session
+	sessions   []session.Session
+	restorable []session.SessionSnapshot // Recorded sessions gone after a tmux restart or reboot
+	orphans    map[string]session.Orphan // Sessions that no longer match a worktree
+}
+
+type sessionUsageMsg struct {
+	usages map[string]session.SessionUsage
+}
+
+// loadSessionUsage measures the CPU and memory used by the processes of each listed session
+func (m Model) loadSessionUsage() tea.Cmd {
+	if !m.backend.Capabilities().Capture || len(m.sessions) == 0 {
+		return nil
+	}
+	names := make([]string, len(m.sessions))
+	for i, sess := range m.sessions {
+		names[i] = sess.Name
+	}
+	return func() tea.Msg {
+		return sessionUsageMsg{usages: m.sessionManager.SessionUsages(names)}
+	}
+}
+
+type orphansKilledMsg struct {
+	killed int
+	err    error
+}
+
+// killOrphans kills the sessions whose worktree is gone (renamed branches are left for renaming,
+// sessions that couldn't be checked are left alone)
+func (m Model) killOrphans() tea.Cmd {
+	var names []string
+	for _, sess := range m.sessions {
+		if orphan, ok := m.sessionOrphans[sess.Name]; ok && orphan.Prunable() {
+			names = append(names, sess.Name)
+		}
+	}
+	return func() tea.Msg {
+		var failures []string
+		killed := 0
+		for _, name := range names {
+			if err := m.backend.Kill(name); err != nil {
+				failures = append(failures, name)
+				continue
+			}
+			killed++
+		}
+		if len(failures) > 0 {
+			return orphansKilledMsg{killed: killed, err: fmt.Errorf("failed to kill %s", strings.Join(failures, ", "))}
+		}
+		return orphansKilledMsg{killed: killed}
+	}
+}
+
+type sessionsRestoredMsg struct {
+	restored []string
+	err      error
+}
+
+// restoreSessions recreates the sessions recorded before a tmux restart or reboot
+func (m Model) restoreSessions() tea.Cmd {
+	return func() tea.Msg {
+		restored, err := m.sessionManager.RestoreSessions(m.configManager)
+		return sessionsRestoredMsg{restored: restored, err: err}
+	}
+}
+
+// saveSessionSnapshot records the running sessions so they can be restored after a reboot
+func (m Model) saveSessionSnapshot() tea.Cmd {
+	return func() tea.Msg {
+		_ = m.sessionManager.SaveSessionSnapshot()
+		return nil
+	}
 }
 
 // pullFromBaseBranch pulls changes from the base branch into the worktree
@@ -1912,6 +2765,60 @@ func (m Model) scheduleActivityCheck() tea.Cmd {
 	})
 }
 
+// controlRetryInterval is how often starting the tmux control client is retried while polling
+const controlRetryInterval = 10 * time.Second
+
+// controlRefreshDelay batches the bursts of control events a single tmux change produces
+const controlRefreshDelay = 150 * time.Millisecond
+
+// startControlClient attaches a tmux control-mode client so session changes arrive as events
+func (m Model) startControlClient() tea.Cmd {
+	return func() tea.Msg {
+		client, err := session.StartControlClient()
+		return controlStartedMsg{client: client, err: err}
+	}
+}
+
+// closeControlClient detaches the control client before jean quits or hands the terminal to tmux
+func (m *Model) closeControlClient() {
+	if m.controlClient != nil {
+		m.controlClient.Close()
+		m.controlClient = nil
+	}
+}
+
+// waitForControlEvent waits for the next notification of the control client
+func waitForControlEvent(client *session.ControlClient) tea.Cmd {
+	return func() tea.Msg {
+		event, ok := <-client.Events()
+		if !ok {
+			return controlClosedMsg{client: client}
+		}
+		return controlEventMsg{client: client, event: event}
+	}
+}
+
+// needsActivityPolling reports whether activity checks must keep polling although control events arrive:
+// agent state and script status come from pane contents and files rather than tmux events
+func (m Model) needsActivityPolling() bool {
+	if m.modal == sessionListModal || m.modal == processModal {
+		return true // Resource usage is refreshed with the activity checks
+	}
+	for _, state := range m.agentStates {
+		if state == session.AgentStateBusy {
+			return true
+		}
+	}
+	for _, runs := range m.scriptRuns {
+		for _, run := range runs {
+			if run.Status == session.ScriptRunning {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // animateSpinner sends a spinner tick message with 100ms interval
 // Continues animating as long as generatingCommit is true
 func (m Model) animateSpinner() tea.Cmd {
@@ -1927,14 +2834,400 @@ func (m Model) animateRenameSpinner() tea.Cmd {
 	})
 }
 
+// loadArchives lists this repository's scrollback archives, searching them when query is set
+func (m Model) loadArchives(query string) tea.Cmd {
+	return func() tea.Msg {
+		prefix := m.sessionManager.SanitizeName(filepath.Base(m.repoPath), "")
+		archives, err := session.ListArchives(prefix)
+		if err != nil {
+			return archivesLoadedMsg{err: err}
+		}
+		var matches []session.ArchiveMatch
+		if query != "" {
+			matches = session.SearchArchives(archives, query)
+		}
+		return archivesLoadedMsg{archives: archives, matches: matches}
+	}
+}
+
+// loadConversations lists the main agent's past conversations in a worktree
+func (m Model) loadConversations(wt git.Worktree) tea.Cmd {
+	agents := m.worktreeAgents(wt.Branch)
+	return func() tea.Msg {
+		msg := conversationsLoadedMsg{branch: wt.Branch}
+		if len(agents) > 0 {
+			msg.conversations = session.ListConversations(agents[0], wt.Path)
+		}
+		return msg
+	}
+}
+
+// loadTranscript reads the messages and tool calls of one of the main agent's conversations
+func (m Model) loadTranscript(branch string, conv session.Conversation) tea.Cmd {
+	agents := m.worktreeAgents(branch)
+	return func() tea.Msg {
+		if len(agents) == 0 {
+			return transcriptLoadedMsg{conversation: conv, err: fmt.Errorf("no agent configured")}
+		}
+		entries, err := session.ReadTranscript(agents[0], conv)
+		return transcriptLoadedMsg{conversation: conv, entries: entries, err: err}
+	}
+}
+
+// Scopes of the agent arguments modal
+const (
+	agentArgsRepo     = iota // Repository default
+	agentArgsWorktree        // Selected worktree (overrides the repository default)
+	agentArgsLaunch          // Only the session being opened
+)
+
+// agentArgsScopes returns the scopes the agent arguments modal offers
+func (m Model) agentArgsScopes() []int {
+	if m.agentArgsLaunch {
+		return []int{agentArgsLaunch, agentArgsWorktree, agentArgsRepo}
+	}
+	if m.agentArgsBranch == "" {
+		return []int{agentArgsRepo} // No worktree selected
+	}
+	return []int{agentArgsRepo, agentArgsWorktree}
+}
+
+// openAgentArgsModal opens the agent arguments editor for a worktree's main agent.
+// With launch, Enter opens the worktree's session with the arguments.
+func (m *Model) openAgentArgsModal(branch string, launch bool) {
+	m.modal = agentArgsModal
+	m.agentArgsLaunch = launch
+	m.agentArgsBranch = branch
+	m.agentArgsScope = m.agentArgsScopes()[0]
+	m.agentArgsFocus = 1
+	m.agentArgsConfirm = false
+
+	agent := session.DefaultAgent()
+	if agents := m.worktreeAgentsWithArgs(branch, config.AgentArgs{}); len(agents) > 0 {
+		agent = agents[0]
+	}
+	m.agentArgsAgent = agent.Name
+	m.agentArgsModes = append([]string{""}, agent.PermissionModes...)
+	m.loadAgentArgsScope()
+}
+
+// agentArgsRestarts reports whether launching with the modal's arguments restarts a running agent
+func (m Model) agentArgsRestarts() bool {
+	if !m.agentArgsLaunch || m.backend.Name() != session.BackendTmux || len(m.worktreeAgents(m.agentArgsBranch)) == 0 {
+		return false
+	}
+	for _, wt := range m.worktrees {
+		if wt.Branch == m.agentArgsBranch {
+			return m.sessionManager.SessionExists(wt.ClaudeSessionName)
+		}
+	}
+	return false
+}
+
+// loadAgentArgsScope shows the arguments stored for the selected scope
+func (m *Model) loadAgentArgsScope() {
+	var args config.AgentArgs
+	if m.configManager != nil {
+		switch m.agentArgsScope {
+		case agentArgsRepo:
+			args = m.configManager.GetRepoAgentArgs(m.repoPath)
+		case agentArgsWorktree:
+			args = m.configManager.GetWorktreeAgentArgs(m.repoPath, m.agentArgsBranch)
+		case agentArgsLaunch:
+			args = m.configManager.GetAgentArgs(m.repoPath, m.agentArgsBranch)
+		}
+	}
+	m.agentArgsModeIndex = 0
+	for i, mode := range m.agentArgsModes {
+		if mode == args.PermissionMode {
+			m.agentArgsModeIndex = i
+		}
+	}
+	m.agentArgsExtraInput.SetValue(session.FormatArgs(args.ExtraArgs))
+	m.agentArgsExtraInput.CursorEnd()
+	m.focusAgentArgsInput()
+}
+
+// focusAgentArgsInput focuses the extra arguments input when its field is selected
+func (m *Model) focusAgentArgsInput() {
+	if m.agentArgsFocus == 2 {
+		m.agentArgsExtraInput.Focus()
+	} else {
+		m.agentArgsExtraInput.Blur()
+	}
+}
+
+// agentArgsInput returns the arguments entered in the agent arguments modal
+func (m Model) agentArgsInput() (config.AgentArgs, error) {
+	extra, err := session.SplitArgs(m.agentArgsExtraInput.Value())
+	if err != nil {
+		return config.AgentArgs{}, err
+	}
+	return config.AgentArgs{PermissionMode: m.agentArgsModes[m.agentArgsModeIndex], ExtraArgs: extra}, nil
+}
+
+// timeReportRanges are the periods the time report can cover (days back, including today)
+var timeReportRanges = []struct {
+	label string
+	days  int
+}{
+	{"Today", 1},
+	{"Last 7 days", 7},
+	{"Last 30 days", 30},
+}
+
+// timeReportSince returns the start of the selected time report period
+func (m Model) timeReportSince() time.Time {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	return today.AddDate(0, 0, 1-timeReportRanges[m.timeReportRange].days)
+}
+
+// loadTimeReport loads the time tracked in this repository during the selected period
+func (m Model) loadTimeReport() tea.Cmd {
+	since := m.timeReportSince()
+	return func() tea.Msg {
+		rows, err := session.TimeReport(since, m.repoPath)
+		return timeReportLoadedMsg{rows: rows, err: err}
+	}
+}
+
+// visibleTimeReportRows returns the report rows shown for the current mode
+func (m Model) visibleTimeReportRows() []session.TimeReportRow {
+	if m.timeReportByDay {
+		return m.timeReportRows
+	}
+	return session.BranchTotals(m.timeReportRows)
+}
+
+// archiveBranch returns the branch part of an archive's session name
+func (m Model) archiveBranch(archive session.Archive) string {
+	prefix := m.sessionManager.SanitizeName(filepath.Base(m.repoPath), "")
+	return strings.TrimPrefix(archive.Session, prefix)
+}
+
+// isArchiveClosed reports whether an archive belongs to a worktree that no longer exists
+func (m Model) isArchiveClosed(archive session.Archive) bool {
+	for _, wt := range m.worktrees {
+		if wt.ClaudeSessionName == archive.Session {
+			return false
+		}
+	}
+	return true
+}
+
+// newBackend returns the configured session backend (tmux without a config)
+func newBackend(configManager *config.Manager) session.Backend {
+	if configManager == nil {
+		return session.NewManager()
+	}
+	return session.NewBackend(configManager.GetMultiplexer())
+}
+
+// startWatcher launches the background agent watcher so alerts, scrollback autosave and time tracking keep working after the TUI exits
+func (m Model) startWatcher() tea.Cmd {
+	return func() tea.Msg {
+		if m.configManager == nil || !m.backend.Capabilities().Capture {
+			return nil // The watcher reads tmux panes
+		}
+		if !m.configManager.GetNotificationConfig().Enabled() && m.configManager.GetScrollbackAutosave() == 0 && !m.configManager.GetTimeTracking() {
+			return nil
+		}
+		if err := session.StartWatcherProcess(session.CurrentTTY()); err != nil {
+			m.debugLog(fmt.Sprintf("DEBUG: %v", err))
+		}
+		return nil
+	}
+}
+
 // checkSessionActivity checks for recent session activity in current repository
 func (m Model) checkSessionActivity() tea.Cmd {
 	return func() tea.Msg {
-		sessions, err := m.sessionManager.List(m.repoPath)
+		sessions, err := m.backend.List(m.repoPath)
+		if err != nil {
+			return activityCheckedMsg{sessions: []session.Session{}, scriptRuns: m.collectScriptRuns(), err: err}
+		}
+
+		// Classify the agent in each worktree's session from its pane output
+		running := make(map[string]bool)
+		for _, sess := range sessions {
+			running[sess.Name] = true
+		}
+		agentStates := make(map[string]session.AgentState)
+		conversations := make(map[string]string)
+		for _, wt := range m.worktrees {
+			if !running[wt.ClaudeSessionName] || !m.backend.Capabilities().Capture {
+				continue
+			}
+			agent := session.DefaultAgent()
+			if agents := m.worktreeAgents(wt.Branch); len(agents) > 0 {
+				agent = agents[0]
+			}
+			if state := m.sessionManager.DetectAgentState(wt.ClaudeSessionName, agent); state != session.AgentStateNone {
+				agentStates[wt.Branch] = state
+			}
+			// The conversation a busy agent is writing to is the one to resume next time
+			if agentStates[wt.Branch] == session.AgentStateBusy {
+				if id, updated, ok := session.CurrentConversationID(agent, wt.Path); ok && time.Since(updated) < time.Minute {
+					conversations[wt.Branch] = id
+				}
+			}
+		}
+
+		return activityCheckedMsg{sessions: sessions, agentStates: agentStates, conversations: conversations, scriptRuns: m.collectScriptRuns(), err: nil}
+	}
+}
+
+// captureAgentPreview captures the last lines of the selected worktree's agent window
+func (m Model) captureAgentPreview() tea.Cmd {
+	wt := m.selectedWorktree()
+	if wt == nil || !m.backend.Capabilities().Capture {
+		return nil
+	}
+	lineCount := 15
+	if m.configManager != nil {
+		lineCount = m.configManager.GetAgentPreviewLines()
+	}
+	branch, sessionName := wt.Branch, wt.ClaudeSessionName
+	return func() tea.Msg {
+		if !m.sessionManager.SessionExists(sessionName) {
+			return agentPreviewMsg{branch: branch}
+		}
+		output, err := m.sessionManager.CaptureAgentOutput(sessionName)
+		if err != nil {
+			return agentPreviewMsg{branch: branch}
+		}
+		lines := strings.Split(output, "\n")
+		// Drop the empty rows below the agent's output
+		for len(lines) > 0 && strings.TrimSpace(ansi.Strip(lines[len(lines)-1])) == "" {
+			lines = lines[:len(lines)-1]
+		}
+		if len(lines) > lineCount {
+			lines = lines[len(lines)-lineCount:]
+		}
+		return agentPreviewMsg{branch: branch, lines: lines}
+	}
+}
+
+// worktreePortEnv returns the port environment of a worktree, allocating its ports if needed.
+// Only worktrees in .workspaces get ports; the main checkout and detached worktrees have none.
+func (m Model) worktreePortEnv(path, branch string) []string {
+	if m.configManager == nil || branch == "" || strings.HasPrefix(branch, "(detached") {
+		return nil
+	}
+	if rel, err := filepath.Rel(filepath.Join(m.repoPath, ".workspaces"), path); err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return nil
+	}
+	ports, err := m.configManager.AllocatePorts(m.repoPath, branch)
+	if err != nil {
+		m.debugLog(fmt.Sprintf("DEBUG: failed to allocate ports for %s: %v", branch, err))
+		return nil
+	}
+	return config.PortEnv(ports)
+}
+
+// collectScriptRuns loads the latest background script runs of every worktree
+func (m Model) collectScriptRuns() map[string][]session.ScriptRun {
+	runs := make(map[string][]session.ScriptRun)
+	for _, wt := range m.worktrees {
+		if wtRuns := session.ListScriptRuns(wt.ClaudeSessionName); len(wtRuns) > 0 {
+			runs[wt.Branch] = wtRuns
+		}
+	}
+	return runs
+}
+
+// loadPrompts returns the prompt library: jean.json prompts of a worktree (falling back to the
+// repository root) merged with the prompts from the user config
+func (m Model) loadPrompts(worktreePath string) []config.Prompt {
+	scripts, err := config.LoadScripts(worktreePath)
+	if err != nil || len(scripts.Prompts) == 0 {
+		scripts, _ = config.LoadScripts(m.repoPath)
+	}
+	var repoPrompts, userPrompts map[string]string
+	if scripts != nil {
+		repoPrompts = scripts.Prompts
+	}
+	if m.configManager != nil {
+		userPrompts = m.configManager.GetAgentPrompts()
+	}
+	return config.MergePrompts(repoPrompts, userPrompts)
+}
+
+// promptTargetBranches returns the worktrees a prompt goes to: the marked ones, or else the selected one
+func (m Model) promptTargetBranches() []string {
+	var branches []string
+	for _, wt := range m.worktrees {
+		if m.markedWorktrees[wt.Branch] {
+			branches = append(branches, wt.Branch)
+		}
+	}
+	if len(branches) == 0 {
+		if wt := m.selectedWorktree(); wt != nil {
+			branches = append(branches, wt.Branch)
+		}
+	}
+	return branches
+}
+
+// sendPrompt types a prompt into the agent window of each target worktree's session,
+// replacing {branch}, {base}, {repo} and {path} per worktree
+func (m Model) sendPrompt(prompt string, branches []string) tea.Cmd {
+	worktrees := make(map[string]git.Worktree)
+	for _, wt := range m.worktrees {
+		worktrees[wt.Branch] = wt
+	}
+	return func() tea.Msg {
+		var result promptSentMsg
+		for _, branch := range branches {
+			wt, ok := worktrees[branch]
+			if !ok {
+				continue
+			}
+			text := config.ExpandPrompt(prompt, map[string]string{
+				"branch": wt.Branch,
+				"base":   m.baseBranch,
+				"repo":   filepath.Base(m.repoPath),
+				"path":   wt.Path,
+			})
+			if err := m.sessionManager.SendPrompt(wt.ClaudeSessionName, text); err != nil {
+				result.failures = append(result.failures, fmt.Sprintf("%s: %v", branch, err))
+				continue
+			}
+			result.sent = append(result.sent, branch)
+		}
+		return result
+	}
+}
+
+// loadScriptNames returns the jean.json scripts of a worktree (falling back to the repository root)
+func (m Model) loadScriptNames(worktreePath string) []string {
+	scripts, err := config.LoadScripts(worktreePath)
+	if err != nil || !scripts.HasScripts() {
+		scripts, err = config.LoadScripts(m.repoPath)
 		if err != nil {
-			return activityCheckedMsg{sessions: []session.Session{}, err: err}
+			return nil
+		}
+	}
+	return scripts.GetScriptNames()
+}
+
+// runScript starts a jean.json script in the background in a worktree
+func (m Model) runScript(wt git.Worktree, name string) tea.Cmd {
+	return func() tea.Msg {
+		scripts, err := config.LoadScripts(wt.Path)
+		if err != nil || scripts.GetScript(name) == "" {
+			scripts, err = config.LoadScripts(m.repoPath)
+		}
+		if err != nil {
+			return scriptStartedMsg{branch: wt.Branch, name: name, err: err}
+		}
+		command := scripts.GetScript(name)
+		if command == "" {
+			return scriptStartedMsg{branch: wt.Branch, name: name, err: fmt.Errorf("script %q not found in jean.json", name)}
 		}
-		return activityCheckedMsg{sessions: sessions, err: nil}
+		_, err = session.RunScript(wt.ClaudeSessionName, wt.Path, m.repoPath, name, command, m.worktreePortEnv(wt.Path, wt.Branch))
+		return scriptStartedMsg{branch: wt.Branch, name: name, err: err}
 	}
 }
 
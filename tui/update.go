@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -11,6 +12,8 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/andrew-bierman/jean-tui/config"
 	"github.com/andrew-bierman/jean-tui/git"
+	"github.com/andrew-bierman/jean-tui/internal/branding"
+	"github.com/andrew-bierman/jean-tui/session"
 	"github.com/andrew-bierman/jean-tui/github"
 )
 
@@ -141,7 +144,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 		// After first successful worktree load, check if we need to show onboarding
-		return m, tea.Batch(cmd, m.checkOnboardingStatus())
+		// Conflict prediction runs in the background and updates list badges when done.
+		// Only one runs at a time; a reload during a run is picked up once it finishes.
+		if m.computingConflicts {
+			m.conflictsOutdated = true
+			return m, tea.Batch(cmd, m.checkOnboardingStatus())
+		}
+		m.computingConflicts = true
+		return m, tea.Batch(cmd, m.checkOnboardingStatus(), m.computeConflicts())
 
 	case worktreeStatusUpdatedMsg:
 		// Update individual worktree with loaded status data (no blocking, progressive update)
@@ -153,6 +163,21 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case conflictMatrixLoadedMsg:
+		m.computingConflicts = false
+		if msg.err != nil {
+			// Non-critical (e.g. git older than 2.38) - keep previous results
+			m.debugLog("Conflict prediction failed: " + msg.err.Error())
+		} else {
+			m.conflictMatrix = msg.matrix
+		}
+		if m.conflictsOutdated {
+			m.conflictsOutdated = false
+			m.computingConflicts = true
+			return m, m.computeConflicts()
+		}
+		return m, nil
+
 	case onboardingStatusMsg:
 		// If user needs onboarding and we haven't shown it yet, show the modal
 		if msg.needsOnboarding {
@@ -407,6 +432,150 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			)
 		}
 
+	case changesMovedMsg:
+		if msg.err != nil {
+			cmd = m.showErrorNotification("Failed to move changes: "+msg.err.Error(), 5*time.Second)
+			if msg.created {
+				return m, tea.Batch(cmd, m.loadWorktrees())
+			}
+			return m, cmd
+		}
+
+		if msg.created {
+			// Select the newly created worktree after reload
+			m.lastCreatedBranch = msg.targetBranch
+		}
+
+		if msg.result != nil && len(msg.result.Collisions) > 0 {
+			cmd = m.showErrorNotification(fmt.Sprintf("Nothing moved: %s already exist(s) in %s",
+				strings.Join(msg.result.Collisions, ", "), msg.targetBranch), 8*time.Second)
+		} else if msg.result != nil && len(msg.result.Conflicts) > 0 {
+			conflictMsg := fmt.Sprintf("Changes applied to %s with %d conflict(s): %s. Source left unchanged.",
+				msg.targetBranch, len(msg.result.Conflicts), strings.Join(msg.result.Conflicts, ", "))
+			cmd = m.showErrorNotification(conflictMsg, 8*time.Second)
+		} else {
+			successMsg := fmt.Sprintf("Moved changes from %s to %s", msg.sourceBranch, msg.targetBranch)
+			if msg.result != nil && msg.result.BackupStash != "" {
+				successMsg += fmt.Sprintf(" (backup stash %s)", msg.result.BackupStash)
+			}
+			cmd = m.showSuccessNotification(successMsg, 4*time.Second)
+		}
+		return m, tea.Batch(cmd, m.loadWorktrees())
+
+	case worktreeForkedMsg:
+		if msg.err != nil {
+			cmd = m.showErrorNotification("Failed to fork worktree: "+msg.err.Error(), 5*time.Second)
+			return m, cmd
+		}
+
+		m.lastCreatedBranch = msg.branch
+		warnings := msg.warnings
+		if len(msg.conflicts) > 0 {
+			warnings = append([]string{fmt.Sprintf("%d file(s) conflicted: %s", len(msg.conflicts), strings.Join(msg.conflicts, ", "))}, warnings...)
+		}
+		if len(warnings) > 0 {
+			cmd = m.showWarningNotification(fmt.Sprintf("Forked %s → %s, but %s", msg.sourceBranch, msg.branch, strings.Join(warnings, "; ")))
+		} else if msg.agentStarted {
+			cmd = m.showSuccessNotification(fmt.Sprintf("Forked %s → %s with a new agent session running", msg.sourceBranch, msg.branch), 4*time.Second)
+		} else {
+			cmd = m.showSuccessNotification(fmt.Sprintf("Forked %s → %s", msg.sourceBranch, msg.branch), 3*time.Second)
+		}
+		return m, tea.Batch(cmd, m.loadWorktrees(), m.loadSessions())
+
+	case taskBranchNamedMsg:
+		name := msg.name
+		if msg.err != nil {
+			// Fall back to the task's first words
+			m.debugLog(fmt.Sprintf("DEBUG: %v", msg.err))
+			name = taskSlug(msg.task)
+		}
+		return m.createTask(name, msg.task)
+
+	case taskStartedMsg:
+		if msg.err != nil {
+			cmd = m.showErrorNotification("Failed to start task: "+msg.err.Error(), 5*time.Second)
+			return m, cmd
+		}
+
+		m.lastCreatedBranch = msg.branch
+		if len(msg.warnings) > 0 {
+			cmd = m.showWarningNotification(fmt.Sprintf("Task worktree %s created, but %s", msg.branch, strings.Join(msg.warnings, "; ")))
+		} else if msg.agentStarted {
+			cmd = m.showSuccessNotification(fmt.Sprintf("Task started in %s (brief in %s), press Enter to attach", msg.branch, msg.file), 4*time.Second)
+		} else {
+			cmd = m.showSuccessNotification(fmt.Sprintf("Task worktree %s created (brief in %s)", msg.branch, msg.file), 3*time.Second)
+		}
+		return m, tea.Batch(cmd, m.loadWorktrees(), m.loadSessions())
+
+	case resetPreviewLoadedMsg:
+		if msg.err != nil {
+			cmd = m.showErrorNotification("Failed to preview reset: "+msg.err.Error(), 4*time.Second)
+			return m, cmd
+		}
+		if m.modal != resetModal {
+			return m, nil
+		}
+		if len(msg.preview.Files) == 0 && len(msg.preview.Commits) == 0 {
+			return m, m.showInfoNotification("Nothing to reset - worktree already clean")
+		}
+		m.resetPreview = msg.preview
+		m.resetConfirming = true
+		return m, nil
+
+	case worktreeResetMsg:
+		if msg.err != nil {
+			cmd = m.showErrorNotification(msg.err.Error(), 5*time.Second)
+			return m, tea.Batch(cmd, m.loadWorktrees())
+		}
+		if msg.undo {
+			cmd = m.showSuccessNotification("Reset undone for "+msg.branch, 3*time.Second)
+		} else {
+			cmd = m.showSuccessNotification(fmt.Sprintf("Worktree reset. Undo with Shift+R (snapshot %s)", msg.safetyRef), 5*time.Second)
+		}
+		return m, tea.Batch(cmd, m.loadWorktrees())
+
+	case patchesExportedMsg:
+		if msg.err != nil {
+			cmd = m.showErrorNotification("Failed to export: "+msg.err.Error(), 5*time.Second)
+			return m, cmd
+		}
+		if msg.bundle {
+			cmd = m.showSuccessNotification("Bundle written to "+msg.path, 5*time.Second)
+		} else {
+			cmd = m.showSuccessNotification(fmt.Sprintf("Exported %d patch(es) to %s", msg.count, msg.path), 5*time.Second)
+		}
+		return m, cmd
+
+	case patchesImportedMsg:
+		if msg.created {
+			m.lastCreatedBranch = msg.branch
+		}
+		var conflictErr *git.AmConflictError
+		if errors.As(msg.err, &conflictErr) {
+			// am stopped on a conflict - let the user resolve, skip or abort
+			m.amWorktreePath = msg.worktreePath
+			m.amBranch = msg.branch
+			m.amConflict = conflictErr
+			m.amOptionIndex = 0
+			m.modal = amConflictModal
+			return m, tea.Batch(m.showWarningNotification("Patch import stopped: "+conflictErr.Error()), m.loadWorktrees())
+		}
+		if msg.err != nil {
+			cmd = m.showErrorNotification(msg.err.Error(), 5*time.Second)
+			if msg.action == "continue" && m.gitManager.IsAmInProgress(msg.worktreePath) {
+				// Still unresolved - keep the conflict modal open
+				m.modal = amConflictModal
+			}
+			return m, tea.Batch(cmd, m.loadWorktrees())
+		}
+		switch msg.action {
+		case "abort":
+			cmd = m.showSuccessNotification("Patch import aborted", 3*time.Second)
+		default:
+			cmd = m.showSuccessNotification("Patches applied to "+msg.branch, 3*time.Second)
+		}
+		return m, tea.Batch(cmd, m.loadWorktrees())
+
 	case branchRenamedMsg:
 		if msg.err != nil {
 			cmd = m.showErrorNotification("Failed to rename branch", 4*time.Second)
@@ -476,8 +645,40 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case sessionsLoadedMsg:
 		m.sessions = msg.sessions
+		m.restorableSessions = msg.restorable
+		m.sessionOrphans = msg.orphans
+		if m.modal == sessionListModal {
+			m.lastUsageCheck = time.Now()
+			cmd = m.loadSessionUsage()
+		}
+		if len(msg.restorable) > 0 && !m.restoreOffered {
+			m.restoreOffered = true
+			return m, tea.Batch(cmd, m.showInfoNotification(fmt.Sprintf("%d session(s) from before a tmux restart can be restored (S, then r)", len(msg.restorable))))
+		}
+		return m, cmd
+
+	case sessionUsageMsg:
+		m.sessionUsages = msg.usages
 		return m, nil
 
+	case orphansKilledMsg:
+		var notifyCmd tea.Cmd
+		if msg.err != nil {
+			notifyCmd = m.showErrorNotification(msg.err.Error(), 4*time.Second)
+		} else {
+			notifyCmd = m.showSuccessNotification(fmt.Sprintf("Killed %d orphaned session(s)", msg.killed), 3*time.Second)
+		}
+		return m, tea.Batch(notifyCmd, m.loadSessions())
+
+	case sessionsRestoredMsg:
+		var notifyCmd tea.Cmd
+		if msg.err != nil {
+			notifyCmd = m.showErrorNotification(msg.err.Error(), 5*time.Second)
+		} else {
+			notifyCmd = m.showSuccessNotification(fmt.Sprintf("Restored %d session(s)", len(msg.restored)), 3*time.Second)
+		}
+		return m, tea.Batch(notifyCmd, m.loadSessions())
+
 	case editorOpenedMsg:
 		if msg.err != nil {
 			cmd = m.showErrorNotification("Failed to open editor: " + msg.err.Error(), 4*time.Second)
@@ -1087,23 +1288,162 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, m.loadWorktrees()
 
 	case activityTickMsg:
+		m.activityTickPending = false
 		// Check if enough time has passed since last activity check
 		if time.Since(m.lastActivityCheck) >= m.activityCheckInterval {
 			m.lastActivityCheck = time.Now()
 			cmd = m.checkSessionActivity()
 			return m, cmd
 		}
+		m.activityTickPending = true
 		return m, m.scheduleActivityCheck()
 
+	case controlStartedMsg:
+		m.controlStarting = false
+		if msg.err != nil {
+			// No tmux session to attach to yet (or tmux before 3.2): keep polling
+			m.debugLog(fmt.Sprintf("DEBUG: %v", msg.err))
+			return m, nil
+		}
+		m.controlClient = msg.client
+		return m, waitForControlEvent(msg.client)
+
+	case controlEventMsg:
+		if msg.client != m.controlClient {
+			return m, nil
+		}
+		cmd = waitForControlEvent(msg.client)
+		if !m.controlRefreshPending {
+			m.controlRefreshPending = true
+			cmd = tea.Batch(cmd, tea.Tick(controlRefreshDelay, func(time.Time) tea.Msg {
+				return controlRefreshMsg{}
+			}))
+		}
+		return m, cmd
+
+	case controlRefreshMsg:
+		m.controlRefreshPending = false
+		m.lastActivityCheck = time.Now()
+		return m, m.checkSessionActivity()
+
+	case controlClosedMsg:
+		if msg.client != m.controlClient {
+			return m, nil
+		}
+		// The server exited or the client's session was killed: refresh, which resumes polling
+		m.controlClient = nil
+		return m, m.checkSessionActivity()
+
 	case activityCheckedMsg:
 		if msg.err == nil {
 			// Update sessions with activity information
 			m.sessions = msg.sessions
+			m.agentStates = msg.agentStates
+			m.recordConversations(msg.conversations)
+		}
+		m.scriptRuns = msg.scriptRuns
+		// Keep polling unless control events report changes (see needsActivityPolling)
+		if (m.controlClient == nil || m.needsActivityPolling()) && !m.activityTickPending {
+			m.activityTickPending = true
+			cmd = m.scheduleActivityCheck()
+		}
+		if m.controlClient == nil && !m.controlStarting && m.backend.Name() == session.BackendTmux &&
+			time.Since(m.lastControlAttempt) >= controlRetryInterval {
+			m.controlStarting = true
+			m.lastControlAttempt = time.Now()
+			cmd = tea.Batch(cmd, m.startControlClient())
+		}
+		if len(msg.sessions) > 0 && m.backend.Capabilities().Capture && time.Since(m.lastSessionSnapshot) >= session.SnapshotInterval {
+			m.lastSessionSnapshot = time.Now()
+			cmd = tea.Batch(cmd, m.saveSessionSnapshot())
+		}
+		if m.agentPreview {
+			// Refresh the agent output preview with every activity check
+			cmd = tea.Batch(cmd, m.captureAgentPreview())
+		}
+		if (m.modal == sessionListModal || m.modal == processModal) && time.Since(m.lastUsageCheck) >= 3*time.Second {
+			// Keep resource usage current while it's shown
+			m.lastUsageCheck = time.Now()
+			cmd = tea.Batch(cmd, m.loadSessionUsage())
 		}
-		// Continue scheduling activity checks
-		cmd = m.scheduleActivityCheck()
 		return m, cmd
 
+	case conversationsLoadedMsg:
+		m.conversationBranch = msg.branch
+		m.conversations = msg.conversations
+		m.conversationIndex = 0
+		return m, nil
+
+	case transcriptLoadedMsg:
+		if msg.err != nil {
+			return m, m.showErrorNotification(msg.err.Error(), 3*time.Second)
+		}
+		reload := m.modal == archiveViewModal && m.archiveViewConv != nil && m.archiveViewConv.Path == msg.conversation.Path
+		if !reload && m.modal != conversationsModal {
+			return m, nil // Closed while loading
+		}
+		atEnd := reload && m.archiveScroll >= len(m.archiveLines)-m.archiveViewHeight()
+		m.archiveLines = transcriptLines(msg.entries, msg.conversation.Agent, max(m.width-12, 40))
+		if !reload {
+			conv := msg.conversation
+			m.archiveSearchInput.SetValue("") // No search highlighting in transcripts
+			m.archiveViewConv = &conv
+			m.archiveViewPath = conv.Path
+			m.archiveViewTitle = fmt.Sprintf("Transcript: %s - %s", m.conversationBranch, conv.Started.Local().Format("Jan 02 15:04"))
+			m.archiveViewReturn = conversationsModal
+			m.archiveScroll = 0
+			m.modal = archiveViewModal
+		}
+		maxScroll := max(len(m.archiveLines)-m.archiveViewHeight(), 0)
+		if atEnd || m.archiveScroll > maxScroll {
+			m.archiveScroll = maxScroll
+		}
+		return m, nil
+
+	case agentPreviewMsg:
+		m.agentPreviewBranch = msg.branch
+		m.agentPreviewLines = msg.lines
+		return m, nil
+
+	case scriptStartedMsg:
+		if msg.err != nil {
+			return m, m.showErrorNotification(fmt.Sprintf("Failed to run %s: %v", msg.name, msg.err), 4*time.Second)
+		}
+		if m.scriptRuns == nil {
+			m.scriptRuns = make(map[string][]session.ScriptRun)
+		}
+		if wt := m.selectedWorktree(); wt != nil && wt.Branch == msg.branch {
+			m.scriptRuns[msg.branch] = session.ListScriptRuns(wt.ClaudeSessionName)
+		}
+		return m, m.showInfoNotification(fmt.Sprintf("Running %s in %s (in the background)", msg.name, msg.branch))
+
+	case promptSentMsg:
+		if len(msg.failures) > 0 {
+			cmd = m.showErrorNotification(fmt.Sprintf("Sent to %d of %d: %s", len(msg.sent), len(msg.sent)+len(msg.failures), strings.Join(msg.failures, "; ")), 5*time.Second)
+			return m, cmd
+		}
+		if len(msg.sent) == 1 {
+			return m, m.showSuccessNotification("Prompt sent to "+msg.sent[0], 2*time.Second)
+		}
+		return m, m.showSuccessNotification(fmt.Sprintf("Prompt sent to %d sessions", len(msg.sent)), 2*time.Second)
+
+	case timeReportLoadedMsg:
+		if msg.err != nil {
+			return m, m.showErrorNotification("Failed to load time report: "+msg.err.Error(), 3*time.Second)
+		}
+		m.timeReportRows = msg.rows
+		m.timeReportScroll = 0
+		return m, nil
+
+	case archivesLoadedMsg:
+		if msg.err != nil {
+			return m, m.showErrorNotification("Failed to load archives: "+msg.err.Error(), 3*time.Second)
+		}
+		m.archives = msg.archives
+		m.archiveMatches = msg.matches
+		m.archiveIndex = 0
+		return m, nil
+
 	case versionCheckMsg:
 		// Silently handle errors (don't show error notification for version check failures)
 		if msg.err != nil {
@@ -1354,11 +1694,19 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		// Worktree is now ensured to exist, proceed with switch
 		if m.pendingSwitchInfo != nil {
-			m.switchInfo = *m.pendingSwitchInfo
+			info := *m.pendingSwitchInfo
 			m.pendingSwitchInfo = nil
-			return m, tea.Quit
+			return m, m.prepareSwitch(info)
 		}
 
+	case switchPreparedMsg:
+		if msg.err != nil {
+			return m, m.showErrorNotification(fmt.Sprintf("Failed to prepare %s session: %v", msg.info.Backend, msg.err), 4*time.Second)
+		}
+		m.switchInfo = msg.info
+		m.closeControlClient()
+		return m, tea.Quit
+
 	case prMarkedReadyMsg:
 		// PR has been marked as ready for review
 		if msg.err != nil {
@@ -1419,6 +1767,7 @@ func (m Model) handleMainInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "q", "ctrl+c":
 		// Clear switch info to prevent shell wrapper from switching directories
 		m.switchInfo = SwitchInfo{}
+		m.closeControlClient()
 		return m, tea.Quit
 
 	case "up":
@@ -1450,6 +1799,9 @@ func (m Model) handleMainInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.sessionNameInput.SetValue("")  // Start with empty input
 		m.sessionNameInput.Focus()       // Focus the input field
 		m.modalFocused = 0               // Focus on input field
+		m.createAgent = ""               // Use the repository's default agent
+		m.createTaskInput.Reset()        // No task: a plain worktree
+		m.createTaskInput.Blur()
 		return m, nil
 
 	case "b":
@@ -1495,33 +1847,14 @@ func (m Model) handleMainInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "enter":
 		// Switch to selected worktree with Claude
 		if wt := m.selectedWorktree(); wt != nil {
-			// Save the last selected branch before switching
-			if m.configManager != nil {
-				_ = m.configManager.SetLastSelectedBranch(m.repoPath, wt.Branch)
-			}
-			// Check if this Claude session has been initialized before
-			isInitialized := false
-			if m.configManager != nil {
-				isInitialized = m.configManager.IsClaudeInitialized(m.repoPath, wt.Branch)
-				// Mark this branch as initialized for next time
-				// (so next run will use --continue instead of plain claude)
-				if m.autoClaude && !isInitialized {
-					_ = m.configManager.SetClaudeInitialized(m.repoPath, wt.Branch)
-				}
-			}
-			// Store pending switch info and ensure worktree exists
-			// SessionName includes repo basename for uniqueness across repositories (e.g., jean-reponame-branch)
-			m.pendingSwitchInfo = &SwitchInfo{
-				Path:                 wt.Path,
-				Branch:               wt.Branch,
-				SessionName:          wt.ClaudeSessionName, // Pre-sanitized session name with repo basename
-				AutoClaude:           m.autoClaude,
-				TargetWindow:         "claude", // Attach to Claude window
-				IsClaudeInitialized:  isInitialized,
-			}
-			m.ensuringWorktree = true
-			cmd = m.showInfoNotification("Preparing workspace...")
-			return m, tea.Batch(cmd, m.ensureWorktreeExists(wt.Path, wt.Branch))
+			return m.switchToAgent(*wt, nil)
+		}
+
+	case "G":
+		// Open the session with different agent arguments (permission mode, extra flags)
+		if wt := m.selectedWorktree(); wt != nil {
+			m.openAgentArgsModal(wt.Branch, true)
+			return m, nil
 		}
 
 	case "B":
@@ -1881,6 +2214,237 @@ func (m Model) handleMainInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+	case "m":
+		// Move uncommitted changes to another worktree
+		if wt := m.selectedWorktree(); wt != nil {
+			hasUncommitted, err := m.gitManager.HasUncommittedChanges(wt.Path)
+			if err != nil {
+				return m, m.showErrorNotification("Failed to check for uncommitted changes: "+err.Error(), 3*time.Second)
+			}
+			if !hasUncommitted {
+				return m, m.showInfoNotification("No uncommitted changes to move in " + wt.Branch)
+			}
+
+			m.moveChangesSource = wt.Path
+			m.moveChangesSourceBranch = wt.Branch
+			m.moveChangesIndex = 0 // Default to new worktree
+			m.moveChangesIncludeStaged = true
+			m.moveChangesIncludeUntracked = true
+			m.modal = moveChangesModal
+			return m, nil
+		}
+
+	case "F":
+		// Fork selected worktree: new branch from its HEAD (Shift+F)
+		if wt := m.selectedWorktree(); wt != nil {
+			hasUncommitted, err := m.gitManager.HasUncommittedChanges(wt.Path)
+			if err != nil {
+				return m, m.showErrorNotification("Failed to check for uncommitted changes: "+err.Error(), 3*time.Second)
+			}
+
+			m.forkSourcePath = wt.Path
+			m.forkSourceBranch = wt.Branch
+			m.forkCarryChanges = hasUncommitted
+			m.forkStartAgent = m.autoClaude
+			m.forkPromptInput.SetValue("")
+			if m.configManager != nil {
+				m.forkPromptInput.SetValue(m.configManager.GetInitialPrompt(m.repoPath, wt.Branch))
+			}
+			m.forkPromptInput.Blur()
+			m.sessionNameInput.SetValue("")
+			m.sessionNameInput.Focus()
+			m.modalFocused = 0
+			m.modal = forkModal
+			return m, nil
+		}
+
+	case "R":
+		// Reset/clean worktree back to base (Shift+R)
+		if wt := m.selectedWorktree(); wt != nil {
+			// Only allow on workspace worktrees, never the main repo
+			if !strings.Contains(wt.Path, ".workspaces") {
+				return m, m.showWarningNotification("Can only reset workspace worktrees. Use git manually in main repo.")
+			}
+
+			m.resetWorktreePath = wt.Path
+			m.resetBranch = wt.Branch
+			m.resetIndex = 0
+			m.resetIncludeIgnored = false
+			m.resetPreview = nil
+			m.resetConfirming = false
+			m.resetSafetyRefs, _ = m.gitManager.ListSafetyRefs(wt.Branch)
+			m.modal = resetModal
+			return m, nil
+		}
+
+	case "x":
+		// Export commits ahead of base as patch series or bundle
+		if wt := m.selectedWorktree(); wt != nil {
+			if m.baseBranch == "" {
+				return m, m.showWarningNotification("Base branch not set. Press 'b' to set base branch")
+			}
+			m.patchWorktreePath = wt.Path
+			m.patchBranch = wt.Branch
+			m.exportFormatIndex = 0
+			m.patchPathInput.SetValue(m.defaultExportPath(wt.Branch, false))
+			m.patchPathInput.Blur()
+			m.modalFocused = 0
+			m.modal = exportPatchesModal
+			return m, nil
+		}
+
+	case "i":
+		// Import patch series or bundle (or resolve a stopped import in the selected worktree)
+		if wt := m.selectedWorktree(); wt != nil && m.gitManager.IsAmInProgress(wt.Path) {
+			m.amWorktreePath = wt.Path
+			m.amBranch = wt.Branch
+			m.amConflict = m.gitManager.CurrentAmConflict(wt.Path)
+			m.amOptionIndex = 0
+			m.modal = amConflictModal
+			return m, nil
+		}
+		m.patchPathInput.SetValue("")
+		m.patchPathInput.Focus()
+		m.importTargetIndex = 0
+		m.modalFocused = 0
+		m.modal = importPatchesModal
+		return m, nil
+
+	case "w":
+		// Pick a window (terminal, agent or jean.json layout window) to open
+		if wt := m.selectedWorktree(); wt != nil {
+			m.layoutWindows = []string{"terminal", "claude"}
+			if layout := m.sessionManager.LoadLayout(wt.Path); layout != nil {
+				for _, name := range layout.GetWindowNames() {
+					if name != "terminal" && name != "claude" && name != branding.AgentWindowName {
+						m.layoutWindows = append(m.layoutWindows, name)
+					}
+				}
+			}
+			m.layoutWindowIndex = 0
+			m.modal = windowSelectModal
+			return m, nil
+		}
+
+	case "A":
+		// Choose the agents that run in the selected worktree's session (Shift+A)
+		if wt := m.selectedWorktree(); wt != nil {
+			m.agentsModalIndex = 0
+			m.agentsModalChoices = nil
+			if m.configManager != nil {
+				m.agentsModalChoices = append(m.agentsModalChoices, m.configManager.GetWorktreeAgents(m.repoPath, wt.Branch)...)
+			}
+			m.modal = agentsModal
+			return m, nil
+		}
+
+	case "X":
+		// Run a jean.json script in the background (Shift+X)
+		if wt := m.selectedWorktree(); wt != nil {
+			m.scriptNames = m.loadScriptNames(wt.Path)
+			if len(m.scriptNames) == 0 {
+				return m, m.showWarningNotification("No scripts defined in jean.json")
+			}
+			m.scriptIndex = 0
+			m.modal = scriptsModal
+			return m, nil
+		}
+
+	case "O":
+		// View the output of the most recent script run (Shift+O)
+		if wt := m.selectedWorktree(); wt != nil {
+			runs := m.scriptRuns[wt.Branch]
+			if len(runs) == 0 {
+				return m, m.showWarningNotification("No script runs for this worktree (press X to run one)")
+			}
+			latest := runs[0]
+			for _, run := range runs[1:] {
+				if run.Start.After(latest.Start) {
+					latest = run
+				}
+			}
+			return m.openScriptOutput(wt.Branch, latest, noModal)
+		}
+
+	case "V":
+		// Toggle the live agent output preview in the details panel (Shift+V)
+		if !m.backend.Capabilities().Capture {
+			return m, m.showWarningNotification(fmt.Sprintf("Agent output preview isn't supported with %s", m.backend.Name()))
+		}
+		m.agentPreview = !m.agentPreview
+		if m.configManager != nil {
+			_ = m.configManager.SetAgentPreview(m.agentPreview)
+		}
+		if m.agentPreview {
+			return m, m.captureAgentPreview()
+		}
+		return m, nil
+
+	case " ":
+		// Mark or unmark the selected worktree for broadcasting prompts
+		if wt := m.selectedWorktree(); wt != nil {
+			if m.markedWorktrees == nil {
+				m.markedWorktrees = make(map[string]bool)
+			}
+			if m.markedWorktrees[wt.Branch] {
+				delete(m.markedWorktrees, wt.Branch)
+			} else {
+				m.markedWorktrees[wt.Branch] = true
+			}
+		}
+
+	case "I":
+		// Send a prompt to the agent of the marked worktrees, or the selected one (Shift+I)
+		if !m.backend.Capabilities().Input {
+			return m, m.showWarningNotification(fmt.Sprintf("Sending prompts isn't supported with %s", m.backend.Name()))
+		}
+		if wt := m.selectedWorktree(); wt != nil {
+			m.promptTargets = m.promptTargetBranches()
+			m.prompts = m.loadPrompts(wt.Path)
+			m.promptIndex = 0
+			m.modalFocused = 0
+			m.promptInput.Reset()
+			m.modal = promptModal
+			return m, m.promptInput.Focus()
+		}
+
+	case "H":
+		// Browse archived session transcripts (Shift+H)
+		m.modal = archiveListModal
+		m.modalFocused = 1
+		m.archiveIndex = 0
+		m.archiveMatches = nil
+		m.archiveSearchInput.SetValue("")
+		m.archiveSearchInput.Blur()
+		return m, m.loadArchives("")
+
+	case "E":
+		// Browse the agent's past conversations in the selected worktree (Shift+E)
+		if wt := m.selectedWorktree(); wt != nil {
+			m.modal = conversationsModal
+			m.conversations = nil
+			m.conversationIndex = 0
+			m.conversationBranch = wt.Branch
+			return m, m.loadConversations(*wt)
+		}
+
+	case "T":
+		// Time report of this repository's worktrees (Shift+T)
+		m.modal = timeReportModal
+		m.timeReportRows = nil
+		m.timeReportScroll = 0
+		return m, m.loadTimeReport()
+
+	case "C":
+		// Open cross-worktree conflict matrix (Shift+C)
+		m.modal = conflictMatrixModal
+		m.conflictMatrixScroll = 0
+		if m.conflictMatrix == nil && !m.computingConflicts {
+			m.computingConflicts = true
+			return m, m.computeConflicts()
+		}
+		return m, nil
+
 	case "h":
 		// Open help modal
 		m.modal = helperModal
@@ -1964,8 +2528,65 @@ func (m Model) handleModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case gitInitModal:
 		return m.handleGitInitModalInput(msg)
 
+	case moveChangesModal:
+		return m.handleMoveChangesModalInput(msg)
+
+	case forkModal:
+		return m.handleForkModalInput(msg)
+
+	case resetModal:
+		return m.handleResetModalInput(msg)
+
+	case exportPatchesModal:
+		return m.handleExportPatchesModalInput(msg)
+
+	case importPatchesModal:
+		return m.handleImportPatchesModalInput(msg)
+
+	case amConflictModal:
+		return m.handleAmConflictModalInput(msg)
+
+	case conflictMatrixModal:
+		return m.handleConflictMatrixModalInput(msg)
+
+	case windowSelectModal:
+		return m.handleWindowSelectModalInput(msg)
+
+	case agentsModal:
+		return m.handleAgentsModalInput(msg)
+
+	case notificationsModal:
+		return m.handleNotificationsModalInput(msg)
+
+	case archiveListModal:
+		return m.handleArchiveListModalInput(msg)
+
+	case timeReportModal:
+		return m.handleTimeReportModalInput(msg)
+
+	case conversationsModal:
+		return m.handleConversationsModalInput(msg)
+
+	case archiveViewModal:
+		return m.handleArchiveViewModalInput(msg)
+
+	case scriptsModal:
+		return m.handleScriptsModalInput(msg)
+
+	case promptModal:
+		return m.handlePromptModalInput(msg)
+
+	case reattachModal:
+		return m.handleReattachModalInput(msg)
+
+	case processModal:
+		return m.handleProcessModalInput(msg)
+
 	case helperModal:
 		return m.handleHelperModalInput(msg)
+
+	case agentArgsModal:
+		return m.handleAgentArgsModalInput(msg)
 	}
 
 	return m, cmd
@@ -2189,78 +2810,148 @@ func (m Model) handleCreateModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) handleCreateWithNameModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "esc":
-		m.modal = noModal
-		m.sessionNameInput.Blur()
-		return m, nil
+	// Focus order: 0=name, 1=task, 2=create button, 3=cancel button
+	const focusCount = 4
 
-	case "tab", "shift+tab":
-		// Cycle through: sessionNameInput -> create button -> cancel button
-		m.modalFocused = (m.modalFocused + 1) % 3
-		if m.modalFocused == 0 {
+	setFocus := func(m *Model, focus int) tea.Cmd {
+		m.modalFocused = focus
+		m.sessionNameInput.Blur()
+		m.createTaskInput.Blur()
+		switch focus {
+		case 0:
 			m.sessionNameInput.Focus()
-		} else {
-			m.sessionNameInput.Blur()
+		case 1:
+			return m.createTaskInput.Focus()
 		}
-		return m, nil
+		return nil
+	}
 
-	case "enter":
-		if m.modalFocused == 0 {
-			// In input, move to create button
-			m.modalFocused = 1
-			m.sessionNameInput.Blur()
-			return m, nil
-		} else if m.modalFocused == 1 {
-			// Create button
-			sessionName := m.sessionNameInput.Value()
+	switch msg.String() {
+	case "esc":
+		m.modal = noModal
+		m.sessionNameInput.Blur()
+		m.createTaskInput.Blur()
+		return m, nil
 
-			// If empty, generate a random name
-			if sessionName == "" {
-				randomName, err := m.gitManager.GenerateRandomName()
-				if err != nil {
-					cmd := m.showWarningNotification("Failed to generate random name")
-					return m, cmd
-				}
-				sessionName = randomName
+	case "ctrl+n":
+		// Cycle through agents: repository default, then every available agent
+		choices := append([]string{""}, m.agentNames()...)
+		for i, name := range choices {
+			if name == m.createAgent {
+				m.createAgent = choices[(i+1)%len(choices)]
+				break
 			}
+		}
+		return m, nil
+
+	case "tab":
+		// Cycle through: sessionNameInput -> task -> create button -> cancel button
+		return m, setFocus(&m, (m.modalFocused+1)%focusCount)
+
+	case "shift+tab":
+		return m, setFocus(&m, (m.modalFocused+focusCount-1)%focusCount)
 
-			// Sanitize the session name to ensure it's a valid branch name
-			sanitizedName := m.sessionManager.SanitizeBranchName(sessionName)
-			if sanitizedName == "" {
-				cmd := m.showWarningNotification("Session name contains no valid characters")
+	case "enter", "ctrl+s":
+		if msg.String() == "enter" {
+			switch m.modalFocused {
+			case 0:
+				// In input, move to create button
+				return m, setFocus(&m, 2)
+			case 1:
+				// New line in the task
+				var cmd tea.Cmd
+				m.createTaskInput, cmd = m.createTaskInput.Update(msg)
 				return m, cmd
+			case 3:
+				// Cancel button
+				m.modal = noModal
+				m.sessionNameInput.Blur()
+				m.createTaskInput.Blur()
+				return m, nil
+			}
+		}
+
+		// Create button (or ctrl+s anywhere)
+		sessionName := m.sessionNameInput.Value()
+		task := strings.TrimSpace(m.createTaskInput.Value())
+		m.sessionNameInput.Blur()
+		m.createTaskInput.Blur()
+
+		if task != "" {
+			// Start a task: name the branch after it (with AI when configured)
+			if sessionName == "" && m.configManager != nil && m.configManager.GetOpenRouterAPIKey() != "" && m.configManager.GetAIBranchNameEnabled() {
+				m.modal = noModal
+				cmd := m.showInfoNotification("🤖 Generating branch name from task...")
+				return m, tea.Batch(cmd, m.nameTaskBranch(task))
+			}
+			if sessionName == "" {
+				sessionName = taskSlug(task)
 			}
+			return m.createTask(sessionName, task)
+		}
 
-			// Generate path from sanitized session name
-			path, err := m.gitManager.GetDefaultPath(sanitizedName)
+		// If empty, generate a random name
+		if sessionName == "" {
+			randomName, err := m.gitManager.GenerateRandomName()
 			if err != nil {
-				cmd := m.showWarningNotification("Failed to generate workspace path")
+				cmd := m.showWarningNotification("Failed to generate random name")
 				return m, cmd
 			}
+			sessionName = randomName
+		}
 
-			m.modal = noModal
-			m.sessionNameInput.Blur()
-			notificationMsg := fmt.Sprintf("Creating worktree: %s\n  Path: %s\n  Claude will automatically continue previous conversations", sanitizedName, path)
-			cmd := m.showInfoNotification(notificationMsg)
-			return m, tea.Batch(cmd, m.createWorktreeWithSession(path, sanitizedName, true))
-		} else {
-			// Cancel button (modalFocused == 2)
-			m.modal = noModal
-			m.sessionNameInput.Blur()
-			return m, nil
+		// Sanitize the session name to ensure it's a valid branch name
+		sanitizedName := m.sessionManager.SanitizeBranchName(sessionName)
+		if sanitizedName == "" {
+			cmd := m.showWarningNotification("Session name contains no valid characters")
+			return m, cmd
 		}
+
+		// Generate path from sanitized session name
+		path, err := m.gitManager.GetDefaultPath(sanitizedName)
+		if err != nil {
+			cmd := m.showWarningNotification("Failed to generate workspace path")
+			return m, cmd
+		}
+
+		m.modal = noModal
+		notificationMsg := fmt.Sprintf("Creating worktree: %s\n  Path: %s\n  Claude will automatically continue previous conversations", sanitizedName, path)
+		cmd := m.showInfoNotification(notificationMsg)
+		return m, tea.Batch(cmd, m.createWorktreeWithSession(path, sanitizedName, true))
 	}
 
 	// Handle text input
 	var cmd tea.Cmd
-	if m.modalFocused == 0 {
+	switch m.modalFocused {
+	case 0:
 		m.sessionNameInput, cmd = m.sessionNameInput.Update(msg)
+	case 1:
+		m.createTaskInput, cmd = m.createTaskInput.Update(msg)
 	}
 
 	return m, cmd
 }
 
+// createTask starts creating a worktree for a task on a branch derived from name
+func (m Model) createTask(name, task string) (tea.Model, tea.Cmd) {
+	branch := m.sessionManager.SanitizeBranchName(name)
+	if branch == "" {
+		randomName, err := m.gitManager.GenerateRandomName()
+		if err != nil {
+			return m, m.showWarningNotification("Failed to generate random name")
+		}
+		branch = randomName
+	}
+	path, err := m.gitManager.GetDefaultPath(branch)
+	if err != nil {
+		return m, m.showWarningNotification("Failed to generate workspace path")
+	}
+
+	m.modal = noModal
+	cmd := m.showInfoNotification(fmt.Sprintf("Starting task in %s...", branch))
+	return m, tea.Batch(cmd, m.startTask(path, branch, task))
+}
+
 func (m Model) handleDeleteModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "esc", "n":
@@ -2358,7 +3049,27 @@ func (m Model) handleSessionListModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 		onConfirm: func(m Model) (tea.Model, tea.Cmd) {
 			if m.sessionIndex >= 0 && m.sessionIndex < len(m.sessions) {
 				sess := m.sessions[m.sessionIndex]
+				if m.backend.Name() != session.BackendTmux {
+					// Switch through the shell wrapper like Enter on the worktree does
+					for _, wt := range m.worktrees {
+						if wt.ClaudeSessionName == sess.Name {
+							m.modal = noModal
+							m.pendingSwitchInfo = &SwitchInfo{
+								Path:                wt.Path,
+								Branch:              wt.Branch,
+								SessionName:         wt.ClaudeSessionName,
+								AutoClaude:          m.autoClaude,
+								TargetWindow:        "claude",
+								IsClaudeInitialized: m.configManager != nil && m.configManager.IsClaudeInitialized(m.repoPath, wt.Branch),
+							}
+							m.ensuringWorktree = true
+							return m, m.ensureWorktreeExists(wt.Path, wt.Branch)
+						}
+					}
+					return m, m.showWarningNotification("No worktree found for session " + sess.Name)
+				}
 				// Attach via tmux
+				m.closeControlClient()
 				if err := m.sessionManager.Attach(sess.Name); err != nil {
 					m.showErrorNotification("Failed to attach to session", 3*time.Second)
 					return m, nil
@@ -2373,10 +3084,108 @@ func (m Model) handleSessionListModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 			return m, nil
 		},
 		onCustomKey: func(m Model, key string) (tea.Model, tea.Cmd) {
+			if key == "l" && m.sessionIndex >= 0 && m.sessionIndex < len(m.sessions) {
+				// Re-apply the jean.json layout (adds missing windows and panes)
+				if !m.backend.Capabilities().Layouts {
+					return m, m.showWarningNotification(fmt.Sprintf("Applying layouts to running sessions isn't supported with %s", m.backend.Name()))
+				}
+				sess := m.sessions[m.sessionIndex]
+				layout := m.sessionManager.LoadLayout(sess.Path)
+				if layout == nil {
+					return m, m.showWarningNotification("No layout configured in jean.json")
+				}
+				if err := m.sessionManager.ApplyLayout(sess.Name, sess.Path, layout); err != nil {
+					return m, m.showErrorNotification("Failed to apply layout: "+err.Error(), 4*time.Second)
+				}
+				return m, m.showSuccessNotification("Layout applied to "+sess.Branch, 3*time.Second)
+			}
+			if key == "r" {
+				// Restore all sessions recorded before a tmux restart or reboot
+				if len(m.restorableSessions) == 0 {
+					return m, m.showInfoNotification("No sessions to restore")
+				}
+				return m, tea.Batch(m.showInfoNotification("Restoring sessions..."), m.restoreSessions())
+			}
+			if key == "f" && len(m.restorableSessions) > 0 {
+				// Stop offering the recorded sessions for restoring
+				if err := m.sessionManager.ForgetRestorableSessions(); err != nil {
+					return m, m.showErrorNotification("Failed to forget sessions: "+err.Error(), 3*time.Second)
+				}
+				count := len(m.restorableSessions)
+				m.restorableSessions = nil
+				return m, m.showSuccessNotification(fmt.Sprintf("Forgot %d restorable session(s)", count), 3*time.Second)
+			}
+			if key == "p" && m.sessionIndex >= 0 && m.sessionIndex < len(m.sessions) {
+				// Show the processes running in the selected session
+				if _, ok := m.sessionUsages[m.sessions[m.sessionIndex].Name]; !ok {
+					return m, m.showInfoNotification("Measuring resource usage...")
+				}
+				m.processIndex = 0
+				m.modal = processModal
+				return m, nil
+			}
+			if key == "K" {
+				// Kill the session using the most CPU (press twice to confirm)
+				heaviest, ok := session.Heaviest(m.sessionUsages)
+				if !ok {
+					return m, m.showInfoNotification("No resource usage measured yet")
+				}
+				if m.confirmKillSession != heaviest.Session {
+					m.confirmKillSession = heaviest.Session
+					return m, m.showWarningNotification(fmt.Sprintf("%s is the heaviest (CPU %.0f%%, %s). Press K again to kill it.", heaviest.Session, heaviest.CPU, session.FormatBytes(heaviest.RSS)))
+				}
+				m.confirmKillSession = ""
+				if err := m.backend.Kill(heaviest.Session); err != nil {
+					return m, m.showErrorNotification("Failed to kill session", 3*time.Second)
+				}
+				return m, tea.Batch(m.showSuccessNotification("Killed "+heaviest.Session, 3*time.Second), m.loadSessions())
+			}
+			if key == "x" {
+				// Kill all sessions whose worktree is gone
+				for _, orphan := range m.sessionOrphans {
+					if orphan.Prunable() {
+						return m, m.killOrphans()
+					}
+				}
+				return m, m.showInfoNotification("No orphaned sessions to kill")
+			}
+			if (key == "n" || key == "a") && m.sessionIndex >= 0 && m.sessionIndex < len(m.sessions) {
+				sess := m.sessions[m.sessionIndex]
+				orphan, ok := m.sessionOrphans[sess.Name]
+				if !ok {
+					return m, m.showInfoNotification("This session belongs to a worktree")
+				}
+				if m.backend.Name() != session.BackendTmux {
+					return m, m.showWarningNotification(fmt.Sprintf("Renaming sessions isn't supported with %s", m.backend.Name()))
+				}
+				if key == "n" {
+					// Rename the session of a renamed branch to match its worktree
+					if orphan.Kind != session.OrphanRenamed {
+						return m, m.showWarningNotification("Only sessions of renamed branches can be renamed (use a to reattach)")
+					}
+					if err := m.sessionManager.Reattach(sess.Name, orphan.ExpectedName, sess.Path); err != nil {
+						return m, m.showErrorNotification("Failed to rename session: "+err.Error(), 4*time.Second)
+					}
+					return m, tea.Batch(m.showSuccessNotification("Session renamed to "+orphan.ExpectedName, 3*time.Second), m.loadSessions())
+				}
+				// Hand the session over to a worktree that doesn't have one
+				m.reattachChoices = nil
+				for _, wt := range m.worktrees {
+					if !m.sessionManager.SessionExists(wt.ClaudeSessionName) {
+						m.reattachChoices = append(m.reattachChoices, wt)
+					}
+				}
+				if len(m.reattachChoices) == 0 {
+					return m, m.showWarningNotification("Every worktree already has a session")
+				}
+				m.reattachIndex = 0
+				m.modal = reattachModal
+				return m, nil
+			}
 			if key == "d" && m.sessionIndex >= 0 && m.sessionIndex < len(m.sessions) {
 				// Kill selected session
 				sess := m.sessions[m.sessionIndex]
-				if err := m.sessionManager.Kill(sess.Name); err != nil {
+				if err := m.backend.Kill(sess.Name); err != nil {
 					return m, m.showErrorNotification("Failed to kill session", 3*time.Second)
 				} else {
 					// Batch notification with session reload
@@ -3017,111 +3826,465 @@ func (m Model) handlePostMergeCleanupModalInput(msg tea.KeyMsg) (tea.Model, tea.
 	return m, nil
 }
 
-func (m Model) handlePRTypeModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+func (m Model) handleMoveChangesModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	return m.handleListSelectionModalInput(msg, listSelectionConfig{
+		getCurrentIndex: func() int { return m.moveChangesIndex },
+		getItemCount: func(m Model) int {
+			// First entry is "new worktree"
+			return len(m.moveChangesTargets()) + 1
+		},
+		incrementIndex: func(m *Model) { m.moveChangesIndex++ },
+		decrementIndex: func(m *Model) { m.moveChangesIndex-- },
+		onConfirm: func(m Model) (tea.Model, tea.Cmd) {
+			opts := git.TransplantOptions{
+				IncludeStaged:    m.moveChangesIncludeStaged,
+				IncludeUntracked: m.moveChangesIncludeUntracked,
+			}
+			targetPath, targetBranch := "", ""
+			if m.moveChangesIndex > 0 {
+				targets := m.moveChangesTargets()
+				if m.moveChangesIndex-1 >= len(targets) {
+					return m, nil
+				}
+				target := targets[m.moveChangesIndex-1]
+				targetPath, targetBranch = target.Path, target.Branch
+			}
+
+			m.modal = noModal
+			m.debugLog(fmt.Sprintf("Moving changes from %s to %q (staged=%v, untracked=%v)", m.moveChangesSource, targetPath, opts.IncludeStaged, opts.IncludeUntracked))
+			notifyCmd := m.showInfoNotification("Moving changes...")
+			return m, tea.Batch(
+				notifyCmd,
+				m.moveChanges(m.moveChangesSource, m.moveChangesSourceBranch, targetPath, targetBranch, opts),
+			)
+		},
+		onCustomKey: func(m Model, key string) (tea.Model, tea.Cmd) {
+			switch key {
+			case "s":
+				m.moveChangesIncludeStaged = !m.moveChangesIncludeStaged
+			case "u":
+				m.moveChangesIncludeUntracked = !m.moveChangesIncludeUntracked
+			}
+			return m, nil
+		},
+	})
+}
+
+func (m Model) handleForkModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Focus order: 0=name, 1=prompt, 2=carry changes, 3=start agent, 4=fork button, 5=cancel button
+	const focusCount = 6
+
+	setFocus := func(m *Model, focus int) {
+		m.modalFocused = focus
+		m.sessionNameInput.Blur()
+		m.forkPromptInput.Blur()
+		switch focus {
+		case 0:
+			m.sessionNameInput.Focus()
+		case 1:
+			m.forkPromptInput.Focus()
+		}
+	}
+
 	switch msg.String() {
 	case "esc":
 		m.modal = noModal
+		m.sessionNameInput.Blur()
+		m.forkPromptInput.Blur()
 		return m, nil
 
-	case "up":
-		if m.prTypeCursor > 0 {
-			m.prTypeCursor--
-		}
+	case "tab", "down":
+		setFocus(&m, (m.modalFocused+1)%focusCount)
 		return m, nil
 
-	case "down":
-		if m.prTypeCursor < 1 {
-			m.prTypeCursor++
-		}
+	case "shift+tab", "up":
+		setFocus(&m, (m.modalFocused+focusCount-1)%focusCount)
 		return m, nil
 
-	case "enter":
-		// User confirmed PR type selection
-		// Set isDraft based on selection (0=draft, 1=ready for review)
-		m.prIsDraft = (m.prTypeCursor == 0)
+	case " ":
+		// Space toggles checkboxes; otherwise fall through to text input
+		if m.modalFocused == 2 {
+			m.forkCarryChanges = !m.forkCarryChanges
+			return m, nil
+		} else if m.modalFocused == 3 {
+			m.forkStartAgent = !m.forkStartAgent
+			return m, nil
+		}
 
-		// Close modal and create PR with commit message as title
-		m.modal = noModal
+	case "enter":
+		switch m.modalFocused {
+		case 0, 1:
+			setFocus(&m, m.modalFocused+1)
+			return m, nil
+		case 2:
+			m.forkCarryChanges = !m.forkCarryChanges
+			return m, nil
+		case 3:
+			m.forkStartAgent = !m.forkStartAgent
+			return m, nil
+		case 5:
+			m.modal = noModal
+			m.sessionNameInput.Blur()
+			m.forkPromptInput.Blur()
+			return m, nil
+		}
 
-		// Use the last commit message as PR title
-		title := m.lastCommitMessage
-		if title == "" {
-			// Fallback if no commit message available (shouldn't happen in normal flow)
-			title = strings.ReplaceAll(m.prModalBranch, "-", " ")
-			title = strings.ReplaceAll(title, "_", " ")
-			title = strings.TrimSpace(title)
-			if len(title) > 0 {
-				title = strings.ToUpper(title[:1]) + title[1:]
+		// Fork button
+		name := m.sessionNameInput.Value()
+		if name == "" {
+			randomName, err := m.gitManager.GenerateRandomName()
+			if err != nil {
+				return m, m.showWarningNotification("Failed to generate random name")
 			}
+			name = randomName
+		}
+		branch := m.sessionManager.SanitizeBranchName(name)
+		if branch == "" {
+			return m, m.showWarningNotification("Branch name contains no valid characters")
 		}
 
-		// Create PR with commit message as title and empty description
-		cmd := m.showInfoNotification("Creating draft PR...")
-		return m, tea.Batch(cmd, m.createOrUpdatePR(m.prModalWorktreePath, m.prModalBranch, title, ""))
+		m.modal = noModal
+		m.sessionNameInput.Blur()
+		m.forkPromptInput.Blur()
+		prompt := strings.TrimSpace(m.forkPromptInput.Value())
+		m.debugLog(fmt.Sprintf("Forking %s into %s (carry=%v, agent=%v)", m.forkSourceBranch, branch, m.forkCarryChanges, m.forkStartAgent))
+		notifyCmd := m.showInfoNotification(fmt.Sprintf("Forking %s → %s...", m.forkSourceBranch, branch))
+		return m, tea.Batch(
+			notifyCmd,
+			m.forkWorktree(m.forkSourcePath, m.forkSourceBranch, branch, prompt, m.forkCarryChanges, m.forkStartAgent),
+		)
 	}
 
-	return m, nil
+	// Handle text input
+	var cmd tea.Cmd
+	switch m.modalFocused {
+	case 0:
+		m.sessionNameInput, cmd = m.sessionNameInput.Update(msg)
+	case 1:
+		m.forkPromptInput, cmd = m.forkPromptInput.Update(msg)
+	}
+	return m, cmd
 }
 
-func (m Model) handleEditorSelectModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	config := listSelectionConfig{
-		getCurrentIndex: func() int { return m.editorIndex },
-		getItemCount:    func(m Model) int { return len(m.editors) },
-		incrementIndex:  func(m *Model) { m.editorIndex++ },
-		decrementIndex:  func(m *Model) { m.editorIndex-- },
+func (m Model) handleResetModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Confirmation step: dry-run list is shown
+	if m.resetConfirming {
+		switch msg.String() {
+		case "esc", "n":
+			// Back to option list
+			m.resetConfirming = false
+			m.resetPreview = nil
+			return m, nil
+
+		case "enter", "y":
+			m.modal = noModal
+			m.resetConfirming = false
+			if m.resetIndex == 3 {
+				if len(m.resetSafetyRefs) == 0 {
+					return m, nil
+				}
+				notifyCmd := m.showInfoNotification("Restoring from safety snapshot...")
+				return m, tea.Batch(notifyCmd, m.undoReset(m.resetWorktreePath, m.resetBranch, m.resetSafetyRefs[0]))
+			}
+			m.debugLog(fmt.Sprintf("Resetting %s (mode=%d, ignored=%v)", m.resetWorktreePath, m.resetIndex, m.resetIncludeIgnored))
+			notifyCmd := m.showInfoNotification("Resetting worktree...")
+			return m, tea.Batch(notifyCmd, m.resetWorktree(m.resetWorktreePath, m.resetBranch, git.ResetMode(m.resetIndex), m.resetIncludeIgnored))
+		}
+		return m, nil
+	}
+
+	return m.handleListSelectionModalInput(msg, listSelectionConfig{
+		getCurrentIndex: func() int { return m.resetIndex },
+		getItemCount:    func(m Model) int { return 4 },
+		incrementIndex:  func(m *Model) { m.resetIndex++ },
+		decrementIndex:  func(m *Model) { m.resetIndex-- },
 		onConfirm: func(m Model) (tea.Model, tea.Cmd) {
-			if m.editorIndex >= 0 && m.editorIndex < len(m.editors) {
-				selectedEditor := m.editors[m.editorIndex]
-				if m.configManager != nil {
-					if err := m.configManager.SetEditor(m.repoPath, selectedEditor); err != nil {
-						m.showErrorNotification("Failed to save editor preference", 3*time.Second)
-					} else {
-						m.showSuccessNotification("Editor set to: " + selectedEditor, 3*time.Second)
-					}
+			if m.resetIndex == 3 {
+				if len(m.resetSafetyRefs) == 0 {
+					return m, m.showInfoNotification("No reset to undo for " + m.resetBranch)
 				}
+				m.resetConfirming = true
+				return m, nil
 			}
-			m.modal = settingsModal
-			m.settingsIndex = 0
-			return m, nil
+			if git.ResetMode(m.resetIndex) == git.ResetHardToBase && m.baseBranch == "" {
+				return m, m.showWarningNotification("Base branch not set. Press 'b' to set base branch")
+			}
+			return m, m.previewReset(m.resetWorktreePath, git.ResetMode(m.resetIndex), m.resetIncludeIgnored)
 		},
-		onCancel: func(m Model) (tea.Model, tea.Cmd) {
-			// Return to settings modal
-			m.modal = settingsModal
-			m.settingsIndex = 0
+		onCustomKey: func(m Model, key string) (tea.Model, tea.Cmd) {
+			if key == "i" {
+				m.resetIncludeIgnored = !m.resetIncludeIgnored
+			}
 			return m, nil
 		},
-	}
-	return m.handleListSelectionModalInput(msg, config)
+	})
 }
 
-func (m Model) handleThemeSelectModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+func (m Model) handleExportPatchesModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Focus: 0=format list, 1=destination path input
 	switch msg.String() {
-	case "esc", "q":
-		// Revert to original theme when cancelling
-		if m.originalTheme != "" {
-			ApplyTheme(m.originalTheme)
-		}
-		m.modal = settingsModal
-		m.settingsIndex = 1
+	case "esc":
+		m.modal = noModal
+		m.patchPathInput.Blur()
 		return m, nil
 
-	case "up":
-		if m.themeIndex > 0 {
-			m.themeIndex--
-			// Apply theme preview immediately
-			selectedTheme := m.availableThemes[m.themeIndex]
-			themeName := strings.ToLower(selectedTheme.Name)
-			if err := ApplyTheme(themeName); err == nil {
-				// Theme applied successfully, UI will refresh with new colors
-			}
+	case "tab", "shift+tab":
+		if m.modalFocused == 0 {
+			m.modalFocused = 1
+			m.patchPathInput.Focus()
+		} else {
+			m.modalFocused = 0
+			m.patchPathInput.Blur()
 		}
 		return m, nil
 
-	case "down":
-		if m.themeIndex < len(m.availableThemes)-1 {
-			m.themeIndex++
-			// Apply theme preview immediately
-			selectedTheme := m.availableThemes[m.themeIndex]
+	case "up", "down":
+		if m.modalFocused == 0 {
+			m.exportFormatIndex = 1 - m.exportFormatIndex
+			m.patchPathInput.SetValue(m.defaultExportPath(m.patchBranch, m.exportFormatIndex == 1))
+			return m, nil
+		}
+
+	case "enter":
+		dest := strings.TrimSpace(m.patchPathInput.Value())
+		if dest == "" {
+			return m, m.showWarningNotification("Destination path is required")
+		}
+		m.modal = noModal
+		m.patchPathInput.Blur()
+		notifyCmd := m.showInfoNotification("Exporting " + m.patchBranch + "...")
+		return m, tea.Batch(notifyCmd, m.exportPatches(m.patchWorktreePath, m.patchBranch, dest, m.exportFormatIndex == 1))
+	}
+
+	var cmd tea.Cmd
+	if m.modalFocused == 1 {
+		m.patchPathInput, cmd = m.patchPathInput.Update(msg)
+	}
+	return m, cmd
+}
+
+func (m Model) handleImportPatchesModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Focus: 0=source path input, 1=target list (0=new worktree, 1..n=existing worktrees)
+	switch msg.String() {
+	case "esc":
+		m.modal = noModal
+		m.patchPathInput.Blur()
+		return m, nil
+
+	case "tab", "shift+tab":
+		if m.modalFocused == 0 {
+			m.modalFocused = 1
+			m.patchPathInput.Blur()
+		} else {
+			m.modalFocused = 0
+			m.patchPathInput.Focus()
+		}
+		return m, nil
+
+	case "up":
+		if m.modalFocused == 1 && m.importTargetIndex > 0 {
+			m.importTargetIndex--
+		}
+		return m, nil
+
+	case "down":
+		if m.modalFocused == 0 {
+			m.modalFocused = 1
+			m.patchPathInput.Blur()
+		} else if m.importTargetIndex < len(m.worktrees) {
+			m.importTargetIndex++
+		}
+		return m, nil
+
+	case "enter":
+		if m.modalFocused == 0 {
+			m.modalFocused = 1
+			m.patchPathInput.Blur()
+			return m, nil
+		}
+
+		source := strings.TrimSpace(m.patchPathInput.Value())
+		if strings.HasPrefix(source, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				source = filepath.Join(home, source[2:])
+			}
+		}
+		if source == "" {
+			return m, m.showWarningNotification("Patch or bundle path is required")
+		}
+
+		targetPath, targetBranch := "", ""
+		if m.importTargetIndex > 0 && m.importTargetIndex-1 < len(m.worktrees) {
+			target := m.worktrees[m.importTargetIndex-1]
+			targetPath, targetBranch = target.Path, target.Branch
+		}
+
+		m.modal = noModal
+		notifyCmd := m.showInfoNotification("Importing patches...")
+		return m, tea.Batch(notifyCmd, m.importPatches(source, targetPath, targetBranch))
+	}
+
+	var cmd tea.Cmd
+	if m.modalFocused == 0 {
+		m.patchPathInput, cmd = m.patchPathInput.Update(msg)
+	}
+	return m, cmd
+}
+
+func (m Model) handleAmConflictModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	actions := []string{"continue", "skip", "abort"}
+	return m.handleListSelectionModalInput(msg, listSelectionConfig{
+		getCurrentIndex: func() int { return m.amOptionIndex },
+		getItemCount:    func(m Model) int { return len(actions) },
+		incrementIndex:  func(m *Model) { m.amOptionIndex++ },
+		decrementIndex:  func(m *Model) { m.amOptionIndex-- },
+		onConfirm: func(m Model) (tea.Model, tea.Cmd) {
+			action := actions[m.amOptionIndex]
+			m.modal = noModal
+			return m, m.resolveAm(m.amWorktreePath, m.amBranch, action)
+		},
+		onCustomKey: func(m Model, key string) (tea.Model, tea.Cmd) {
+			if key == "o" {
+				// Open the worktree in the editor to resolve conflicts
+				return m, m.openInEditor(m.amWorktreePath)
+			}
+			return m, nil
+		},
+	})
+}
+
+func (m Model) handleConflictMatrixModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "C":
+		m.modal = noModal
+		return m, nil
+
+	case "up":
+		if m.conflictMatrixScroll > 0 {
+			m.conflictMatrixScroll--
+		}
+		return m, nil
+
+	case "down":
+		m.conflictMatrixScroll++
+		return m, nil
+
+	case "r":
+		// Recompute predictions
+		if !m.computingConflicts {
+			m.computingConflicts = true
+			return m, m.computeConflicts()
+		}
+	}
+
+	return m, nil
+}
+
+func (m Model) handlePRTypeModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.modal = noModal
+		return m, nil
+
+	case "up":
+		if m.prTypeCursor > 0 {
+			m.prTypeCursor--
+		}
+		return m, nil
+
+	case "down":
+		if m.prTypeCursor < 1 {
+			m.prTypeCursor++
+		}
+		return m, nil
+
+	case "enter":
+		// User confirmed PR type selection
+		// Set isDraft based on selection (0=draft, 1=ready for review)
+		m.prIsDraft = (m.prTypeCursor == 0)
+
+		// Close modal and create PR with commit message as title
+		m.modal = noModal
+
+		// Use the last commit message as PR title
+		title := m.lastCommitMessage
+		if title == "" {
+			// Fallback if no commit message available (shouldn't happen in normal flow)
+			title = strings.ReplaceAll(m.prModalBranch, "-", " ")
+			title = strings.ReplaceAll(title, "_", " ")
+			title = strings.TrimSpace(title)
+			if len(title) > 0 {
+				title = strings.ToUpper(title[:1]) + title[1:]
+			}
+		}
+
+		// Create PR with commit message as title and empty description
+		cmd := m.showInfoNotification("Creating draft PR...")
+		return m, tea.Batch(cmd, m.createOrUpdatePR(m.prModalWorktreePath, m.prModalBranch, title, ""))
+	}
+
+	return m, nil
+}
+
+func (m Model) handleEditorSelectModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	config := listSelectionConfig{
+		getCurrentIndex: func() int { return m.editorIndex },
+		getItemCount:    func(m Model) int { return len(m.editors) },
+		incrementIndex:  func(m *Model) { m.editorIndex++ },
+		decrementIndex:  func(m *Model) { m.editorIndex-- },
+		onConfirm: func(m Model) (tea.Model, tea.Cmd) {
+			if m.editorIndex >= 0 && m.editorIndex < len(m.editors) {
+				selectedEditor := m.editors[m.editorIndex]
+				if m.configManager != nil {
+					if err := m.configManager.SetEditor(m.repoPath, selectedEditor); err != nil {
+						m.showErrorNotification("Failed to save editor preference", 3*time.Second)
+					} else {
+						m.showSuccessNotification("Editor set to: " + selectedEditor, 3*time.Second)
+					}
+				}
+			}
+			m.modal = settingsModal
+			m.settingsIndex = 0
+			return m, nil
+		},
+		onCancel: func(m Model) (tea.Model, tea.Cmd) {
+			// Return to settings modal
+			m.modal = settingsModal
+			m.settingsIndex = 0
+			return m, nil
+		},
+	}
+	return m.handleListSelectionModalInput(msg, config)
+}
+
+func (m Model) handleThemeSelectModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		// Revert to original theme when cancelling
+		if m.originalTheme != "" {
+			ApplyTheme(m.originalTheme)
+		}
+		m.modal = settingsModal
+		m.settingsIndex = 1
+		return m, nil
+
+	case "up":
+		if m.themeIndex > 0 {
+			m.themeIndex--
+			// Apply theme preview immediately
+			selectedTheme := m.availableThemes[m.themeIndex]
+			themeName := strings.ToLower(selectedTheme.Name)
+			if err := ApplyTheme(themeName); err == nil {
+				// Theme applied successfully, UI will refresh with new colors
+			}
+		}
+		return m, nil
+
+	case "down":
+		if m.themeIndex < len(m.availableThemes)-1 {
+			m.themeIndex++
+			// Apply theme preview immediately
+			selectedTheme := m.availableThemes[m.themeIndex]
 			themeName := strings.ToLower(selectedTheme.Name)
 			if err := ApplyTheme(themeName); err == nil {
 				// Theme applied successfully, UI will refresh with new colors
@@ -3147,6 +4310,39 @@ func (m Model) handleThemeSelectModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 	return m, nil
 }
 
+// switchToAgent quits to the agent window of a worktree's session, starting the session if needed.
+// agentArgs replaces the main agent's configured arguments for this launch (nil = configured ones).
+func (m Model) switchToAgent(wt git.Worktree, agentArgs *config.AgentArgs) (tea.Model, tea.Cmd) {
+	// Save the last selected branch before switching
+	if m.configManager != nil {
+		_ = m.configManager.SetLastSelectedBranch(m.repoPath, wt.Branch)
+	}
+	// Check if this Claude session has been initialized before
+	isInitialized := false
+	if m.configManager != nil {
+		isInitialized = m.configManager.IsClaudeInitialized(m.repoPath, wt.Branch)
+		// Mark this branch as initialized for next time
+		// (so next run will use --continue instead of plain claude)
+		if m.autoClaude && !isInitialized {
+			_ = m.configManager.SetClaudeInitialized(m.repoPath, wt.Branch)
+		}
+	}
+	// Store pending switch info and ensure worktree exists
+	// SessionName includes repo basename for uniqueness across repositories (e.g., jean-reponame-branch)
+	m.pendingSwitchInfo = &SwitchInfo{
+		Path:                wt.Path,
+		Branch:              wt.Branch,
+		SessionName:         wt.ClaudeSessionName, // Pre-sanitized session name with repo basename
+		AutoClaude:          m.autoClaude,
+		TargetWindow:        "claude", // Attach to Claude window
+		IsClaudeInitialized: isInitialized,
+		AgentArgs:           agentArgs,
+	}
+	m.ensuringWorktree = true
+	cmd := m.showInfoNotification("Preparing workspace...")
+	return m, tea.Batch(cmd, m.ensureWorktreeExists(wt.Path, wt.Branch))
+}
+
 func (m Model) handleSettingsModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "esc", "q":
@@ -3159,7 +4355,7 @@ func (m Model) handleSettingsModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 
 	case "down":
-		if m.settingsIndex < 6 { // Now 7 settings (editor, theme, base branch, tmux config, AI integration, debug logs, PR default state)
+		if m.settingsIndex < 10 { // Now 11 settings (editor, theme, base branch, tmux config, AI integration, debug logs, PR default state, agent, notifications, multiplexer, agent arguments)
 			m.settingsIndex++
 		}
 
@@ -3205,6 +4401,30 @@ func (m Model) handleSettingsModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		msg = tea.KeyMsg{Type: tea.KeyEnter}
 		return m.handleSettingsModalInput(msg)
 
+	case "g":
+		// Quick key for Agent
+		m.settingsIndex = 7
+		msg = tea.KeyMsg{Type: tea.KeyEnter}
+		return m.handleSettingsModalInput(msg)
+
+	case "n":
+		// Quick key for Notifications
+		m.settingsIndex = 8
+		msg = tea.KeyMsg{Type: tea.KeyEnter}
+		return m.handleSettingsModalInput(msg)
+
+	case "m":
+		// Quick key for Multiplexer
+		m.settingsIndex = 9
+		msg = tea.KeyMsg{Type: tea.KeyEnter}
+		return m.handleSettingsModalInput(msg)
+
+	case "x":
+		// Quick key for Agent Arguments
+		m.settingsIndex = 10
+		msg = tea.KeyMsg{Type: tea.KeyEnter}
+		return m.handleSettingsModalInput(msg)
+
 	case "enter":
 		// Open the selected setting's modal
 		switch m.settingsIndex {
@@ -3307,6 +4527,63 @@ func (m Model) handleSettingsModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				}
 			}
 			return m, nil
+
+		case 7:
+			// Agent setting - cycle through available agents for new sessions
+			if m.configManager != nil {
+				names := m.agentNames()
+				current := m.configManager.GetRepoAgent(m.repoPath)
+				next := names[0]
+				for i, name := range names {
+					if name == current {
+						next = names[(i+1)%len(names)]
+						break
+					}
+				}
+				if err := m.configManager.SetRepoAgent(m.repoPath, next); err != nil {
+					return m, m.showErrorNotification("Failed to save agent setting: "+err.Error(), 3*time.Second)
+				}
+				return m, m.showSuccessNotification("Default agent: "+next, 2*time.Second)
+			}
+			return m, nil
+
+		case 8:
+			// Notifications setting - open notifications modal
+			m.modal = notificationsModal
+			m.notificationsIndex = 0
+			return m, nil
+
+		case 9:
+			// Multiplexer setting - cycle through the session backends
+			if m.configManager != nil {
+				next := session.BackendNames[0]
+				for i, name := range session.BackendNames {
+					if name == m.backend.Name() {
+						next = session.BackendNames[(i+1)%len(session.BackendNames)]
+						break
+					}
+				}
+				if err := m.configManager.SetMultiplexer(next); err != nil {
+					return m, m.showErrorNotification("Failed to save multiplexer setting: "+err.Error(), 3*time.Second)
+				}
+				m.backend = session.NewBackend(next)
+				m.sessions = nil
+				m.agentStates = nil
+				if !m.backend.IsAvailable() {
+					return m, m.showWarningNotification(fmt.Sprintf("Multiplexer: %s (not installed)", next))
+				}
+				return m, m.showSuccessNotification("Multiplexer: "+next, 2*time.Second)
+			}
+			return m, nil
+
+		case 10:
+			// Agent arguments setting - edit the repository's (or selected worktree's) agent arguments
+			branch := ""
+			if wt := m.selectedWorktree(); wt != nil {
+				branch = wt.Branch
+			}
+			m.openAgentArgsModal(branch, false)
+			return m, nil
 		}
 	}
 
@@ -3748,6 +5025,7 @@ func (m Model) handleGitInitModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case "n", "q", "esc":
 		// Quit application
+		m.closeControlClient()
 		return m, tea.Quit
 
 	default:
@@ -3765,3 +5043,776 @@ func (m Model) handleHelperModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	return m, nil
 }
+
+func (m Model) handleWindowSelectModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	config := listSelectionConfig{
+		getCurrentIndex: func() int { return m.layoutWindowIndex },
+		getItemCount:    func(m Model) int { return len(m.layoutWindows) },
+		incrementIndex:  func(m *Model) { m.layoutWindowIndex++ },
+		decrementIndex:  func(m *Model) { m.layoutWindowIndex-- },
+		onConfirm: func(m Model) (tea.Model, tea.Cmd) {
+			wt := m.selectedWorktree()
+			if wt == nil || m.layoutWindowIndex < 0 || m.layoutWindowIndex >= len(m.layoutWindows) {
+				m.modal = noModal
+				return m, nil
+			}
+			target := m.layoutWindows[m.layoutWindowIndex]
+			m.modal = noModal
+
+			if m.configManager != nil {
+				_ = m.configManager.SetLastSelectedBranch(m.repoPath, wt.Branch)
+			}
+			isInitialized := false
+			if m.configManager != nil && target == "claude" {
+				isInitialized = m.configManager.IsClaudeInitialized(m.repoPath, wt.Branch)
+				if m.autoClaude && !isInitialized {
+					_ = m.configManager.SetClaudeInitialized(m.repoPath, wt.Branch)
+				}
+			}
+			m.pendingSwitchInfo = &SwitchInfo{
+				Path:                wt.Path,
+				Branch:              wt.Branch,
+				SessionName:         wt.ClaudeSessionName,
+				AutoClaude:          m.autoClaude && target == "claude",
+				TargetWindow:        target,
+				IsClaudeInitialized: isInitialized,
+			}
+			m.ensuringWorktree = true
+			cmd := m.showInfoNotification("Preparing workspace...")
+			return m, tea.Batch(cmd, m.ensureWorktreeExists(wt.Path, wt.Branch))
+		},
+		onCancel: func(m Model) (tea.Model, tea.Cmd) {
+			m.modal = noModal
+			return m, nil
+		},
+	}
+	return m.handleListSelectionModalInput(msg, config)
+}
+
+func (m Model) handleAgentsModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	names := m.agentNames()
+
+	switch msg.String() {
+	case "esc", "q":
+		m.modal = noModal
+		return m, nil
+
+	case "up", "k":
+		if m.agentsModalIndex > 0 {
+			m.agentsModalIndex--
+		}
+		return m, nil
+
+	case "down", "j":
+		if m.agentsModalIndex < len(names)-1 {
+			m.agentsModalIndex++
+		}
+		return m, nil
+
+	case " ":
+		// Toggle the agent; the first selected agent becomes the main agent
+		if m.agentsModalIndex >= len(names) {
+			return m, nil
+		}
+		name := names[m.agentsModalIndex]
+		var choices []string
+		found := false
+		for _, choice := range m.agentsModalChoices {
+			if choice == name {
+				found = true
+				continue
+			}
+			choices = append(choices, choice)
+		}
+		if !found {
+			choices = append(choices, name)
+		}
+		m.agentsModalChoices = choices
+		return m, nil
+
+	case "enter":
+		wt := m.selectedWorktree()
+		m.modal = noModal
+		if wt == nil || m.configManager == nil {
+			return m, nil
+		}
+		if err := m.configManager.SetWorktreeAgents(m.repoPath, wt.Branch, m.agentsModalChoices); err != nil {
+			return m, m.showErrorNotification("Failed to save agents: "+err.Error(), 3*time.Second)
+		}
+
+		// Start newly added agents right away if the session is running
+		if m.backend.Capabilities().Layouts && m.sessionManager.SessionExists(wt.ClaudeSessionName) {
+			resume := m.configManager.IsClaudeInitialized(m.repoPath, wt.Branch)
+			if err := m.sessionManager.StartAgents(wt.ClaudeSessionName, wt.Path, m.worktreeAgents(wt.Branch), resume, m.resumeID(wt.Branch), ""); err != nil {
+				return m, m.showErrorNotification("Failed to start agents: "+err.Error(), 4*time.Second)
+			}
+		}
+		return m, m.showSuccessNotification(fmt.Sprintf("Agents for %s: %s", wt.Branch, strings.Join(m.configManager.GetWorktreeAgents(m.repoPath, wt.Branch), ", ")), 3*time.Second)
+	}
+
+	return m, nil
+}
+
+func (m Model) handleNotificationsModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.modal = settingsModal
+		m.settingsIndex = 8 // Go back to Notifications option in settings
+		return m, nil
+
+	case "up", "k":
+		if m.notificationsIndex > 0 {
+			m.notificationsIndex--
+		}
+		return m, nil
+
+	case "down", "j":
+		if m.notificationsIndex < 2 {
+			m.notificationsIndex++
+		}
+		return m, nil
+
+	case " ", "enter":
+		if m.configManager == nil {
+			return m, nil
+		}
+		cfg := m.configManager.GetNotificationConfig()
+		switch m.notificationsIndex {
+		case 0:
+			cfg.Bell = !cfg.Bell
+		case 1:
+			cfg.TmuxMessage = !cfg.TmuxMessage
+		case 2:
+			cfg.Desktop = !cfg.Desktop
+		}
+		if err := m.configManager.SetNotificationConfig(cfg); err != nil {
+			return m, m.showErrorNotification("Failed to save notification settings: "+err.Error(), 3*time.Second)
+		}
+		// The watcher re-reads the settings; make sure it runs if alerts were just enabled
+		return m, m.startWatcher()
+	}
+
+	return m, nil
+}
+
+func (m Model) handleArchiveListModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Focus: 0=search input, 1=archive/match list
+	if m.modalFocused == 0 {
+		switch msg.String() {
+		case "esc":
+			m.modalFocused = 1
+			m.archiveSearchInput.Blur()
+			return m, nil
+
+		case "enter":
+			m.modalFocused = 1
+			m.archiveSearchInput.Blur()
+			return m, m.loadArchives(strings.TrimSpace(m.archiveSearchInput.Value()))
+		}
+
+		var cmd tea.Cmd
+		m.archiveSearchInput, cmd = m.archiveSearchInput.Update(msg)
+		return m, cmd
+	}
+
+	count := len(m.archives)
+	if m.archiveSearchInput.Value() != "" {
+		count = len(m.archiveMatches)
+	}
+
+	switch msg.String() {
+	case "esc", "q":
+		if m.archiveSearchInput.Value() != "" {
+			// Clear the search first
+			m.archiveSearchInput.SetValue("")
+			m.archiveMatches = nil
+			m.archiveIndex = 0
+			return m, nil
+		}
+		m.modal = noModal
+		return m, nil
+
+	case "/":
+		m.modalFocused = 0
+		m.archiveSearchInput.Focus()
+		return m, nil
+
+	case "up", "k":
+		if m.archiveIndex > 0 {
+			m.archiveIndex--
+		}
+		return m, nil
+
+	case "down", "j":
+		if m.archiveIndex < count-1 {
+			m.archiveIndex++
+		}
+		return m, nil
+
+	case "o":
+		if archive, _, ok := m.selectedArchive(); ok {
+			return m, m.openInEditor(archive.Path)
+		}
+		return m, nil
+
+	case "enter":
+		archive, line, ok := m.selectedArchive()
+		if !ok {
+			return m, nil
+		}
+		content, err := os.ReadFile(archive.Path)
+		if err != nil {
+			return m, m.showErrorNotification("Failed to read archive: "+err.Error(), 3*time.Second)
+		}
+		m.archiveLines = strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+		m.archiveViewPath = archive.Path
+		m.archiveViewConv = nil
+		m.archiveViewTitle = "Transcript: " + archive.Session + "/" + filepath.Base(archive.Path)
+		m.archiveViewReturn = archiveListModal
+		m.archiveScroll = line
+		m.modal = archiveViewModal
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// selectedArchive returns the selected archive and the line to open it at
+func (m Model) selectedArchive() (session.Archive, int, bool) {
+	if m.archiveSearchInput.Value() != "" {
+		if m.archiveIndex < len(m.archiveMatches) {
+			match := m.archiveMatches[m.archiveIndex]
+			return match.Archive, match.Line, true
+		}
+		return session.Archive{}, 0, false
+	}
+	if m.archiveIndex < len(m.archives) {
+		return m.archives[m.archiveIndex], 0, true
+	}
+	return session.Archive{}, 0, false
+}
+
+// archiveViewHeight is the number of transcript lines shown in the archive viewer
+func (m Model) archiveViewHeight() int {
+	if m.height > 14 {
+		return m.height - 12
+	}
+	return 5
+}
+
+func (m Model) handleArchiveViewModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	maxScroll := len(m.archiveLines) - m.archiveViewHeight()
+	if maxScroll < 0 {
+		maxScroll = 0
+	}
+	query := strings.ToLower(strings.TrimSpace(m.archiveSearchInput.Value()))
+
+	switch msg.String() {
+	case "esc", "q":
+		m.modal = m.archiveViewReturn
+		m.archiveLines = nil
+		m.archiveViewConv = nil
+		return m, nil
+
+	case "r":
+		// Reload (e.g. output of a script that is still running, or a conversation the agent is still writing)
+		if m.archiveViewConv != nil {
+			return m, m.loadTranscript(m.conversationBranch, *m.archiveViewConv)
+		}
+		if content, err := os.ReadFile(m.archiveViewPath); err == nil {
+			atEnd := m.archiveScroll >= maxScroll
+			m.archiveLines = strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+			maxScroll = max(len(m.archiveLines)-m.archiveViewHeight(), 0)
+			if atEnd {
+				m.archiveScroll = maxScroll
+			}
+		}
+
+	case "up", "k":
+		m.archiveScroll--
+
+	case "down", "j":
+		m.archiveScroll++
+
+	case "pgup", "ctrl+u":
+		m.archiveScroll -= m.archiveViewHeight()
+
+	case "pgdown", "ctrl+d", " ":
+		m.archiveScroll += m.archiveViewHeight()
+
+	case "home", "g":
+		m.archiveScroll = 0
+
+	case "end", "G":
+		m.archiveScroll = maxScroll
+
+	case "n":
+		// Jump to the next line containing the search query
+		if query != "" {
+			for i := m.archiveScroll + 1; i < len(m.archiveLines); i++ {
+				if strings.Contains(strings.ToLower(m.archiveLines[i]), query) {
+					m.archiveScroll = i
+					break
+				}
+			}
+		}
+
+	case "N":
+		// Jump to the previous line containing the search query
+		if query != "" {
+			for i := m.archiveScroll - 1; i >= 0; i-- {
+				if strings.Contains(strings.ToLower(m.archiveLines[i]), query) {
+					m.archiveScroll = i
+					break
+				}
+			}
+		}
+
+	case "o":
+		return m, m.openInEditor(m.archiveViewPath)
+	}
+
+	if m.archiveScroll > maxScroll {
+		m.archiveScroll = maxScroll
+	}
+	if m.archiveScroll < 0 {
+		m.archiveScroll = 0
+	}
+	return m, nil
+}
+
+func (m Model) handleScriptsModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	return m.handleListSelectionModalInput(msg, listSelectionConfig{
+		getCurrentIndex: func() int { return m.scriptIndex },
+		getItemCount:    func(m Model) int { return len(m.scriptNames) },
+		incrementIndex:  func(m *Model) { m.scriptIndex++ },
+		decrementIndex:  func(m *Model) { m.scriptIndex-- },
+		onConfirm: func(m Model) (tea.Model, tea.Cmd) {
+			wt := m.selectedWorktree()
+			if wt == nil || m.scriptIndex >= len(m.scriptNames) {
+				return m, nil
+			}
+			m.modal = noModal
+			return m, m.runScript(*wt, m.scriptNames[m.scriptIndex])
+		},
+		onCustomKey: func(m Model, key string) (tea.Model, tea.Cmd) {
+			if key != "o" {
+				return m, nil
+			}
+			wt := m.selectedWorktree()
+			if wt == nil || m.scriptIndex >= len(m.scriptNames) {
+				return m, nil
+			}
+			run, err := session.GetScriptRun(wt.ClaudeSessionName, m.scriptNames[m.scriptIndex])
+			if err != nil {
+				return m, m.showWarningNotification("This script hasn't run in this worktree yet")
+			}
+			return m.openScriptOutput(wt.Branch, *run, scriptsModal)
+		},
+	})
+}
+
+// openScriptOutput shows the captured output of a script run in the viewer, scrolled to the end
+func (m Model) openScriptOutput(branch string, run session.ScriptRun, returnTo modalType) (tea.Model, tea.Cmd) {
+	content, err := os.ReadFile(run.LogPath)
+	if err != nil {
+		return m, m.showErrorNotification("Failed to read script output: "+err.Error(), 3*time.Second)
+	}
+	m.archiveSearchInput.SetValue("") // No search highlighting in script output
+	m.archiveLines = strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+	m.archiveViewPath = run.LogPath
+	m.archiveViewConv = nil
+	m.archiveViewTitle = fmt.Sprintf("Output: %s (%s) - %s", run.Name, branch, run.Status)
+	m.archiveViewReturn = returnTo
+	m.archiveScroll = max(len(m.archiveLines)-m.archiveViewHeight(), 0)
+	m.modal = archiveViewModal
+	return m, nil
+}
+
+// handlePromptModalInput handles the prompt modal: the editor (modalFocused 0) and the prompt library (1)
+func (m Model) handlePromptModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg.String() {
+	case "esc":
+		m.promptInput.Blur()
+		m.modal = noModal
+		return m, nil
+
+	case "tab", "shift+tab":
+		if len(m.prompts) == 0 {
+			return m, nil
+		}
+		if m.modalFocused == 0 {
+			m.modalFocused = 1
+			m.promptInput.Blur()
+			return m, nil
+		}
+		m.modalFocused = 0
+		return m, m.promptInput.Focus()
+
+	case "ctrl+s":
+		// Send what's in the editor
+		prompt := m.promptInput.Value()
+		if strings.TrimSpace(prompt) == "" {
+			return m, m.showWarningNotification("Write a prompt first (or pick one from the library with Tab)")
+		}
+		m.promptInput.Blur()
+		m.modal = noModal
+		return m, m.sendPrompt(prompt, m.promptTargets)
+	}
+
+	if m.modalFocused == 1 {
+		switch msg.String() {
+		case "up":
+			if m.promptIndex > 0 {
+				m.promptIndex--
+			}
+		case "down":
+			if m.promptIndex < len(m.prompts)-1 {
+				m.promptIndex++
+			}
+		case "enter":
+			// Send the library prompt as is
+			if m.promptIndex < len(m.prompts) {
+				m.modal = noModal
+				return m, m.sendPrompt(m.prompts[m.promptIndex].Text, m.promptTargets)
+			}
+		case "e":
+			// Copy the library prompt into the editor to adjust it before sending
+			if m.promptIndex < len(m.prompts) {
+				m.promptInput.SetValue(m.prompts[m.promptIndex].Text)
+				m.modalFocused = 0
+				return m, m.promptInput.Focus()
+			}
+		}
+		return m, nil
+	}
+
+	m.promptInput, cmd = m.promptInput.Update(msg)
+	return m, cmd
+}
+
+// handleReattachModalInput hands the selected orphaned session over to the chosen worktree
+func (m Model) handleReattachModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	return m.handleListSelectionModalInput(msg, listSelectionConfig{
+		getCurrentIndex: func() int { return m.reattachIndex },
+		getItemCount:    func(m Model) int { return len(m.reattachChoices) },
+		incrementIndex:  func(m *Model) { m.reattachIndex++ },
+		decrementIndex:  func(m *Model) { m.reattachIndex-- },
+		onConfirm: func(m Model) (tea.Model, tea.Cmd) {
+			m.modal = sessionListModal
+			if m.sessionIndex >= len(m.sessions) || m.reattachIndex >= len(m.reattachChoices) {
+				return m, nil
+			}
+			sess := m.sessions[m.sessionIndex]
+			wt := m.reattachChoices[m.reattachIndex]
+			if err := m.sessionManager.Reattach(sess.Name, wt.ClaudeSessionName, wt.Path); err != nil {
+				return m, tea.Batch(m.showErrorNotification("Failed to reattach session: "+err.Error(), 4*time.Second), m.loadSessions())
+			}
+			return m, tea.Batch(m.showSuccessNotification(fmt.Sprintf("Session reattached to %s", wt.Branch), 3*time.Second), m.loadSessions())
+		},
+		onCancel: func(m Model) (tea.Model, tea.Cmd) {
+			m.modal = sessionListModal
+			return m, nil
+		},
+	})
+}
+
+// selectedSessionUsage returns the resource usage of the session selected in the session list
+func (m Model) selectedSessionUsage() session.SessionUsage {
+	if m.sessionIndex < 0 || m.sessionIndex >= len(m.sessions) {
+		return session.SessionUsage{}
+	}
+	return m.sessionUsages[m.sessions[m.sessionIndex].Name]
+}
+
+// handleProcessModalInput handles the process list of a session: d asks the selected process to terminate
+func (m Model) handleProcessModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	return m.handleListSelectionModalInput(msg, listSelectionConfig{
+		getCurrentIndex: func() int { return m.processIndex },
+		getItemCount:    func(m Model) int { return len(m.selectedSessionUsage().Processes) },
+		incrementIndex:  func(m *Model) { m.processIndex++ },
+		decrementIndex:  func(m *Model) { m.processIndex-- },
+		onConfirm: func(m Model) (tea.Model, tea.Cmd) {
+			return m, nil
+		},
+		onCancel: func(m Model) (tea.Model, tea.Cmd) {
+			m.modal = sessionListModal
+			return m, nil
+		},
+		onCustomKey: func(m Model, key string) (tea.Model, tea.Cmd) {
+			processes := m.selectedSessionUsage().Processes
+			if key != "d" || m.processIndex >= len(processes) {
+				return m, nil
+			}
+			proc := processes[m.processIndex]
+			if err := session.KillProcess(proc.PID); err != nil {
+				return m, m.showErrorNotification(err.Error(), 3*time.Second)
+			}
+			m.lastUsageCheck = time.Now()
+			return m, tea.Batch(m.showSuccessNotification(fmt.Sprintf("Sent SIGTERM to %d", proc.PID), 2*time.Second), m.loadSessionUsage())
+		},
+	})
+}
+
+func (m Model) handleTimeReportModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.modal = noModal
+		return m, nil
+
+	case "up", "k":
+		if m.timeReportScroll > 0 {
+			m.timeReportScroll--
+		}
+		return m, nil
+
+	case "down", "j":
+		if m.timeReportScroll < len(m.visibleTimeReportRows())-1 {
+			m.timeReportScroll++
+		}
+		return m, nil
+
+	case "tab", "right", "l":
+		m.timeReportRange = (m.timeReportRange + 1) % len(timeReportRanges)
+		return m, m.loadTimeReport()
+
+	case "shift+tab", "left", "h":
+		m.timeReportRange = (m.timeReportRange + len(timeReportRanges) - 1) % len(timeReportRanges)
+		return m, m.loadTimeReport()
+
+	case "d":
+		m.timeReportByDay = !m.timeReportByDay
+		m.timeReportScroll = 0
+		return m, nil
+
+	case "t":
+		if m.configManager == nil {
+			return m, nil
+		}
+		enabled := !m.configManager.GetTimeTracking()
+		if err := m.configManager.SetTimeTracking(enabled); err != nil {
+			return m, m.showErrorNotification("Failed to save time tracking setting: "+err.Error(), 3*time.Second)
+		}
+		if !enabled {
+			return m, m.showInfoNotification("Time tracking disabled")
+		}
+		// Time is recorded by the background watcher
+		return m, tea.Batch(m.startWatcher(), m.showSuccessNotification("Time tracking enabled", 2*time.Second))
+
+	case "c", "J":
+		// Export every branch and day of the period to ~/.config/<cli>/reports
+		ext := ".csv"
+		if msg.String() == "J" {
+			ext = ".json"
+		}
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return m, m.showErrorNotification("Failed to export report: "+err.Error(), 3*time.Second)
+		}
+		dir := filepath.Join(home, ".config", branding.ConfigDirName, "reports")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return m, m.showErrorNotification("Failed to export report: "+err.Error(), 3*time.Second)
+		}
+		name := fmt.Sprintf("%s-%s%s", filepath.Base(m.repoPath), time.Now().Format("2006-01-02"), ext)
+		path := filepath.Join(dir, name)
+		if err := session.ExportTimeReport(path, m.timeReportRows); err != nil {
+			return m, m.showErrorNotification("Failed to export report: "+err.Error(), 3*time.Second)
+		}
+		return m, m.showSuccessNotification("Report saved to "+path, 4*time.Second)
+	}
+
+	return m, nil
+}
+
+// recordConversations saves the conversations busy agents were seen writing, so their worktrees resume them
+func (m Model) recordConversations(conversations map[string]string) {
+	if m.configManager == nil {
+		return
+	}
+	for branch, id := range conversations {
+		agents := m.worktreeAgents(branch)
+		if len(agents) == 0 {
+			continue
+		}
+		conv := config.AgentConversation{Agent: agents[0].Name, ID: id}
+		if recorded, ok := m.configManager.GetConversation(m.repoPath, branch); ok && recorded == conv {
+			continue
+		}
+		if err := m.configManager.SetConversation(m.repoPath, branch, conv); err != nil {
+			m.debugLog(fmt.Sprintf("DEBUG: failed to record conversation for %s: %v", branch, err))
+		}
+	}
+}
+
+func (m Model) handleConversationsModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.modal = noModal
+		return m, nil
+
+	case "up", "k":
+		if m.conversationIndex > 0 {
+			m.conversationIndex--
+		}
+		return m, nil
+
+	case "down", "j":
+		if m.conversationIndex < len(m.conversations)-1 {
+			m.conversationIndex++
+		}
+		return m, nil
+
+	case "v":
+		// Read the selected conversation's messages and tool calls
+		if m.conversationIndex < len(m.conversations) {
+			return m, m.loadTranscript(m.conversationBranch, m.conversations[m.conversationIndex])
+		}
+		return m, nil
+
+	case "enter", "n":
+		wt := m.selectedWorktree()
+		agents := m.worktreeAgents(m.conversationBranch)
+		if wt == nil || wt.Branch != m.conversationBranch || len(agents) == 0 || m.configManager == nil {
+			return m, nil
+		}
+		fresh := msg.String() == "n"
+		if !fresh && m.conversationIndex >= len(m.conversations) {
+			return m, nil
+		}
+
+		// Record the choice for the next start: a fresh start forgets the conversation
+		var err error
+		if fresh {
+			if err = m.configManager.SetConversation(m.repoPath, wt.Branch, config.AgentConversation{}); err == nil {
+				err = m.configManager.ClearClaudeInitialized(m.repoPath, wt.Branch)
+			}
+		} else {
+			conv := m.conversations[m.conversationIndex]
+			if err = m.configManager.SetConversation(m.repoPath, wt.Branch, config.AgentConversation{Agent: conv.Agent, ID: conv.ID}); err == nil {
+				err = m.configManager.SetClaudeInitialized(m.repoPath, wt.Branch)
+			}
+		}
+		if err != nil {
+			return m, m.showErrorNotification("Failed to save conversation choice: "+err.Error(), 3*time.Second)
+		}
+		m.modal = noModal
+
+		// Switch the running agent over right away
+		if m.backend.Name() == session.BackendTmux && m.sessionManager.SessionExists(wt.ClaudeSessionName) {
+			if fresh {
+				_ = m.configManager.SetClaudeInitialized(m.repoPath, wt.Branch)
+			}
+			if err := m.sessionManager.RestartAgent(wt.ClaudeSessionName, wt.Path, agents[0], !fresh, m.resumeID(wt.Branch)); err != nil {
+				return m, m.showErrorNotification("Failed to restart agent: "+err.Error(), 4*time.Second)
+			}
+			if fresh {
+				return m, m.showSuccessNotification("Started a fresh conversation in "+wt.Branch, 3*time.Second)
+			}
+			return m, m.showSuccessNotification("Resumed conversation in "+wt.Branch, 3*time.Second)
+		}
+		if fresh {
+			return m, m.showSuccessNotification("The next session for "+wt.Branch+" starts a fresh conversation", 3*time.Second)
+		}
+		return m, m.showSuccessNotification("The next session for "+wt.Branch+" resumes this conversation", 3*time.Second)
+	}
+
+	return m, nil
+}
+
+func (m Model) handleAgentArgsModalInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Any other key takes back a pending restart confirmation
+	confirmed := m.agentArgsConfirm
+	m.agentArgsConfirm = false
+
+	switch msg.String() {
+	case "esc":
+		m.agentArgsExtraInput.Blur()
+		if m.agentArgsLaunch {
+			m.modal = noModal
+		} else {
+			m.modal = settingsModal
+			m.settingsIndex = 10 // Go back to Agent Arguments option in settings
+		}
+		return m, nil
+
+	case "tab", "down":
+		m.agentArgsFocus = (m.agentArgsFocus + 1) % 3
+		m.focusAgentArgsInput()
+		return m, nil
+
+	case "shift+tab", "up":
+		m.agentArgsFocus = (m.agentArgsFocus + 2) % 3
+		m.focusAgentArgsInput()
+		return m, nil
+
+	case "left", "right":
+		if m.agentArgsFocus == 2 {
+			break // Move the cursor in the input
+		}
+		step := 1
+		if msg.String() == "left" {
+			step = -1
+		}
+		if m.agentArgsFocus == 0 {
+			scopes := m.agentArgsScopes()
+			for i, scope := range scopes {
+				if scope == m.agentArgsScope {
+					m.agentArgsScope = scopes[(i+step+len(scopes))%len(scopes)]
+					break
+				}
+			}
+			m.loadAgentArgsScope()
+		} else {
+			m.agentArgsModeIndex = (m.agentArgsModeIndex + step + len(m.agentArgsModes)) % len(m.agentArgsModes)
+		}
+		return m, nil
+
+	case "enter":
+		args, err := m.agentArgsInput()
+		if err != nil {
+			return m, m.showErrorNotification("Invalid arguments: "+err.Error(), 3*time.Second)
+		}
+		if !confirmed && m.agentArgsRestarts() {
+			// The running agent would be killed mid-task without asking
+			m.agentArgsConfirm = true
+			return m, nil
+		}
+		if m.configManager != nil {
+			switch m.agentArgsScope {
+			case agentArgsRepo:
+				err = m.configManager.SetRepoAgentArgs(m.repoPath, args)
+			case agentArgsWorktree:
+				err = m.configManager.SetWorktreeAgentArgs(m.repoPath, m.agentArgsBranch, args)
+			}
+			if err != nil {
+				return m, m.showErrorNotification("Failed to save agent arguments: "+err.Error(), 3*time.Second)
+			}
+			if m.agentArgsScope != agentArgsLaunch {
+				args = m.configManager.GetAgentArgs(m.repoPath, m.agentArgsBranch)
+			}
+		}
+		m.agentArgsExtraInput.Blur()
+
+		if !m.agentArgsLaunch {
+			m.modal = settingsModal
+			m.settingsIndex = 10
+			return m, m.showSuccessNotification("Agent arguments saved (used when the agent next starts)", 3*time.Second)
+		}
+		m.modal = noModal
+		for _, wt := range m.worktrees {
+			if wt.Branch == m.agentArgsBranch {
+				return m.switchToAgent(wt, &args)
+			}
+		}
+		return m, nil
+	}
+
+	if m.agentArgsFocus == 2 {
+		var cmd tea.Cmd
+		m.agentArgsExtraInput, cmd = m.agentArgsExtraInput.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
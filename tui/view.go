@@ -4,10 +4,16 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+	"time"
+	"unicode"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/andrew-bierman/jean-tui/config"
+	"github.com/andrew-bierman/jean-tui/git"
+	"github.com/andrew-bierman/jean-tui/internal/branding"
 	"github.com/andrew-bierman/jean-tui/internal/version"
+	"github.com/andrew-bierman/jean-tui/session"
 )
 
 // View renders the TUI
@@ -114,6 +120,10 @@ func (m Model) renderWorktreeList() string {
 		if branch == "" {
 			branch = "(no branch)"
 		}
+		if m.markedWorktrees[wt.Branch] {
+			// Marked for broadcasting prompts
+			branch = "◆ " + branch
+		}
 
 
 		// For current worktree, show it's the main repo
@@ -134,8 +144,19 @@ func (m Model) renderWorktreeList() string {
 				behindIndicator := fmt.Sprintf(" ↓%d", wt.BehindCount)
 				line += normalItemStyle.Copy().Foreground(warningColor).Render(behindIndicator)
 			}
+
+			// Show predicted conflict indicator (with base or another worktree)
+			if m.conflictMatrix.HasConflicts(wt.Branch) {
+				line += normalItemStyle.Copy().Foreground(errorColor).Render(" ⚡")
+			}
 		}
 
+		// Show agent state badge (busy/waiting/idle/exited) from the last activity check
+		line += m.renderAgentStateBadge(wt.Branch)
+
+		// Show background script status (running/passed/failed)
+		line += m.renderScriptBadge(wt.Branch)
+
 
 		b.WriteString(style.Render(line))
 		b.WriteString("\n")
@@ -144,6 +165,105 @@ func (m Model) renderWorktreeList() string {
 	return b.String()
 }
 
+// renderAgentPreview renders the last lines of the agent window, keeping the agent's colours
+func (m Model) renderAgentPreview(branch string) string {
+	var b strings.Builder
+	b.WriteString("\n")
+	b.WriteString(detailKeyStyle.Render("Agent Output:"))
+	b.WriteString(normalItemStyle.Copy().Foreground(mutedColor).Render(" (V to hide)"))
+	b.WriteString("\n")
+
+	if m.agentPreviewBranch != branch {
+		// Captured with the next activity check
+		b.WriteString(normalItemStyle.Copy().Foreground(mutedColor).Render("  Loading..."))
+		b.WriteString("\n")
+		return b.String()
+	}
+	if len(m.agentPreviewLines) == 0 {
+		b.WriteString(normalItemStyle.Copy().Foreground(mutedColor).Render("  No agent output (press Enter to start one)"))
+		b.WriteString("\n")
+		return b.String()
+	}
+
+	// Truncate instead of letting the panel wrap, so the preview keeps its shape
+	width := max((m.width-6)/2-4, 10)
+	for _, line := range m.agentPreviewLines {
+		b.WriteString(ansi.Truncate(line, width, "…"))
+		b.WriteString("\x1b[0m\n") // Don't let the agent's colours bleed into the next line
+	}
+	return b.String()
+}
+
+// renderAgentStateBadge renders the detected agent state of a worktree's session
+func (m Model) renderAgentStateBadge(branch string) string {
+	state, ok := m.agentStates[branch]
+	if !ok {
+		return ""
+	}
+
+	style := normalItemStyle.Copy()
+	switch state {
+	case session.AgentStateBusy:
+		style = style.Foreground(successColor)
+	case session.AgentStateWaiting:
+		style = style.Foreground(warningColor).Bold(true)
+	case session.AgentStateExited:
+		style = style.Foreground(errorColor)
+	default:
+		style = style.Foreground(mutedColor)
+	}
+	return style.Render(fmt.Sprintf(" [%s]", state))
+}
+
+// scriptStatusStyle returns the style and icon for a script run status
+func scriptStatusStyle(status session.ScriptStatus) (lipgloss.Style, string) {
+	switch status {
+	case session.ScriptRunning:
+		return normalItemStyle.Copy().Foreground(warningColor), "⟳"
+	case session.ScriptPassed:
+		return normalItemStyle.Copy().Foreground(successColor), "✓"
+	default:
+		return normalItemStyle.Copy().Foreground(errorColor), "✗"
+	}
+}
+
+// renderScriptBadge summarizes a worktree's background scripts: running if any runs, else failed if any failed
+func (m Model) renderScriptBadge(branch string) string {
+	runs := m.scriptRuns[branch]
+	if len(runs) == 0 {
+		return ""
+	}
+
+	status := session.ScriptPassed
+	for _, run := range runs {
+		if run.Status == session.ScriptRunning {
+			status = session.ScriptRunning
+			break
+		}
+		if run.Status == session.ScriptFailed {
+			status = session.ScriptFailed
+		}
+	}
+	style, icon := scriptStatusStyle(status)
+	return style.Render(" ⚙" + icon)
+}
+
+// formatScriptRun describes a script run's status and duration
+func formatScriptRun(run session.ScriptRun) string {
+	duration := run.Duration().Round(time.Second)
+	switch run.Status {
+	case session.ScriptRunning:
+		return fmt.Sprintf("running for %s", duration)
+	case session.ScriptPassed:
+		return fmt.Sprintf("passed in %s", duration)
+	default:
+		if run.ExitCode < 0 {
+			return "interrupted"
+		}
+		return fmt.Sprintf("failed (exit %d) in %s", run.ExitCode, duration)
+	}
+}
+
 func (m Model) renderDetails() string {
 	var b strings.Builder
 
@@ -205,6 +325,20 @@ func (m Model) renderDetails() string {
 		b.WriteString("\n")
 	}
 
+	// Show the worktree's allocated ports ($JEAN_PORT is the first one)
+	if m.configManager != nil {
+		if ports := m.configManager.GetPorts(m.repoPath, wt.Branch); len(ports) > 0 {
+			b.WriteString(detailKeyStyle.Render("Ports: "))
+			b.WriteString(detailValueStyle.Render(fmt.Sprintf("%d-%d", ports[0], ports[len(ports)-1])))
+			b.WriteString("  ")
+			url := fmt.Sprintf("http://localhost:%d", ports[0])
+			styledURL := normalItemStyle.Copy().Foreground(accentColor).Underline(true).Render(url)
+			// OSC 8 hyperlink so the URL is clickable in modern terminals
+			b.WriteString(fmt.Sprintf("\033]8;;%s\033\\%s\033]8;;\033\\", url, styledURL))
+			b.WriteString("\n")
+		}
+	}
+
 	// Show uncommitted changes status
 	if wt.HasUncommitted {
 		b.WriteString("\n")
@@ -252,6 +386,27 @@ func (m Model) renderDetails() string {
 		}
 	}
 
+	// Show background script runs
+	if runs := m.scriptRuns[wt.Branch]; len(runs) > 0 {
+		b.WriteString("\n")
+		b.WriteString(detailKeyStyle.Render("Scripts:"))
+		b.WriteString("\n")
+		for _, run := range runs {
+			style, icon := scriptStatusStyle(run.Status)
+			b.WriteString(style.Render(fmt.Sprintf("  %s %s", icon, run.Name)))
+			b.WriteString(normalItemStyle.Copy().Foreground(mutedColor).Render(" " + formatScriptRun(run)))
+			b.WriteString("\n")
+		}
+		b.WriteString(normalItemStyle.Copy().Foreground(accentColor).Render("  Press 'O' to view output"))
+		b.WriteString("\n")
+	}
+
+	// Show the agent's latest output instead of the action hints when the preview is on
+	if m.agentPreview {
+		b.WriteString(m.renderAgentPreview(wt.Branch))
+		return b.String()
+	}
+
 	// Add action hints
 	b.WriteString("\n")
 	b.WriteString(detailKeyStyle.Render("Actions:"))
@@ -443,6 +598,44 @@ func (m Model) renderModal() string {
 		return m.renderOnboardingModal()
 	case gitInitModal:
 		return m.renderGitInitModal()
+	case moveChangesModal:
+		return m.renderMoveChangesModal()
+	case forkModal:
+		return m.renderForkModal()
+	case resetModal:
+		return m.renderResetModal()
+	case exportPatchesModal:
+		return m.renderExportPatchesModal()
+	case importPatchesModal:
+		return m.renderImportPatchesModal()
+	case amConflictModal:
+		return m.renderAmConflictModal()
+	case conflictMatrixModal:
+		return m.renderConflictMatrixModal()
+	case windowSelectModal:
+		return m.renderWindowSelectModal()
+	case agentsModal:
+		return m.renderAgentsModal()
+	case notificationsModal:
+		return m.renderNotificationsModal()
+	case archiveListModal:
+		return m.renderArchiveListModal()
+	case archiveViewModal:
+		return m.renderArchiveViewModal()
+	case scriptsModal:
+		return m.renderScriptsModal()
+	case promptModal:
+		return m.renderPromptModal()
+	case reattachModal:
+		return m.renderReattachModal()
+	case processModal:
+		return m.renderProcessModal()
+	case timeReportModal:
+		return m.renderTimeReportModal()
+	case conversationsModal:
+		return m.renderConversationsModal()
+	case agentArgsModal:
+		return m.renderAgentArgsModal()
 	}
 	return ""
 }
@@ -527,13 +720,26 @@ func (m Model) renderCreateWithNameModal() string {
 	b.WriteString(helpStyle.Render("(leave empty for random name, or type a custom name)"))
 	b.WriteString("\n\n")
 
+	// Optional task: turns "create worktree" into "start task"
+	task := strings.TrimSpace(m.createTaskInput.Value())
+	if m.modalFocused == 1 {
+		b.WriteString(selectedItemStyle.Render("Task:"))
+	} else {
+		b.WriteString(inputLabelStyle.Render("Task:"))
+	}
+	b.WriteString("\n")
+	b.WriteString(m.createTaskInput.View())
+	b.WriteString("\n\n")
+
 	// Show info about what will be created
 	sessionName := m.sessionNameInput.Value()
 
 	b.WriteString(helpStyle.Render(fmt.Sprintf("Will create:")))
 	b.WriteString("\n")
 
-	if sessionName == "" {
+	if sessionName == "" && task != "" {
+		b.WriteString(helpStyle.Render("  Branch: <named after the task>"))
+	} else if sessionName == "" {
 		// Empty input - will generate random name
 		b.WriteString(helpStyle.Render(fmt.Sprintf("  Branch: <random name will be generated>")))
 	} else {
@@ -549,27 +755,47 @@ func (m Model) renderCreateWithNameModal() string {
 	}
 
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render(fmt.Sprintf("  Claude will automatically continue previous conversations")))
+	if task != "" {
+		b.WriteString(helpStyle.Render("  The task is saved in the worktree and the agent starts working on it"))
+	} else {
+		b.WriteString(helpStyle.Render(fmt.Sprintf("  Claude will automatically continue previous conversations")))
+	}
+	b.WriteString("\n\n")
+
+	// Agent for the new worktree
+	agentLabel := m.createAgent
+	if agentLabel == "" {
+		agentLabel = "repository default"
+		if m.configManager != nil {
+			agentLabel = fmt.Sprintf("repository default (%s)", m.configManager.GetRepoAgent(m.repoPath))
+		}
+	}
+	b.WriteString(inputLabelStyle.Render("Agent: "))
+	b.WriteString(detailValueStyle.Render(agentLabel))
+	b.WriteString(helpStyle.Render("  (ctrl+n to change)"))
 	b.WriteString("\n\n")
 
 	// Buttons (Create and Cancel)
 	createBtn := "Create"
+	if task != "" {
+		createBtn = "Start Task"
+	}
 	cancelBtn := "Cancel"
 
-	if m.modalFocused == 1 {
+	if m.modalFocused == 2 {
 		b.WriteString(selectedButtonStyle.Render(createBtn))
 	} else {
 		b.WriteString(buttonStyle.Render(createBtn))
 	}
 
-	if m.modalFocused == 2 {
+	if m.modalFocused == 3 {
 		b.WriteString(selectedCancelButtonStyle.Render(cancelBtn))
 	} else {
 		b.WriteString(cancelButtonStyle.Render(cancelBtn))
 	}
 
 	b.WriteString("\n\n")
-	b.WriteString(helpStyle.Render("Tab to navigate • Enter to confirm • Esc to cancel"))
+	b.WriteString(helpStyle.Render("Tab to navigate • Enter to confirm • Ctrl+S to create from any field • Esc to cancel"))
 
 	return lipgloss.Place(
 		m.width, m.height,
@@ -847,6 +1073,28 @@ func min(a, b int) int {
 	return b
 }
 
+// renderRestorableSessions lists the sessions recorded before a tmux restart or reboot
+func (m Model) renderRestorableSessions() string {
+	var b strings.Builder
+	b.WriteString(detailKeyStyle.Render(fmt.Sprintf("Restorable (%d):", len(m.restorableSessions))))
+	b.WriteString("\n")
+	for i, sess := range m.restorableSessions {
+		if i == 8 {
+			b.WriteString(normalItemStyle.Copy().Foreground(mutedColor).Render(fmt.Sprintf("  ... and %d more", len(m.restorableSessions)-i)))
+			b.WriteString("\n")
+			break
+		}
+		agent := ""
+		if sess.AgentRunning {
+			agent = " (agent)"
+		}
+		b.WriteString(normalItemStyle.Copy().Foreground(mutedColor).Render(fmt.Sprintf("  ↺ %s%s", sess.Name, agent)))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
 func (m Model) renderSessionListModal() string {
 	var b strings.Builder
 
@@ -856,7 +1104,12 @@ func (m Model) renderSessionListModal() string {
 	if len(m.sessions) == 0 {
 		b.WriteString(normalItemStyle.Render("No active sessions found"))
 		b.WriteString("\n\n")
-		b.WriteString(helpStyle.Render("Press Esc to close"))
+		if len(m.restorableSessions) > 0 {
+			b.WriteString(m.renderRestorableSessions())
+			b.WriteString(helpStyle.Render("r restore all • f forget • Esc close"))
+		} else {
+			b.WriteString(helpStyle.Render("Press Esc to close"))
+		}
 	} else {
 		// Show sessions
 		maxVisible := 10
@@ -897,6 +1150,13 @@ func (m Model) renderSessionListModal() string {
 
 			line := fmt.Sprintf("%s %s %s%s", statusIcon, sessionTypeIcon, sess.Branch, statusText)
 			b.WriteString(style.Render(line))
+			if usage, ok := m.sessionUsages[sess.Name]; ok {
+				b.WriteString(normalItemStyle.Copy().Foreground(mutedColor).Render(fmt.Sprintf("  CPU %.0f%% · %s", usage.CPU, session.FormatBytes(usage.RSS))))
+			}
+			if orphan, ok := m.sessionOrphans[sess.Name]; ok {
+				// Flag sessions that no longer match a worktree
+				b.WriteString(normalItemStyle.Copy().Foreground(warningColor).Render(" ⚠ " + orphan.Kind.String()))
+			}
 			b.WriteString("\n")
 		}
 
@@ -904,7 +1164,18 @@ func (m Model) renderSessionListModal() string {
 		b.WriteString(helpStyle.Render(fmt.Sprintf("Showing %d-%d of %d sessions", start+1, end, len(m.sessions))))
 		b.WriteString("\n\n")
 
-		b.WriteString(helpStyle.Render("↑↓ navigate • Enter attach • d kill • Esc close"))
+		help := "↑↓ navigate • Enter attach • l apply layout • d kill • Esc close"
+		if len(m.restorableSessions) > 0 {
+			b.WriteString(m.renderRestorableSessions())
+			help = "↑↓ navigate • Enter attach • l apply layout • d kill • r restore all • f forget • Esc close"
+		}
+		b.WriteString(helpStyle.Render(help))
+		b.WriteString("\n")
+		b.WriteString(helpStyle.Render("p processes • K kill heaviest session"))
+		if len(m.sessionOrphans) > 0 {
+			b.WriteString("\n")
+			b.WriteString(helpStyle.Render(fmt.Sprintf("%d orphaned • x kill orphans • a reattach to worktree • n rename to match branch", len(m.sessionOrphans))))
+		}
 	}
 
 	return lipgloss.Place(
@@ -1598,6 +1869,71 @@ func (m Model) renderSettingsModal() string {
 				return "Ready for Review"
 			},
 		},
+		{
+			name:        "Agent",
+			key:         "g",
+			description: "Default coding agent for new sessions (Enter to cycle; Shift+A sets agents per worktree)",
+			getCurrent: func() string {
+				if m.configManager != nil {
+					if agent := m.configManager.GetRepoAgent(m.repoPath); agent != "" {
+						return agent
+					}
+				}
+				return "None (blank terminal)"
+			},
+		},
+		{
+			name:        "Notifications",
+			key:         "n",
+			description: "Alerts when an agent finishes or needs input (bell, tmux message, desktop)",
+			getCurrent: func() string {
+				if m.configManager == nil {
+					return "Disabled"
+				}
+				cfg := m.configManager.GetNotificationConfig()
+				var enabled []string
+				if cfg.Bell {
+					enabled = append(enabled, "bell")
+				}
+				if cfg.TmuxMessage {
+					enabled = append(enabled, "tmux message")
+				}
+				if cfg.Desktop {
+					enabled = append(enabled, "desktop")
+				}
+				if cfg.Command != "" {
+					enabled = append(enabled, "command")
+				}
+				if len(enabled) == 0 {
+					return "Disabled"
+				}
+				return strings.Join(enabled, ", ")
+			},
+		},
+		{
+			name:        "Multiplexer",
+			key:         "m",
+			description: "Where sessions run: tmux (all features), zellij (no agent state, alerts or archives) or none (cd only)",
+			getCurrent: func() string {
+				if !m.backend.IsAvailable() {
+					return m.backend.Name() + " (not installed)"
+				}
+				return m.backend.Name()
+			},
+		},
+		{
+			name:        "Agent Arguments",
+			key:         "x",
+			description: "Permission mode and extra flags (e.g. --model) of the main agent, per repository or worktree",
+			getCurrent: func() string {
+				if m.configManager != nil {
+					if args := m.configManager.GetRepoAgentArgs(m.repoPath); !args.IsZero() {
+						return formatAgentArgs(args)
+					}
+				}
+				return "Agent defaults"
+			},
+		},
 	}
 
 	// Render settings list
@@ -2021,12 +2357,21 @@ func (m Model) renderHelperModal() string {
 			}{
 				{"↑", "Move cursor up"},
 				{"↓", "Move cursor down"},
-				{"n", "Create new worktree (with AI)"},
+				{"n", "Create new worktree, or start a task (brief + agent prompt)"},
 				{"a", "Create new worktree (from existing branch)"},
 				{"enter", "Open CLI (Claude for now)"},
 				{"t", "Open terminal"},
+				{"w", "Open session window (layout)"},
+				{"A", "Choose agents for worktree"},
 				{"o", "Open default editor"},
 				{"d", "Delete selected worktree"},
+				{"F", "Fork worktree (new branch from its HEAD)"},
+				{"X", "Run jean.json script in the background"},
+				{"O", "View output of the last script run"},
+				{"space", "Mark worktree for broadcasting prompts"},
+				{"I", "Send prompt to agent (marked or selected worktrees)"},
+				{"V", "Toggle live agent output in details"},
+				{"G", "Open agent with other arguments (permission mode, extra flags)"},
 			},
 		},
 		{
@@ -2042,6 +2387,11 @@ func (m Model) renderHelperModal() string {
 				{"b", "Change base branch for new worktrees"},
 				{"B", "Rename current branch"},
 				{"K", "Checkout/switch branch in main repo"},
+				{"m", "Move uncommitted changes to another worktree"},
+				{"R", "Reset/clean worktree (undoable)"},
+				{"x", "Export commits as patches or bundle"},
+				{"i", "Import patches or bundle (git am)"},
+				{"C", "Conflict matrix across worktrees (⚡ = predicted conflict)"},
 			},
 		},
 		{
@@ -2065,6 +2415,9 @@ func (m Model) renderHelperModal() string {
 				{"s", "Open settings"},
 				{"e", "Select default editor"},
 				{"S", "View tmux sessions"},
+				{"H", "Browse archived session transcripts"},
+				{"T", "Time report (active time per branch)"},
+				{"E", "Agent conversations (resume, start fresh or read a transcript)"},
 				{"g", "Open repo in browser"},
 				{"h", "Show this help"},
 				{"q", "Quit application"},
@@ -2336,73 +2689,113 @@ func (m Model) renderPostMergeCleanupModal() string {
 	)
 }
 
-func (m Model) renderOnboardingModal() string {
+func (m Model) renderMoveChangesModal() string {
 	var b strings.Builder
 
-	b.WriteString(modalTitleStyle.Render("Welcome to jean!"))
+	b.WriteString(modalTitleStyle.Render("📦 Move Uncommitted Changes"))
 	b.WriteString("\n\n")
 
-	// Brief introduction
-	b.WriteString(normalItemStyle.Render("jean is a TUI for managing Git worktrees with tmux + Claude integration."))
+	b.WriteString(detailKeyStyle.Render("From: "))
+	b.WriteString(detailValueStyle.Render(m.moveChangesSourceBranch))
 	b.WriteString("\n\n")
 
-	// Explain shell integration
-	b.WriteString(detailKeyStyle.Render("✓ Shell Integration"))
-	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("  Your shell wrapper is already configured and active!"))
+	// Options
+	checkbox := func(enabled bool) string {
+		if enabled {
+			return "[x]"
+		}
+		return "[ ]"
+	}
+	b.WriteString(normalItemStyle.Render(fmt.Sprintf("%s Include staged changes (s)", checkbox(m.moveChangesIncludeStaged))))
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("  You can now switch worktrees seamlessly."))
+	b.WriteString(normalItemStyle.Render(fmt.Sprintf("%s Include untracked files (u)", checkbox(m.moveChangesIncludeUntracked))))
 	b.WriteString("\n\n")
 
-	// Explain tmux config
-	b.WriteString(detailKeyStyle.Render("⚙  Tmux Configuration (Optional)"))
-	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("  We recommend installing our opinionated tmux config:"))
-	b.WriteString("\n")
-	b.WriteString(normalItemStyle.Copy().Foreground(mutedColor).Render("  • Mouse support for scrolling"))
+	b.WriteString(normalItemStyle.Render("Move to:"))
 	b.WriteString("\n")
-	b.WriteString(normalItemStyle.Copy().Foreground(mutedColor).Render("  • Ctrl-D to detach (familiar keybinding)"))
-	b.WriteString("\n")
-	b.WriteString(normalItemStyle.Copy().Foreground(mutedColor).Render("  • 10,000 line scrollback buffer"))
-	b.WriteString("\n")
-	b.WriteString(normalItemStyle.Copy().Foreground(mutedColor).Render("  • Clean status bar with repo:branch info"))
+
+	// First option is always a brand-new worktree
+	options := []string{"+ New worktree (from " + m.baseBranch + ")"}
+	for _, wt := range m.moveChangesTargets() {
+		options = append(options, wt.Branch)
+	}
+
+	for i, option := range options {
+		if i == m.moveChangesIndex {
+			b.WriteString(selectedItemStyle.Render("▶ " + option))
+		} else {
+			b.WriteString(normalItemStyle.Render("  " + option))
+		}
+		b.WriteString("\n")
+	}
 	b.WriteString("\n")
-	b.WriteString(normalItemStyle.Copy().Foreground(mutedColor).Render("  • Shift+arrow keys for window navigation"))
+
+	b.WriteString(normalItemStyle.Copy().Foreground(mutedColor).Render("Source changes are stashed only if the patch applies cleanly."))
 	b.WriteString("\n\n")
 
-	// Check if tmux config is already installed
-	hasTmuxConfig, _ := m.sessionManager.HasJeanTmuxConfig()
-	if hasTmuxConfig {
-		b.WriteString(helpStyle.Render("Note: Tmux config is already installed. You can update it or skip."))
-		b.WriteString("\n\n")
-	}
+	b.WriteString(helpStyle.Render("↑/↓ select • s staged • u untracked • enter move • esc cancel"))
 
-	// Buttons
-	installBtn := "Install Tmux Config"
-	skipBtn := "Skip for Now"
+	content := modalStyle.Width(m.width - 4).Render(b.String())
+	return lipgloss.Place(
+		m.width, m.height,
+		lipgloss.Center, lipgloss.Center,
+		content,
+	)
+}
 
-	if hasTmuxConfig {
-		installBtn = "Update Tmux Config"
+func (m Model) renderForkModal() string {
+	var b strings.Builder
+
+	b.WriteString(modalTitleStyle.Render("🍴 Fork Worktree"))
+	b.WriteString("\n\n")
+
+	b.WriteString(detailKeyStyle.Render("From: "))
+	b.WriteString(detailValueStyle.Render(m.forkSourceBranch + " (HEAD)"))
+	b.WriteString("\n\n")
+
+	b.WriteString(inputLabelStyle.Render("New branch name:"))
+	b.WriteString("\n")
+	b.WriteString(m.sessionNameInput.View())
+	b.WriteString("\n\n")
+
+	b.WriteString(inputLabelStyle.Render("Initial prompt:"))
+	b.WriteString("\n")
+	b.WriteString(m.forkPromptInput.View())
+	b.WriteString("\n\n")
+
+	checkbox := func(enabled bool, label string, focus int) string {
+		box := "[ ] "
+		if enabled {
+			box = "[x] "
+		}
+		if m.modalFocused == focus {
+			return selectedItemStyle.Render("▶ " + box + label)
+		}
+		return normalItemStyle.Render("  " + box + label)
 	}
+	b.WriteString(checkbox(m.forkCarryChanges, "Carry over uncommitted changes", 2))
+	b.WriteString("\n")
+	b.WriteString(checkbox(m.forkStartAgent, "Start a fresh agent session with this prompt", 3))
+	b.WriteString("\n\n")
 
-	if m.onboardingFocused == 0 {
-		b.WriteString(selectedButtonStyle.Render(installBtn))
+	forkBtn := "[ Fork ]"
+	cancelBtn := "[ Cancel ]"
+	if m.modalFocused == 4 {
+		forkBtn = selectedItemStyle.Render(forkBtn)
 	} else {
-		b.WriteString(buttonStyle.Render(installBtn))
+		forkBtn = normalItemStyle.Render(forkBtn)
 	}
-	b.WriteString("  ")
-
-	if m.onboardingFocused == 1 {
-		b.WriteString(selectedButtonStyle.Render(skipBtn))
+	if m.modalFocused == 5 {
+		cancelBtn = selectedItemStyle.Render(cancelBtn)
 	} else {
-		b.WriteString(buttonStyle.Render(skipBtn))
+		cancelBtn = normalItemStyle.Render(cancelBtn)
 	}
-
+	b.WriteString(lipgloss.JoinHorizontal(lipgloss.Center, forkBtn, "  ", cancelBtn))
 	b.WriteString("\n\n")
-	b.WriteString(helpStyle.Render("Tab/←→ navigate • Enter confirm • Esc skip"))
 
-	// Center the modal
-	content := modalStyle.Width(60).Render(b.String())
+	b.WriteString(helpStyle.Render("tab/↑/↓ navigate • space toggle • enter confirm • esc cancel"))
+
+	content := modalStyle.Width(m.width - 4).Render(b.String())
 	return lipgloss.Place(
 		m.width, m.height,
 		lipgloss.Center, lipgloss.Center,
@@ -2410,32 +2803,1193 @@ func (m Model) renderOnboardingModal() string {
 	)
 }
 
-func (m Model) renderGitInitModal() string {
+func (m Model) renderResetModal() string {
 	var b strings.Builder
 
-	b.WriteString(modalTitleStyle.Render("Git Repository Not Found"))
+	b.WriteString(modalTitleStyle.Render("⟲ Reset Worktree"))
 	b.WriteString("\n\n")
 
-	// Error message
-	b.WriteString(normalItemStyle.Copy().Foreground(errorColor).Render(m.gitInitError))
+	b.WriteString(detailKeyStyle.Render("Worktree: "))
+	b.WriteString(detailValueStyle.Render(m.resetBranch))
 	b.WriteString("\n\n")
 
-	// Buttons
-	yesBtn := "[Y]es - Initialize Git"
-	noBtn := "[N]o - Quit"
+	undoDesc := "No previous reset to undo"
+	if len(m.resetSafetyRefs) > 0 {
+		undoDesc = "Restore snapshot from " + git.SafetyRefTime(m.resetSafetyRefs[0]).Format("2006-01-02 15:04:05")
+	}
+	ignoredBox := "[ ]"
+	if m.resetIncludeIgnored {
+		ignoredBox = "[x]"
+	}
+	options := []struct {
+		name        string
+		description string
+	}{
+		{"Discard uncommitted changes", "Revert staged and unstaged changes to tracked files"},
+		{"Hard reset to " + m.baseBranch, "Drop all commits and changes not on the base branch"},
+		{"Clean untracked files", ignoredBox + " include ignored files (i)"},
+		{"Undo last reset", undoDesc},
+	}
 
-	b.WriteString(selectedButtonStyle.Render(yesBtn))
-	b.WriteString("\n")
-	b.WriteString(cancelButtonStyle.Render(noBtn))
+	if !m.resetConfirming {
+		for i, option := range options {
+			if i == m.resetIndex {
+				b.WriteString(selectedItemStyle.Render("▶ " + option.name))
+			} else {
+				b.WriteString(normalItemStyle.Render("  " + option.name))
+			}
+			b.WriteString("\n")
+			b.WriteString(normalItemStyle.Copy().Foreground(mutedColor).Render("  " + option.description))
+			b.WriteString("\n\n")
+		}
+		b.WriteString(helpStyle.Render("↑/↓ select • i toggle ignored • enter preview • esc cancel"))
+	} else {
+		warningStyle := normalItemStyle.Copy().Foreground(warningColor).Bold(true)
+		b.WriteString(warningStyle.Render("⚠  " + options[m.resetIndex].name))
+		b.WriteString("\n\n")
 
-	b.WriteString("\n\n")
-	b.WriteString(helpStyle.Render("Y to initialize • N/Esc to quit"))
+		if m.resetIndex == 3 {
+			b.WriteString(normalItemStyle.Render(undoDesc))
+			b.WriteString("\n")
+			b.WriteString(normalItemStyle.Copy().Foreground(mutedColor).Render("Current uncommitted changes will be replaced by the snapshot."))
+			b.WriteString("\n\n")
+		} else if m.resetPreview != nil {
+			const maxLines = 12
+			writeList := func(title string, items []string) {
+				if len(items) == 0 {
+					return
+				}
+				b.WriteString(detailKeyStyle.Render(fmt.Sprintf("%s (%d):", title, len(items))))
+				b.WriteString("\n")
+				for i, item := range items {
+					if i == maxLines {
+						b.WriteString(helpStyle.Render(fmt.Sprintf("  … and %d more", len(items)-maxLines)))
+						b.WriteString("\n")
+						break
+					}
+					b.WriteString(normalItemStyle.Render("  " + item))
+					b.WriteString("\n")
+				}
+				b.WriteString("\n")
+			}
+			writeList("Commits that will be dropped", m.resetPreview.Commits)
+			writeList("Files affected", m.resetPreview.Files)
+
+			note := "A safety snapshot is saved first - undo with Shift+R."
+			if m.resetIndex == 2 && m.resetIncludeIgnored {
+				if m.resetPreview.SnapshotIgnored {
+					note = fmt.Sprintf("A safety snapshot including the ignored files (%s) is saved first - undo with Shift+R.", session.FormatBytes(m.resetPreview.IgnoredSize))
+				} else {
+					b.WriteString(normalItemStyle.Copy().Foreground(warningColor).Render(fmt.Sprintf(
+						"⚠ The ignored files (%s) are over the %s snapshot limit: undo won't bring them back.",
+						session.FormatBytes(m.resetPreview.IgnoredSize), session.FormatBytes(git.MaxIgnoredSnapshotSize))))
+					b.WriteString("\n")
+				}
+			}
+			b.WriteString(normalItemStyle.Copy().Foreground(mutedColor).Render(note))
+			b.WriteString("\n\n")
+		}
 
-	// Center the modal
-	content := modalStyle.Width(70).Render(b.String())
+		b.WriteString(helpStyle.Render("enter/y confirm • esc/n back"))
+	}
+
+	content := modalStyle.Width(m.width - 4).Render(b.String())
 	return lipgloss.Place(
 		m.width, m.height,
 		lipgloss.Center, lipgloss.Center,
 		content,
 	)
 }
+
+func (m Model) renderExportPatchesModal() string {
+	var b strings.Builder
+
+	b.WriteString(modalTitleStyle.Render("📤 Export Commits"))
+	b.WriteString("\n\n")
+
+	b.WriteString(detailKeyStyle.Render("Commits: "))
+	b.WriteString(detailValueStyle.Render(m.baseBranch + ".." + m.patchBranch))
+	b.WriteString("\n\n")
+
+	formats := []struct {
+		name        string
+		description string
+	}{
+		{"Patch series", "One .patch file per commit (git format-patch) into a directory"},
+		{"Bundle", "Single file containing all commits (git bundle)"},
+	}
+	for i, format := range formats {
+		line := "  " + format.name
+		if i == m.exportFormatIndex {
+			line = "▶ " + format.name
+		}
+		if i == m.exportFormatIndex && m.modalFocused == 0 {
+			b.WriteString(selectedItemStyle.Render(line))
+		} else {
+			b.WriteString(normalItemStyle.Render(line))
+		}
+		b.WriteString("\n")
+		b.WriteString(normalItemStyle.Copy().Foreground(mutedColor).Render("  " + format.description))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	b.WriteString(inputLabelStyle.Render("Destination:"))
+	b.WriteString("\n")
+	b.WriteString(m.patchPathInput.View())
+	b.WriteString("\n\n")
+
+	b.WriteString(helpStyle.Render("↑/↓ format • tab edit path • enter export • esc cancel"))
+
+	content := modalStyle.Width(m.width - 4).Render(b.String())
+	return lipgloss.Place(
+		m.width, m.height,
+		lipgloss.Center, lipgloss.Center,
+		content,
+	)
+}
+
+func (m Model) renderImportPatchesModal() string {
+	var b strings.Builder
+
+	b.WriteString(modalTitleStyle.Render("📥 Import Patches"))
+	b.WriteString("\n\n")
+
+	b.WriteString(inputLabelStyle.Render("Patch file, patch directory or .bundle:"))
+	b.WriteString("\n")
+	b.WriteString(m.patchPathInput.View())
+	b.WriteString("\n\n")
+
+	b.WriteString(normalItemStyle.Render("Apply to:"))
+	b.WriteString("\n")
+
+	options := []string{"+ New worktree (from " + m.baseBranch + ")"}
+	for _, wt := range m.worktrees {
+		options = append(options, wt.Branch)
+	}
+	for i, option := range options {
+		if i == m.importTargetIndex && m.modalFocused == 1 {
+			b.WriteString(selectedItemStyle.Render("▶ " + option))
+		} else if i == m.importTargetIndex {
+			b.WriteString(normalItemStyle.Render("▶ " + option))
+		} else {
+			b.WriteString(normalItemStyle.Render("  " + option))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	b.WriteString(helpStyle.Render("tab switch field • ↑/↓ select target • enter import • esc cancel"))
+
+	content := modalStyle.Width(m.width - 4).Render(b.String())
+	return lipgloss.Place(
+		m.width, m.height,
+		lipgloss.Center, lipgloss.Center,
+		content,
+	)
+}
+
+func (m Model) renderAmConflictModal() string {
+	var b strings.Builder
+
+	b.WriteString(modalTitleStyle.Render("⚠ Patch Import Stopped"))
+	b.WriteString("\n\n")
+
+	b.WriteString(detailKeyStyle.Render("Worktree: "))
+	b.WriteString(detailValueStyle.Render(m.amBranch))
+	b.WriteString("\n")
+
+	if m.amConflict != nil {
+		b.WriteString(detailKeyStyle.Render("Patch: "))
+		b.WriteString(detailValueStyle.Render(m.amConflict.Patch))
+		b.WriteString("\n\n")
+
+		if len(m.amConflict.Conflicts) > 0 {
+			b.WriteString(normalItemStyle.Copy().Foreground(warningColor).Render("Conflicted files:"))
+			b.WriteString("\n")
+			for _, file := range m.amConflict.Conflicts {
+				b.WriteString(normalItemStyle.Render("  " + file))
+				b.WriteString("\n")
+			}
+		} else {
+			b.WriteString(normalItemStyle.Copy().Foreground(warningColor).Render("The patch could not be applied."))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	options := []struct {
+		name        string
+		description string
+	}{
+		{"Continue", "Stage resolved files and apply the remaining patches"},
+		{"Skip patch", "Drop this patch and continue with the rest"},
+		{"Abort import", "Restore the branch to its state before the import"},
+	}
+	for i, option := range options {
+		if i == m.amOptionIndex {
+			b.WriteString(selectedItemStyle.Render("▶ " + option.name))
+		} else {
+			b.WriteString(normalItemStyle.Render("  " + option.name))
+		}
+		b.WriteString("\n")
+		b.WriteString(normalItemStyle.Copy().Foreground(mutedColor).Render("  " + option.description))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	b.WriteString(helpStyle.Render("↑/↓ select • enter confirm • o open editor • esc resolve later (press i again)"))
+
+	content := modalStyle.Width(m.width - 4).Render(b.String())
+	return lipgloss.Place(
+		m.width, m.height,
+		lipgloss.Center, lipgloss.Center,
+		content,
+	)
+}
+
+func (m Model) renderConflictMatrixModal() string {
+	var lines []string
+
+	if m.conflictMatrix == nil {
+		if m.computingConflicts {
+			lines = append(lines, normalItemStyle.Render("Computing conflict predictions..."))
+		} else {
+			lines = append(lines, normalItemStyle.Render("No predictions available (requires git 2.38+)"))
+		}
+	} else {
+		matrix := m.conflictMatrix
+		conflictStyle := normalItemStyle.Copy().Foreground(errorColor)
+		overlapStyle := normalItemStyle.Copy().Foreground(warningColor)
+		mutedStyle := normalItemStyle.Copy().Foreground(mutedColor)
+		successStyle := normalItemStyle.Copy().Foreground(successColor)
+
+		lines = append(lines, detailKeyStyle.Render("Against base ("+matrix.BaseBranch+"):"))
+		baseClean := true
+		for _, wt := range m.worktrees {
+			if files := matrix.BaseConflicts[wt.Branch]; len(files) > 0 {
+				baseClean = false
+				lines = append(lines, conflictStyle.Render(fmt.Sprintf("  ⚡ %s (%d file(s))", wt.Branch, len(files))))
+				for _, f := range files {
+					lines = append(lines, mutedStyle.Render("      "+f))
+				}
+			}
+		}
+		if baseClean {
+			lines = append(lines, successStyle.Render("  ✓ All worktrees merge cleanly into "+matrix.BaseBranch))
+		}
+		lines = append(lines, "")
+
+		lines = append(lines, detailKeyStyle.Render("Between worktrees:"))
+		if len(matrix.Pairs) == 0 {
+			lines = append(lines, successStyle.Render("  ✓ No worktrees touch the same files"))
+		}
+		for _, pair := range matrix.Pairs {
+			conflicting := make(map[string]bool, len(pair.Conflicts))
+			for _, f := range pair.Conflicts {
+				conflicting[f] = true
+			}
+			header := fmt.Sprintf("  %s ↔ %s: %d overlapping", pair.A, pair.B, len(pair.Overlapping))
+			if len(pair.Conflicts) > 0 {
+				lines = append(lines, conflictStyle.Render(fmt.Sprintf("%s, %d conflicting", header, len(pair.Conflicts))))
+			} else {
+				lines = append(lines, overlapStyle.Render(header+", merges cleanly"))
+			}
+			for _, f := range pair.Overlapping {
+				if conflicting[f] {
+					lines = append(lines, conflictStyle.Render("      ⚡ "+f))
+				} else {
+					lines = append(lines, mutedStyle.Render("        "+f))
+				}
+			}
+		}
+	}
+
+	// Apply scrolling so long matrices fit on screen
+	maxLines := m.height - 12
+	if maxLines < 5 {
+		maxLines = 5
+	}
+	scroll := m.conflictMatrixScroll
+	if scroll > len(lines)-maxLines {
+		scroll = len(lines) - maxLines
+	}
+	if scroll < 0 {
+		scroll = 0
+	}
+	end := scroll + maxLines
+	if end > len(lines) {
+		end = len(lines)
+	}
+
+	var b strings.Builder
+	b.WriteString(modalTitleStyle.Render("⚡ Conflict Matrix"))
+	b.WriteString("\n\n")
+	b.WriteString(strings.Join(lines[scroll:end], "\n"))
+	b.WriteString("\n\n")
+	status := "Committed work only"
+	if m.computingConflicts && m.conflictMatrix != nil {
+		status = "Refreshing..."
+	}
+	b.WriteString(helpStyle.Render(status + " • ↑/↓ scroll • r recompute • esc close"))
+
+	content := modalStyle.Width(m.width - 4).Render(b.String())
+	return lipgloss.Place(
+		m.width, m.height,
+		lipgloss.Center, lipgloss.Center,
+		content,
+	)
+}
+
+func (m Model) renderOnboardingModal() string {
+	var b strings.Builder
+
+	b.WriteString(modalTitleStyle.Render("Welcome to jean!"))
+	b.WriteString("\n\n")
+
+	// Brief introduction
+	b.WriteString(normalItemStyle.Render("jean is a TUI for managing Git worktrees with tmux + Claude integration."))
+	b.WriteString("\n\n")
+
+	// Explain shell integration
+	b.WriteString(detailKeyStyle.Render("✓ Shell Integration"))
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("  Your shell wrapper is already configured and active!"))
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("  You can now switch worktrees seamlessly."))
+	b.WriteString("\n\n")
+
+	// Explain tmux config
+	b.WriteString(detailKeyStyle.Render("⚙  Tmux Configuration (Optional)"))
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("  We recommend installing our opinionated tmux config:"))
+	b.WriteString("\n")
+	b.WriteString(normalItemStyle.Copy().Foreground(mutedColor).Render("  • Mouse support for scrolling"))
+	b.WriteString("\n")
+	b.WriteString(normalItemStyle.Copy().Foreground(mutedColor).Render("  • Ctrl-D to detach (familiar keybinding)"))
+	b.WriteString("\n")
+	b.WriteString(normalItemStyle.Copy().Foreground(mutedColor).Render("  • 10,000 line scrollback buffer"))
+	b.WriteString("\n")
+	b.WriteString(normalItemStyle.Copy().Foreground(mutedColor).Render("  • Clean status bar with repo:branch info"))
+	b.WriteString("\n")
+	b.WriteString(normalItemStyle.Copy().Foreground(mutedColor).Render("  • Shift+arrow keys for window navigation"))
+	b.WriteString("\n\n")
+
+	// Check if tmux config is already installed
+	hasTmuxConfig, _ := m.sessionManager.HasJeanTmuxConfig()
+	if hasTmuxConfig {
+		b.WriteString(helpStyle.Render("Note: Tmux config is already installed. You can update it or skip."))
+		b.WriteString("\n\n")
+	}
+
+	// Buttons
+	installBtn := "Install Tmux Config"
+	skipBtn := "Skip for Now"
+
+	if hasTmuxConfig {
+		installBtn = "Update Tmux Config"
+	}
+
+	if m.onboardingFocused == 0 {
+		b.WriteString(selectedButtonStyle.Render(installBtn))
+	} else {
+		b.WriteString(buttonStyle.Render(installBtn))
+	}
+	b.WriteString("  ")
+
+	if m.onboardingFocused == 1 {
+		b.WriteString(selectedButtonStyle.Render(skipBtn))
+	} else {
+		b.WriteString(buttonStyle.Render(skipBtn))
+	}
+
+	b.WriteString("\n\n")
+	b.WriteString(helpStyle.Render("Tab/←→ navigate • Enter confirm • Esc skip"))
+
+	// Center the modal
+	content := modalStyle.Width(60).Render(b.String())
+	return lipgloss.Place(
+		m.width, m.height,
+		lipgloss.Center, lipgloss.Center,
+		content,
+	)
+}
+
+func (m Model) renderGitInitModal() string {
+	var b strings.Builder
+
+	b.WriteString(modalTitleStyle.Render("Git Repository Not Found"))
+	b.WriteString("\n\n")
+
+	// Error message
+	b.WriteString(normalItemStyle.Copy().Foreground(errorColor).Render(m.gitInitError))
+	b.WriteString("\n\n")
+
+	// Buttons
+	yesBtn := "[Y]es - Initialize Git"
+	noBtn := "[N]o - Quit"
+
+	b.WriteString(selectedButtonStyle.Render(yesBtn))
+	b.WriteString("\n")
+	b.WriteString(cancelButtonStyle.Render(noBtn))
+
+	b.WriteString("\n\n")
+	b.WriteString(helpStyle.Render("Y to initialize • N/Esc to quit"))
+
+	// Center the modal
+	content := modalStyle.Width(70).Render(b.String())
+	return lipgloss.Place(
+		m.width, m.height,
+		lipgloss.Center, lipgloss.Center,
+		content,
+	)
+}
+
+func (m Model) renderWindowSelectModal() string {
+	var b strings.Builder
+
+	b.WriteString(modalTitleStyle.Render("Open Window"))
+	b.WriteString("\n\n")
+
+	for i, name := range m.layoutWindows {
+		label := name
+		switch name {
+		case "terminal":
+			label = "terminal (window 1)"
+		case "claude":
+			label = fmt.Sprintf("%s (window 2)", branding.AgentWindowName)
+		}
+
+		if i == m.layoutWindowIndex {
+			b.WriteString(selectedItemStyle.Render("› " + label))
+		} else {
+			b.WriteString(normalItemStyle.Render("  " + label))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(m.layoutWindows) <= 2 {
+		b.WriteString("\n")
+		b.WriteString(helpStyle.Render("Add a \"layout\" section to jean.json for more windows"))
+	}
+
+	b.WriteString("\n\n")
+	b.WriteString(helpStyle.Render("↑↓ navigate • Enter open • Esc cancel"))
+
+	content := modalStyle.Width(m.width - 4).Render(b.String())
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, content)
+}
+
+func (m Model) renderAgentsModal() string {
+	var b strings.Builder
+
+	b.WriteString(modalTitleStyle.Render("Agents"))
+	b.WriteString("\n\n")
+	if wt := m.selectedWorktree(); wt != nil {
+		b.WriteString(helpStyle.Render("Agents running side by side in " + wt.Branch))
+		b.WriteString("\n\n")
+	}
+
+	agents := config.BuiltinAgents()
+	if m.configManager != nil {
+		agents = m.configManager.GetAgents()
+	}
+
+	for i, agent := range agents {
+		position := 0
+		for j, choice := range m.agentsModalChoices {
+			if choice == agent.Name {
+				position = j + 1
+				break
+			}
+		}
+
+		checkbox := "[ ]"
+		suffix := ""
+		if position > 0 {
+			checkbox = "[✓]"
+			if position == 1 {
+				suffix = " (main agent window)"
+			} else {
+				suffix = " (own window)"
+			}
+		}
+		if !session.IsAgentInstalled(agent) {
+			suffix += " - not installed"
+		}
+
+		line := fmt.Sprintf("%s %s%s", checkbox, agent.Name, suffix)
+		if i == m.agentsModalIndex {
+			b.WriteString(selectedItemStyle.Render("› " + line))
+		} else {
+			b.WriteString(normalItemStyle.Render("  " + line))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(m.agentsModalChoices) == 0 {
+		b.WriteString("\n")
+		b.WriteString(helpStyle.Render("Nothing selected: the repository default agent is used"))
+	}
+
+	b.WriteString("\n\n")
+	b.WriteString(helpStyle.Render("↑↓ navigate • Space toggle • Enter save • Esc cancel"))
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("Custom agents can be added under \"custom_agents\" in config.json"))
+
+	content := modalStyle.Width(m.width - 4).Render(b.String())
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, content)
+}
+
+func (m Model) renderNotificationsModal() string {
+	var b strings.Builder
+
+	b.WriteString(modalTitleStyle.Render("Notifications"))
+	b.WriteString("\n\n")
+	b.WriteString(helpStyle.Render("Alert when an agent goes from busy to waiting for input or finished."))
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render(fmt.Sprintf("Alerts come from a background '%s watch' process, so they also fire", branding.CLIName)))
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("while you are attached to another worktree's session."))
+	b.WriteString("\n\n")
+
+	cfg := config.NotificationConfig{}
+	if m.configManager != nil {
+		cfg = m.configManager.GetNotificationConfig()
+	}
+
+	options := []struct {
+		label   string
+		enabled bool
+	}{
+		{"Terminal bell", cfg.Bell},
+		{"tmux message", cfg.TmuxMessage},
+		{"Desktop notification (OSC 9/777)", cfg.Desktop},
+	}
+	for i, option := range options {
+		checkbox := "[ ]"
+		if option.enabled {
+			checkbox = "[✓]"
+		}
+		line := fmt.Sprintf("%s %s", checkbox, option.label)
+		if i == m.notificationsIndex {
+			b.WriteString(selectedItemStyle.Render("› " + line))
+		} else {
+			b.WriteString(normalItemStyle.Render("  " + line))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(detailKeyStyle.Render("Command: "))
+	if cfg.Command != "" {
+		b.WriteString(detailValueStyle.Render(cfg.Command))
+	} else {
+		b.WriteString(helpStyle.Render("none (set \"notifications.command\" in config.json)"))
+	}
+
+	b.WriteString("\n\n")
+	b.WriteString(helpStyle.Render("↑↓ navigate • Space/Enter toggle • Esc back"))
+
+	content := modalStyle.Width(m.width - 4).Render(b.String())
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, content)
+}
+
+func (m Model) renderArchiveListModal() string {
+	var b strings.Builder
+
+	b.WriteString(modalTitleStyle.Render("Session Archives"))
+	b.WriteString("\n\n")
+	b.WriteString(helpStyle.Render("Scrollback saved when sessions are killed or worktrees deleted."))
+	b.WriteString("\n\n")
+
+	b.WriteString(inputLabelStyle.Render("Search:"))
+	b.WriteString("\n")
+	b.WriteString(m.archiveSearchInput.View())
+	b.WriteString("\n\n")
+
+	// Window the list around the selection
+	maxItems := m.archiveViewHeight() - 2
+	if maxItems < 3 {
+		maxItems = 3
+	}
+	start := 0
+	if m.archiveIndex >= maxItems {
+		start = m.archiveIndex - maxItems + 1
+	}
+
+	var lines []string
+	if m.archiveSearchInput.Value() != "" && m.modalFocused == 1 {
+		for _, match := range m.archiveMatches {
+			text := match.Text
+			if len(text) > 80 {
+				text = text[:77] + "..."
+			}
+			lines = append(lines, fmt.Sprintf("%s L%d: %s", m.archiveBranch(match.Archive), match.Line+1, text))
+		}
+		if len(lines) == 0 {
+			b.WriteString(helpStyle.Render("No matches"))
+			b.WriteString("\n")
+		}
+	} else {
+		for _, archive := range m.archives {
+			line := fmt.Sprintf("%-30s %s  %6.1f KB", m.archiveBranch(archive), archive.Time.Format("2006-01-02 15:04"), float64(archive.Size)/1024)
+			if archive.Autosave {
+				line += "  [autosave]"
+			}
+			if m.isArchiveClosed(archive) {
+				line += "  [closed]"
+			}
+			lines = append(lines, line)
+		}
+		if len(lines) == 0 {
+			b.WriteString(helpStyle.Render("No archives yet"))
+			b.WriteString("\n")
+		}
+	}
+
+	for i := start; i < len(lines) && i < start+maxItems; i++ {
+		if i == m.archiveIndex && m.modalFocused == 1 {
+			b.WriteString(selectedItemStyle.Render("› " + lines[i]))
+		} else {
+			b.WriteString(normalItemStyle.Render("  " + lines[i]))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	if m.modalFocused == 0 {
+		b.WriteString(helpStyle.Render("Enter search • Esc back to list"))
+	} else {
+		b.WriteString(helpStyle.Render("↑↓ navigate • Enter view • o open in editor • / search • Esc close"))
+	}
+
+	content := modalStyle.Width(m.width - 4).Render(b.String())
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, content)
+}
+
+func (m Model) renderArchiveViewModal() string {
+	var b strings.Builder
+
+	b.WriteString(modalTitleStyle.Render(m.archiveViewTitle))
+	b.WriteString("\n\n")
+
+	query := strings.ToLower(strings.TrimSpace(m.archiveSearchInput.Value()))
+	height := m.archiveViewHeight()
+	for i := m.archiveScroll; i < len(m.archiveLines) && i < m.archiveScroll+height; i++ {
+		line := m.archiveLines[i]
+		if runes := []rune(line); m.width > 12 && len(runes) > m.width-12 {
+			line = string(runes[:m.width-12])
+		}
+		if query != "" && strings.Contains(strings.ToLower(line), query) {
+			b.WriteString(selectedItemStyle.Render(line))
+		} else {
+			b.WriteString(line)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render(fmt.Sprintf("Lines %d-%d of %d", m.archiveScroll+1, min(m.archiveScroll+height, len(m.archiveLines)), len(m.archiveLines))))
+	b.WriteString("\n")
+	help := "↑↓/PgUp/PgDn scroll • g/G top/bottom • r reload • o open in editor • Esc back"
+	if query != "" {
+		help = "n/N next/prev match • " + help
+	}
+	b.WriteString(helpStyle.Render(help))
+
+	content := modalStyle.Width(m.width - 4).Render(b.String())
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, content)
+}
+
+func (m Model) renderScriptsModal() string {
+	var b strings.Builder
+
+	branch := ""
+	if wt := m.selectedWorktree(); wt != nil {
+		branch = wt.Branch
+	}
+
+	b.WriteString(modalTitleStyle.Render("Run Script"))
+	b.WriteString("\n\n")
+	b.WriteString(helpStyle.Render(fmt.Sprintf("Scripts from jean.json run in the background in %s.", branch)))
+	b.WriteString("\n\n")
+
+	runs := make(map[string]session.ScriptRun)
+	for _, run := range m.scriptRuns[branch] {
+		runs[run.Name] = run
+	}
+
+	for i, name := range m.scriptNames {
+		line := name
+		if run, ok := runs[name]; ok {
+			_, icon := scriptStatusStyle(run.Status)
+			line = fmt.Sprintf("%s  %s %s", name, icon, formatScriptRun(run))
+		}
+		if i == m.scriptIndex {
+			b.WriteString(selectedItemStyle.Render("› " + line))
+		} else {
+			b.WriteString(normalItemStyle.Render("  " + line))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("↑↓ navigate • Enter run • o view last output • Esc cancel"))
+
+	content := modalStyle.Width(m.width - 4).Render(b.String())
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, content)
+}
+
+// renderPromptModal renders the prompt editor and library for sending prompts to agents
+func (m Model) renderPromptModal() string {
+	var b strings.Builder
+
+	b.WriteString(modalTitleStyle.Render("Send Prompt"))
+	b.WriteString("\n\n")
+	targets := strings.Join(m.promptTargets, ", ")
+	if len(m.promptTargets) > 1 {
+		targets = fmt.Sprintf("%d sessions (%s)", len(m.promptTargets), targets)
+	}
+	b.WriteString(helpStyle.Render("To the agent in " + targets))
+	b.WriteString("\n\n")
+
+	label := "Prompt:"
+	if m.modalFocused == 0 {
+		label = selectedItemStyle.Render(label)
+	} else {
+		label = inputLabelStyle.Render(label)
+	}
+	b.WriteString(label)
+	b.WriteString("\n")
+	b.WriteString(m.promptInput.View())
+	b.WriteString("\n\n")
+
+	label = "Library:"
+	if m.modalFocused == 1 {
+		label = selectedItemStyle.Render(label)
+	} else {
+		label = inputLabelStyle.Render(label)
+	}
+	b.WriteString(label)
+	b.WriteString("\n")
+	if len(m.prompts) == 0 {
+		b.WriteString(helpStyle.Render("No saved prompts. Add them under \"prompts\" in jean.json or \"agent_prompts\" in the config."))
+		b.WriteString("\n")
+	}
+	for i, prompt := range m.prompts {
+		firstLine := strings.SplitN(prompt.Text, "\n", 2)[0]
+		if len(firstLine) > 60 {
+			firstLine = firstLine[:57] + "..."
+		}
+		line := fmt.Sprintf("%s  %s", prompt.Name, normalItemStyle.Copy().Foreground(mutedColor).Render(fmt.Sprintf("%s (%s)", firstLine, prompt.Source)))
+		if m.modalFocused == 1 && i == m.promptIndex {
+			b.WriteString(selectedItemStyle.Render("› ") + line)
+		} else {
+			b.WriteString(normalItemStyle.Render("  ") + line)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	if m.modalFocused == 1 {
+		b.WriteString(helpStyle.Render("↑↓ navigate • Enter send • e edit before sending • Tab editor • Esc cancel"))
+	} else {
+		b.WriteString(helpStyle.Render("Ctrl+S send • Tab library • Esc cancel"))
+	}
+
+	content := modalStyle.Width(m.width - 4).Render(b.String())
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, content)
+}
+
+// renderReattachModal lists the worktrees an orphaned session can be handed to
+func (m Model) renderReattachModal() string {
+	var b strings.Builder
+
+	name := ""
+	if m.sessionIndex < len(m.sessions) {
+		name = m.sessions[m.sessionIndex].Name
+	}
+
+	b.WriteString(modalTitleStyle.Render("Reattach Session"))
+	b.WriteString("\n\n")
+	b.WriteString(helpStyle.Render(fmt.Sprintf("Hand %s over to a worktree without a session:", name)))
+	b.WriteString("\n\n")
+
+	for i, wt := range m.reattachChoices {
+		if i == m.reattachIndex {
+			b.WriteString(selectedItemStyle.Render("› " + wt.Branch))
+		} else {
+			b.WriteString(normalItemStyle.Render("  " + wt.Branch))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("↑↓ navigate • Enter reattach • Esc cancel"))
+
+	content := modalStyle.Width(m.width - 4).Render(b.String())
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, content)
+}
+
+// renderProcessModal lists the processes running in the selected session, heaviest first
+func (m Model) renderProcessModal() string {
+	var b strings.Builder
+
+	usage := m.selectedSessionUsage()
+	b.WriteString(modalTitleStyle.Render("Processes: " + usage.Session))
+	b.WriteString("\n\n")
+	b.WriteString(helpStyle.Render(fmt.Sprintf("CPU %.0f%% · %s in %d processes", usage.CPU, session.FormatBytes(usage.RSS), len(usage.Processes))))
+	b.WriteString("\n\n")
+
+	width := max(m.width-40, 20)
+	maxVisible := 15
+	start := max(min(m.processIndex-maxVisible/2, len(usage.Processes)-maxVisible), 0)
+	end := min(start+maxVisible, len(usage.Processes))
+	for i := start; i < end; i++ {
+		proc := usage.Processes[i]
+		command := proc.Command
+		if len(command) > width {
+			command = command[:width-3] + "..."
+		}
+		line := fmt.Sprintf("%7d %5.0f%% %8s  %s", proc.PID, proc.CPU, session.FormatBytes(proc.RSS), command)
+		if i == m.processIndex {
+			b.WriteString(selectedItemStyle.Render("› " + line))
+		} else {
+			b.WriteString(normalItemStyle.Render("  " + line))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("↑↓ navigate • d terminate process (SIGTERM) • Esc back"))
+
+	content := modalStyle.Width(m.width - 4).Render(b.String())
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, content)
+}
+
+func (m Model) renderTimeReportModal() string {
+	var b strings.Builder
+
+	b.WriteString(modalTitleStyle.Render("Time Report"))
+	b.WriteString("\n\n")
+
+	// Period tabs
+	var tabs []string
+	for i, r := range timeReportRanges {
+		if i == m.timeReportRange {
+			tabs = append(tabs, selectedItemStyle.Render(r.label))
+		} else {
+			tabs = append(tabs, normalItemStyle.Render(r.label))
+		}
+	}
+	b.WriteString(strings.Join(tabs, helpStyle.Render(" │ ")))
+	b.WriteString("\n\n")
+
+	tracking := m.configManager != nil && m.configManager.GetTimeTracking()
+	rows := m.visibleTimeReportRows()
+	if len(rows) == 0 {
+		if tracking {
+			b.WriteString(helpStyle.Render("No time tracked in this period."))
+		} else {
+			b.WriteString(helpStyle.Render("Time tracking is off. Press t to record attached and agent-busy time per worktree."))
+		}
+		b.WriteString("\n")
+	} else {
+		header := fmt.Sprintf("%-30s %9s %9s %9s", "Branch", "Active", "Attached", "Agent")
+		if m.timeReportByDay {
+			header = fmt.Sprintf("%-10s  %s", "Day", header)
+		}
+		b.WriteString(detailKeyStyle.Render(header))
+		b.WriteString("\n")
+
+		var total time.Duration
+		for _, row := range rows {
+			total += row.Active
+		}
+
+		maxVisible := max(m.height-16, 5)
+		start := min(m.timeReportScroll, max(len(rows)-maxVisible, 0))
+		end := min(start+maxVisible, len(rows))
+		for _, row := range rows[start:end] {
+			branch := row.Branch
+			if len(branch) > 30 {
+				branch = branch[:27] + "..."
+			}
+			line := fmt.Sprintf("%-30s %9s %9s %9s", branch,
+				session.FormatTrackedTime(row.Active), session.FormatTrackedTime(row.Attached), session.FormatTrackedTime(row.Agent))
+			if m.timeReportByDay {
+				line = fmt.Sprintf("%-10s  %s", row.Day, line)
+			}
+			b.WriteString(normalItemStyle.Render(line))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+		b.WriteString(detailKeyStyle.Render("Total active: "))
+		b.WriteString(detailValueStyle.Render(session.FormatTrackedTime(total)))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	status := "tracking on"
+	if !tracking {
+		status = "tracking off"
+	}
+	view := "d per day"
+	if m.timeReportByDay {
+		view = "d totals"
+	}
+	b.WriteString(helpStyle.Render(fmt.Sprintf("%s • ←/→ period • %s • c export CSV • J export JSON • t toggle tracking • Esc close", status, view)))
+
+	content := modalStyle.Width(m.width - 4).Render(b.String())
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, content)
+}
+
+func (m Model) renderConversationsModal() string {
+	var b strings.Builder
+
+	b.WriteString(modalTitleStyle.Render("Conversations: " + m.conversationBranch))
+	b.WriteString("\n\n")
+
+	current := m.resumeID(m.conversationBranch)
+	if len(m.conversations) == 0 {
+		b.WriteString(helpStyle.Render("No conversations found for this worktree's agent."))
+		b.WriteString("\n")
+	} else {
+		width := max(m.width-50, 20)
+		maxVisible := max(m.height-14, 5)
+		start := max(min(m.conversationIndex-maxVisible/2, len(m.conversations)-maxVisible), 0)
+		end := min(start+maxVisible, len(m.conversations))
+		for i := start; i < end; i++ {
+			conv := m.conversations[i]
+			marker := " "
+			if conv.ID == current {
+				marker = "●" // Resumed by the next start
+			}
+			summary := conv.Summary
+			if len([]rune(summary)) > width {
+				summary = string([]rune(summary)[:width-3]) + "..."
+			}
+			line := fmt.Sprintf("%s %s  %3d msgs  %s", marker, conv.Updated.Format("Jan 02 15:04"), conv.Messages, summary)
+			if i == m.conversationIndex {
+				b.WriteString(selectedItemStyle.Render("› " + line))
+			} else {
+				b.WriteString(normalItemStyle.Render("  " + line))
+			}
+			b.WriteString("\n")
+		}
+		if m.conversationIndex < len(m.conversations) {
+			b.WriteString("\n")
+			b.WriteString(detailKeyStyle.Render("ID: "))
+			b.WriteString(detailValueStyle.Render(m.conversations[m.conversationIndex].ID))
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("● resumed next • ↑↓ navigate • Enter resume • v view transcript • n start fresh • Esc close"))
+
+	content := modalStyle.Width(m.width - 4).Render(b.String())
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, content)
+}
+
+// transcriptResultLines caps the tool output shown per call in a transcript
+const transcriptResultLines = 6
+
+// transcriptText strips escape sequences and control characters from text read from a session file,
+// so tool output can't move the cursor or restyle the viewer. Tabs become spaces, and of a line
+// overwritten with carriage returns (e.g. a progress bar) only the last version is kept.
+func transcriptText(text string) string {
+	lines := strings.Split(ansi.Strip(strings.ReplaceAll(text, "\r\n", "\n")), "\n")
+	for i, line := range lines {
+		if strings.Contains(line, "\r") {
+			parts := strings.Split(line, "\r")
+			line = parts[len(parts)-1]
+			for j := len(parts) - 1; j >= 0 && line == ""; j-- {
+				line = parts[j]
+			}
+		}
+		lines[i] = strings.Map(func(r rune) rune {
+			if unicode.IsControl(r) {
+				return -1
+			}
+			return r
+		}, strings.ReplaceAll(line, "\t", "    "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// transcriptLines renders a conversation for the viewer: a header per turn, messages wrapped to width,
+// tool calls on one line each and the start of their output
+func transcriptLines(entries []session.TranscriptEntry, agent string, width int) []string {
+	var lines []string
+	wrap := func(indent, text string) {
+		for _, line := range strings.Split(ansi.Wrap(text, width-len([]rune(indent)), ""), "\n") {
+			lines = append(lines, indent+line)
+		}
+	}
+
+	speaker := ""
+	for _, entry := range entries {
+		entry.Text = transcriptText(entry.Text)
+		entry.Tool = transcriptText(entry.Tool)
+		who := agent
+		if entry.Kind == session.TranscriptPrompt {
+			who = "You"
+		}
+		if who != speaker {
+			if speaker != "" {
+				lines = append(lines, "")
+			}
+			lines = append(lines, fmt.Sprintf("── %s · %s ──", who, entry.Time.Local().Format("Jan 02 15:04")))
+			speaker = who
+		}
+
+		switch entry.Kind {
+		case session.TranscriptPrompt, session.TranscriptReply:
+			wrap("", entry.Text)
+		case session.TranscriptToolCall:
+			summary, _, _ := strings.Cut(entry.Text, "\n")
+			limit := width - len([]rune(entry.Tool)) - 6 // "  ▸ " and ": "
+			if runes := []rune(summary); limit > 3 && len(runes) > limit {
+				summary = string(runes[:limit-3]) + "..."
+			}
+			lines = append(lines, fmt.Sprintf("  ▸ %s: %s", entry.Tool, summary))
+		case session.TranscriptToolResult:
+			marker := "↳"
+			if entry.Error {
+				marker = "✗"
+			}
+			output := strings.Split(strings.TrimSpace(entry.Text), "\n")
+			if len(output) == 1 && output[0] == "" {
+				output[0] = "(no output)"
+			}
+			for i, line := range output {
+				if i == transcriptResultLines {
+					lines = append(lines, fmt.Sprintf("      … %d more lines", len(output)-i))
+					break
+				}
+				if runes := []rune(line); len(runes) > width-6 {
+					line = string(runes[:width-6])
+				}
+				if i == 0 {
+					lines = append(lines, "    "+marker+" "+line)
+				} else {
+					lines = append(lines, "      "+line)
+				}
+			}
+		}
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "(empty conversation)")
+	}
+	return lines
+}
+
+// formatAgentArgs describes agent launch arguments in one line
+func formatAgentArgs(args config.AgentArgs) string {
+	var parts []string
+	if args.PermissionMode != "" {
+		parts = append(parts, "mode "+args.PermissionMode)
+	}
+	if len(args.ExtraArgs) > 0 {
+		parts = append(parts, session.FormatArgs(args.ExtraArgs))
+	}
+	if len(parts) == 0 {
+		return "Agent defaults"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (m Model) renderAgentArgsModal() string {
+	var b strings.Builder
+
+	title := "Agent Arguments"
+	if m.agentArgsLaunch {
+		title = "Open Agent: " + m.agentArgsBranch
+	}
+	b.WriteString(modalTitleStyle.Render(title))
+	b.WriteString("\n\n")
+	b.WriteString(helpStyle.Render(fmt.Sprintf("Arguments %s is started with in its agent window. Other agents keep their defaults.", m.agentArgsAgent)))
+	b.WriteString("\n\n")
+
+	label := func(field int, text string) string {
+		if m.agentArgsFocus == field {
+			return selectedItemStyle.Render(text)
+		}
+		return inputLabelStyle.Render(text)
+	}
+
+	// Scope
+	scopeNames := map[int]string{
+		agentArgsRepo:     "Repository default",
+		agentArgsWorktree: "Worktree " + m.agentArgsBranch,
+		agentArgsLaunch:   "This launch only",
+	}
+	var scopes []string
+	for _, scope := range m.agentArgsScopes() {
+		if scope == m.agentArgsScope {
+			scopes = append(scopes, selectedItemStyle.Render("["+scopeNames[scope]+"]"))
+		} else {
+			scopes = append(scopes, normalItemStyle.Render(" "+scopeNames[scope]+" "))
+		}
+	}
+	b.WriteString(label(0, "Applies to:"))
+	b.WriteString("\n")
+	b.WriteString(strings.Join(scopes, " "))
+	b.WriteString("\n\n")
+
+	// Permission mode
+	b.WriteString(label(1, "Permission mode:"))
+	b.WriteString("\n")
+	if len(m.agentArgsModes) <= 1 {
+		b.WriteString(helpStyle.Render(m.agentArgsAgent + " has no permission modes to pick from"))
+	} else {
+		var modes []string
+		for i, mode := range m.agentArgsModes {
+			name := mode
+			if mode == "" {
+				name = "agent default"
+			}
+			if i == m.agentArgsModeIndex {
+				modes = append(modes, selectedItemStyle.Render("["+name+"]"))
+			} else {
+				modes = append(modes, normalItemStyle.Render(" "+name+" "))
+			}
+		}
+		b.WriteString(strings.Join(modes, " "))
+	}
+	b.WriteString("\n\n")
+
+	// Extra arguments
+	b.WriteString(label(2, "Extra arguments:"))
+	b.WriteString("\n")
+	b.WriteString(m.agentArgsExtraInput.View())
+	b.WriteString("\n")
+
+	if m.agentArgsScope == agentArgsWorktree {
+		b.WriteString(helpStyle.Render("Empty fields fall back to the repository default"))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	if m.agentArgsConfirm {
+		warning := "The agent in this worktree's session is running and will be restarted with these arguments."
+		if state := m.agentStates[m.agentArgsBranch]; state == session.AgentStateBusy || state == session.AgentStateWaiting {
+			warning = fmt.Sprintf("The agent in this worktree's session is %s and will be killed and restarted with these arguments.", state)
+		}
+		b.WriteString(normalItemStyle.Copy().Foreground(warningColor).Render("⚠ " + warning))
+		b.WriteString("\n\n")
+		b.WriteString(helpStyle.Render("Enter restart the agent and open • any other key go back • Esc cancel"))
+	} else if m.agentArgsLaunch {
+		b.WriteString(helpStyle.Render("Tab/↑↓ field • ←→ change • Enter open (asks before restarting a running agent) • Esc cancel"))
+	} else {
+		b.WriteString(helpStyle.Render("Tab/↑↓ field • ←→ change • Enter save • Esc back"))
+	}
+
+	return lipgloss.Place(
+		m.width, m.height,
+		lipgloss.Center, lipgloss.Center,
+		modalStyle.Render(b.String()),
+	)
+}
@@ -0,0 +1,51 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrew-bierman/jean-tui/session"
+)
+
+// TestTranscriptText tests that escape sequences and control characters are stripped from transcripts
+func TestTranscriptText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"plain", "hello\nworld", "hello\nworld"},
+		{"colors", "\x1b[31mFAIL\x1b[0m ok", "FAIL ok"},
+		{"cursor movement", "a\x1b[2J\x1b[Hb", "ab"},
+		{"title sequence", "\x1b]0;pwned\x07text", "text"},
+		{"windows line endings", "one\r\ntwo\r\n", "one\ntwo\n"},
+		{"progress bar", "10%\r50%\r100%", "100%"},
+		{"trailing carriage return", "done\r", "done"},
+		{"bell and backspace", "a\x07b\x08c", "abc"},
+		{"C1 control", "a\u009bb", "ab"},
+		{"tabs", "a\tb", "a    b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := transcriptText(tt.text); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestTranscriptLines_NoControlCharacters tests that rendered transcript lines contain no control characters
+func TestTranscriptLines_NoControlCharacters(t *testing.T) {
+	entries := []session.TranscriptEntry{
+		{Kind: session.TranscriptPrompt, Time: time.Now(), Text: "run \x1b[1mtests\x1b[0m"},
+		{Kind: session.TranscriptToolCall, Time: time.Now(), Tool: "Bash\x1b[2J", Text: "go test\r./..."},
+		{Kind: session.TranscriptToolResult, Time: time.Now(), Text: "\x1b[32mok\x1b[0m\tpkg\rpassed"},
+	}
+	for _, line := range transcriptLines(entries, "claude", 80) {
+		if strings.ContainsAny(line, "\x1b\r\t\x07") {
+			t.Errorf("Control characters in %q", line)
+		}
+	}
+}